@@ -0,0 +1,193 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Atomik takas (atomic swap), iki farklı zincirdeki tokenların aracısız değiş tokuşudur. Her iki taraf da
+// kendi zincirinde karşı tarafa, aynı gizli değerin (secret) hash'ine bağlı bir hash zaman kilitli sözleşme
+// (HTLC) çıktısı oluşturur. Çıktı, gizli değeri ve alıcının imzasını sunan bir girdiyle ya da sözleşmenin
+// kilit zamanından sonra göndericinin imzasıyla harcanabilir. Gizli değeri yalnızca başlatan taraf bilir;
+// karşı tarafın sözleşmesini harcarken gizli değeri zincirde açıklar ve karşı taraf bu değerle (bkz.
+// ExtractSecret) başlatan tarafın sözleşmesini harcar. Takas yarıda kalırsa iki taraf da kilit zamanından
+// sonra kendi tokenlarını geri alır; başlatan tarafın kilit zamanı karşı tarafınkinden sonra olmalıdır.
+//
+// Sözleşmeler P2SH çıktılarıdır; asıl betik AtomicSwapScript ile oluşturulur ve ScriptAddress ile adrese çevrilir.
+
+// SecretSize, atomik takasta kullanılan gizli değerin bayt uzunluğudur.
+const SecretSize = 32
+
+// SwapContract, bir atomik takas sözleşmesinin koşullarıdır.
+type SwapContract struct {
+	SecretHash    []byte // gizli değerin SHA-256 hash'i
+	RecipientHash []byte // gizli değerle harcayabilecek alıcının public key hash'i
+	RefundHash    []byte // kilit zamanından sonra geri alabilecek göndericinin public key hash'i
+	LockTime      uint32 // geri alma işleminin kazılabileceği en erken blok yüksekliği ya da zaman (bkz. locktime.go)
+}
+
+// AtomicSwapScript fonksiyonu, sözleşmenin asıl betiğini döndürür:
+//
+//	OP_IF
+//	    OP_SIZE <32> OP_EQUALVERIFY OP_SHA256 <secretHash> OP_EQUALVERIFY OP_DUP OP_HASH160 <recipientHash>
+//	OP_ELSE
+//	    <lockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP OP_DUP OP_HASH160 <refundHash>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+//
+// Alıcı <imza> <public key> <secret> <1>, gönderici ise <imza> <public key> <> (boş değer) ile harcar.
+// Hash uzunlukları yanlışsa ya da kilit zamanı 0 ise ErrBadContract'ı saran bir hata döner.
+func AtomicSwapScript(contract SwapContract) (Script, error) {
+	if len(contract.SecretHash) != sha256.Size {
+		return nil, fmt.Errorf("%w: secret hash is %d bytes", ErrBadContract, len(contract.SecretHash))
+	}
+	if len(contract.RecipientHash) != pubKeyHashLength || len(contract.RefundHash) != pubKeyHashLength {
+		return nil, fmt.Errorf("%w: public key hashes must be %d bytes", ErrBadContract, pubKeyHashLength)
+	}
+	if contract.LockTime == 0 {
+		return nil, fmt.Errorf("%w: lock time is 0", ErrBadContract) // kilitsiz sözleşme hemen geri alınabilir
+	}
+
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddOp(OpSize).AddInt64(SecretSize).AddOp(OpEqualVerify).
+		AddOp(OpSha256).AddData(contract.SecretHash).AddOp(OpEqualVerify).
+		AddOp(OpDup, OpHash160).AddData(contract.RecipientHash).
+		AddOp(OpElse).
+		AddInt64(int64(contract.LockTime)).AddOp(OpCheckLockTimeVerify, OpDrop).
+		AddOp(OpDup, OpHash160).AddData(contract.RefundHash).
+		AddOp(OpEndIf).
+		AddOp(OpEqualVerify, OpCheckSig).
+		Script(), nil
+}
+
+// AtomicSwap fonksiyonu, betik AtomicSwapScript şablonuna uyuyorsa sözleşmenin koşullarını döndürür.
+func (s Script) AtomicSwap() (SwapContract, bool) {
+	instructions, err := s.parse()
+	if err != nil || len(instructions) != 20 {
+		return SwapContract{}, false
+	}
+
+	lockTime := instructions[11]
+	var lock int64
+	if lockTime.op >= OpTrue && lockTime.op <= Op16 {
+		lock = int64(lockTime.op-OpTrue) + 1
+	} else if lock, err = scriptNum(lockTime.data, lockTimeNumLength); err != nil || lock <= 0 || lock > int64(^uint32(0)) {
+		return SwapContract{}, false
+	}
+
+	contract := SwapContract{
+		SecretHash:    instructions[5].data,
+		RecipientHash: instructions[9].data,
+		RefundHash:    instructions[16].data,
+		LockTime:      uint32(lock),
+	}
+	script, err := AtomicSwapScript(contract)
+	if err != nil || !bytes.Equal(s, script) { //yalnızca şablonun kendisi (en kısa kodlamayla) sözleşme sayılır
+		return SwapContract{}, false
+	}
+	return contract, true
+}
+
+// ContractOutput fonksiyonu, contractTx işleminin contract sözleşmesine ödeme yapan çıktısının indeksini döndürür.
+// Böyle bir çıktı yoksa ErrNoContractOutput'u saran bir hata döner.
+func ContractOutput(contract Script, contractTx *Transaction) (int, error) {
+	lockingScript := PayToScriptHashScript(contract.Hash160())
+	for i, out := range contractTx.Outputs {
+		if bytes.Equal(out.ScriptPubKey, lockingScript) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %x", ErrNoContractOutput, contractTx.ID)
+}
+
+// newContractSpend fonksiyonu, contractTx'teki sözleşme çıktısını w cüzdanına (fee düşülerek) ödeyen ve
+// pubKeyHash'i w'ninki olması gereken koşul için imzalanmış işlemi döndürür. sigData, imza ve public key'den
+// sonra kilit açma betiğine yazılan verilerdir (dalı seçen değer dahil).
+func newContractSpend(contract Script, contractTx *Transaction, w *wallet.Wallet, pubKeyHash []byte, fee int, lockTime uint32, sigData ...[]byte) (*Transaction, error) {
+	if !bytes.Equal(wallet.PublicKeyHash(w.PublicKey), pubKeyHash) {
+		return nil, fmt.Errorf("%w: wallet %s is not a party of this branch of the contract", ErrNonStandardScript, w.Address())
+	}
+
+	out, err := ContractOutput(contract, contractTx)
+	if err != nil {
+		return nil, err
+	}
+	value := contractTx.Outputs[out].Value - fee
+	if fee < 0 || value <= 0 {
+		return nil, fmt.Errorf("%w: contract holds %d, fee %d", ErrInvalidAmount, contractTx.Outputs[out].Value, fee)
+	}
+
+	input := TxInput{contractTx.ID, out, nil, SequenceFinal}
+	output := TxOutput{value, PayToPubKeyHashScript(pubKeyHash)}
+	tx := Transaction{TxVersion, nil, []TxInput{input}, []TxOutput{output}, lockTime}
+
+	signature, err := tx.signInput(w.PrivateKey, 0, contract) // P2SH girdisinin imzası asıl betiği kapsar
+	if err != nil {
+		return nil, err
+	}
+	data := append([][]byte{signature, w.PublicKey}, sigData...)
+	tx.Inputs[0].ScriptSig = ScriptHashSigScript(data, contract)
+	tx.ID = tx.Hash()
+
+	return &tx, nil
+}
+
+// NewAtomicSwapRedeem fonksiyonu, contractTx'teki sözleşme çıktısını gizli değerle alıcıya (w) ödeyen işlemi döndürür.
+// İşlem kazıldığında gizli değer zincirde açıklanmış olur. fee, sözleşmedeki tutardan düşülür.
+// Gizli değer sözleşmenin hash'ine uymuyorsa ErrSecretMismatch'i, w alıcı değilse ErrNonStandardScript'i
+// saran bir hata döner.
+func NewAtomicSwapRedeem(contract Script, contractTx *Transaction, secret []byte, w *wallet.Wallet, fee int) (*Transaction, error) {
+	terms, ok := contract.AtomicSwap()
+	if !ok {
+		return nil, fmt.Errorf("%w: script is %s", ErrBadContract, contract.Class())
+	}
+	if hash := sha256.Sum256(secret); len(secret) != SecretSize || !bytes.Equal(hash[:], terms.SecretHash) {
+		return nil, fmt.Errorf("%w: %x", ErrSecretMismatch, terms.SecretHash)
+	}
+
+	return newContractSpend(contract, contractTx, w, terms.RecipientHash, fee, 0, secret, []byte{1})
+}
+
+// NewAtomicSwapRefund fonksiyonu, contractTx'teki sözleşme çıktısını göndericiye (w) geri ödeyen işlemi döndürür.
+// İşlemin kilit zamanı sözleşmeninkidir; bu yüzden işlem sözleşmenin kilit zamanından önce kazılamaz.
+// w gönderici değilse ErrNonStandardScript'i saran bir hata döner.
+func NewAtomicSwapRefund(contract Script, contractTx *Transaction, w *wallet.Wallet, fee int) (*Transaction, error) {
+	terms, ok := contract.AtomicSwap()
+	if !ok {
+		return nil, fmt.Errorf("%w: script is %s", ErrBadContract, contract.Class())
+	}
+
+	return newContractSpend(contract, contractTx, w, terms.RefundHash, fee, terms.LockTime, []byte{}) // boş değer geri alma dalını seçer
+}
+
+// ExtractSecret fonksiyonu, bir sözleşmeyi harcayan tx işleminin kilit açma betiklerinden SHA-256 hash'i
+// secretHash olan gizli değeri çıkarır. İşlem böyle bir değer açıklamıyorsa ikinci değer false olur.
+func ExtractSecret(tx *Transaction, secretHash []byte) ([]byte, bool) {
+	for _, in := range tx.Inputs {
+		data, err := in.ScriptSig.PushedData()
+		if err != nil {
+			continue
+		}
+		for _, d := range data {
+			if hash := sha256.Sum256(d); len(d) == SecretSize && bytes.Equal(hash[:], secretHash) {
+				return d, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// FindSpendingTransaction fonksiyonu, ana zincirde ID işleminin out numaralı çıktısını harcayan işlemi
+// harcama ve işlem indekslerinden bulur. Çıktı harcanmamışsa ErrTxNotFound'u saran bir hata döner.
+func (chain *BlockChain) FindSpendingTransaction(ID []byte, out int) (Transaction, error) {
+	spender, err := chain.findSpendingTxID(ID, out)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return chain.FindTransaction(spender)
+}
@@ -0,0 +1,152 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Bir takasın iki sonu bellekteki bir zincirde uçtan uca denenir: alıcı sözleşmeyi gizli değerle harcar ve
+// başlatan taraf değeri harcayan işlemden çıkarır; tamamlanmayan ikinci sözleşme ise kilit zamanından önce
+// geri alınamaz, kilit zamanının tam yüksekliğinde geri alınır.
+func TestAtomicSwap(t *testing.T) {
+	alice, bob := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, alice)
+
+	mine := func(t *testing.T, txs ...*Transaction) *Block {
+		t.Helper()
+		height, err := chain.GetBestHeight()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb, err := CoinbaseTx(string(alice.Address()), "", chain.Engine.Reward(height+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := chain.MineBlock(append([]*Transaction{cb}, txs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return block
+	}
+
+	secret := bytes.Repeat([]byte{0x5e}, SecretSize)
+	secretHash := sha256.Sum256(secret)
+	const lockTime = 6
+	lock := func(t *testing.T, amount int) (Script, *Transaction) {
+		t.Helper()
+		contract, err := AtomicSwapScript(SwapContract{
+			SecretHash:    secretHash[:],
+			RecipientHash: wallet.PublicKeyHash(bob.PublicKey),
+			RefundHash:    wallet.PublicKeyHash(alice.PublicKey),
+			LockTime:      lockTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		contractTx, err := NewTransaction(alice, ScriptAddress(contract), amount, 0, 0, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mine(t, contractTx)
+		return contract, contractTx
+	}
+
+	// Gizli değerle harcanan sözleşme
+	contract, contractTx := lock(t, 20)
+	out, err := ContractOutput(contract, contractTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.FindSpendingTransaction(contractTx.ID, out); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindSpendingTransaction of an unspent contract = %v, want ErrTxNotFound", err)
+	}
+	if _, err := NewAtomicSwapRedeem(contract, contractTx, bytes.Repeat([]byte{0x11}, SecretSize), bob, 1); !errors.Is(err, ErrSecretMismatch) {
+		t.Fatalf("redeem with a wrong secret = %v, want ErrSecretMismatch", err)
+	}
+	if _, err := NewAtomicSwapRedeem(contract, contractTx, secret, alice, 1); !errors.Is(err, ErrNonStandardScript) {
+		t.Fatalf("redeem by the sender = %v, want ErrNonStandardScript", err)
+	}
+
+	redeem, err := NewAtomicSwapRedeem(contract, contractTx, secret, bob, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, redeem)
+
+	spending, err := chain.FindSpendingTransaction(contractTx.ID, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spending.ID, redeem.ID) {
+		t.Fatalf("FindSpendingTransaction = %x, want the redeem %x", spending.ID, redeem.ID)
+	}
+	if got, ok := ExtractSecret(&spending, secretHash[:]); !ok || !bytes.Equal(got, secret) {
+		t.Fatalf("ExtractSecret = %x, %v, want %x", got, ok, secret)
+	}
+	redeemedRefund, err := NewAtomicSwapRefund(contract, contractTx, alice, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Geri alınan sözleşme
+	contract, contractTx = lock(t, 15)
+	if _, err := NewAtomicSwapRefund(contract, contractTx, bob, 1); !errors.Is(err, ErrNonStandardScript) {
+		t.Fatalf("refund by the recipient = %v, want ErrNonStandardScript", err)
+	}
+	refund, err := NewAtomicSwapRefund(contract, contractTx, alice, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.LockTime != lockTime {
+		t.Fatalf("refund lock time %d, want %d", refund.LockTime, lockTime)
+	}
+
+	for {
+		tip, err := chain.GetBlock(chain.LastHash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tip.Height+1 == lockTime {
+			break
+		}
+		if err := chain.VerifyTransaction(refund); !errors.Is(err, ErrTxNotFinal) {
+			t.Fatalf("refund at height %d = %v, want ErrTxNotFinal", tip.Height+1, err)
+		}
+
+		// Kilit zamanından önceki bir blok da zincire eklenemez
+		cb, err := CoinbaseTx(string(alice.Address()), "", chain.Engine.Reward(tip.Height+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := CreateBlock(context.Background(), chain, chain.Engine, []*Transaction{cb, refund}, &tip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := chain.AddBlock(block); !errors.Is(err, ErrTxNotFinal) {
+			t.Fatalf("block with the refund at height %d = %v, want ErrTxNotFinal", block.Height, err)
+		}
+		mine(t)
+	}
+
+	if block := mine(t, refund); block.Height != lockTime {
+		t.Fatalf("refund mined at height %d, want %d", block.Height, lockTime)
+	}
+	spending, err = chain.FindSpendingTransaction(contractTx.ID, refund.Inputs[0].Out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spending.ID, refund.ID) {
+		t.Fatalf("FindSpendingTransaction = %x, want the refund %x", spending.ID, refund.ID)
+	}
+	if got, ok := ExtractSecret(&spending, secretHash[:]); ok {
+		t.Fatalf("refund reveals secret %x", got)
+	}
+	if err := chain.VerifyTransaction(redeemedRefund); !errors.Is(err, ErrMissingInput) {
+		t.Fatalf("refund of the redeemed contract after the lock time = %v, want ErrMissingInput", err)
+	}
+}
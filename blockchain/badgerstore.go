@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgraph-io/badger"
+)
+
+// badgerBackend, zincir verisini path dizinindeki bir Badger veritabanında tutar.
+type badgerBackend struct {
+	db *badger.DB
+}
+
+// NewBadgerStore fonksiyonu, path dizinindeki Badger veritabanını açar (yoksa oluşturur).
+// Önceki çalışmadan kalmış bir LOCK dosyası varsa silinip veritabanı yeniden açılır.
+// Blokları ham hash'leriyle saklayan eski veritabanlarının blok kayıtları bu sırada taşınır.
+func NewBadgerStore(path string) (ChainStore, error) {
+	opts := badger.DefaultOptions(path)
+	opts.Dir = path
+	opts.ValueDir = path
+	opts.Logger = nil
+
+	db, err := openDB(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	backend := badgerBackend{db}
+
+	migrated, err := migrateBlockKeys(backend)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if migrated {
+		fmt.Println("Blok kayıtları b-<hash> anahtarlarına taşındı")
+	}
+	return kvStore{backend}, nil
+}
+
+func (b badgerBackend) view(fn func(kvTxn) error) error {
+	return b.db.View(func(txn *badger.Txn) error { return fn(badgerTxn{txn}) })
+}
+
+func (b badgerBackend) update(fn func(kvTxn) error) error {
+	return b.db.Update(func(txn *badger.Txn) error { return fn(badgerTxn{txn}) })
+}
+
+// deletePrefix fonksiyonu, anahtarları bir işlemin sınırını aşmamak için parçalar halinde siler.
+func (b badgerBackend) deletePrefix(prefix []byte) error {
+	deleteKeys := func(keysForDelete [][]byte) error {
+		return b.db.Update(func(txn *badger.Txn) error {
+			for _, key := range keysForDelete {
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// Anahtarların toplanacağı koleksiyon boyutu.
+	collectSize := 100000
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		keysForDelete := make([][]byte, 0, collectSize)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			keysForDelete = append(keysForDelete, it.Item().KeyCopy(nil))
+			// Belirli bir koleksiyon boyutuna ulaşıldığında anahtarlar silinir.
+			if len(keysForDelete) == collectSize {
+				if err := deleteKeys(keysForDelete); err != nil {
+					return err
+				}
+				keysForDelete = make([][]byte, 0, collectSize)
+			}
+		}
+		if len(keysForDelete) > 0 {
+			return deleteKeys(keysForDelete)
+		}
+		return nil
+	})
+}
+
+func (b badgerBackend) close() error {
+	return b.db.Close()
+}
+
+// badgerTxn, kvTxn'i bir Badger işlemi üzerinde uygular.
+type badgerTxn struct {
+	txn *badger.Txn
+}
+
+func (t badgerTxn) get(key []byte) ([]byte, error) {
+	item, err := t.txn.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, errKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func (t badgerTxn) set(key, value []byte) error {
+	return t.txn.Set(key, value)
+}
+
+func (t badgerTxn) delete(key []byte) error {
+	return t.txn.Delete(key)
+}
+
+func (t badgerTxn) seek(prefix, start []byte, fn func(key, value []byte) (bool, error)) error {
+	it := t.txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		more, err := fn(item.KeyCopy(nil), value)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+func retry(dir string, originalOpts badger.Options) (*badger.DB, error) {
+	lockPath := filepath.Join(dir, "LOCK")
+	if err := os.Remove(lockPath); err != nil {
+		return nil, fmt.Errorf(`removing "LOCK": %s`, err)
+	}
+	retryOpts := originalOpts
+	retryOpts.Truncate = true
+	db, err := badger.Open(retryOpts)
+	return db, err
+}
+
+func openDB(dir string, opts badger.Options) (*badger.DB, error) {
+	if db, err := badger.Open(opts); err != nil {
+		if strings.Contains(err.Error(), "LOCK") {
+			if db, err := retry(dir, opts); err == nil {
+				log.Println("veritabanının kilidi açıldı, değer günlüğü kesildi")
+				return db, nil
+			}
+			log.Println("veritabanının kilidi açılamadı:", err)
+		}
+		return nil, err
+	} else {
+		return db, nil
+	}
+}
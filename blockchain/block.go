@@ -2,89 +2,163 @@ package blockchain
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
-	"log"
+	"fmt"
 	"time"
 )
 
+// Block, başlık alanlarını (bkz. BlockHeader) ve işlemleri tutar. Bloğun hash'i başlığın hash'idir;
+// işlemler hash'e MerkleRoot üzerinden girer.
 type Block struct {
+	Version      int32 //blok baslıgının surumu
 	Timestamp    int64
 	Hash         []byte
 	Transactions []*Transaction //burada data vardı sımdı datalar yerını transectıonlara aldı
 	PrevHash     []byte
 	Nonce        int
 	Height       int
+	Bits         uint32 //blogun kazılması gereken hedefin sıkıstırılmıs (compact) hali
+	MerkleRoot   []byte //islemlerin merkle kökü, blok olusturulurken bir kez hesaplanır
+
+	// Yetki kanıtı (PoA) alanları, iş kanıtında boş kalır
+	Signer    []byte //blogu imzalayan yetkilinin acık anahtarı (X||Y, 64 bayt)
+	Signature []byte //blogun hası uzerındekı imza
+	Vote      []byte //yetkili listesine eklenmesi ya da cıkarılması onerılen adresin acık anahtar hası
+	VoteAdd   bool   //oy eklemek ıcın mı (true) cıkarmak ıcın mı (false)
 }
 
-// HashTransactions fonksiyonu, bloğun islemlerini hash eder
+// transactionData fonksiyonu, merkle ağacının yapraklarını oluşturan serileştirilmiş işlemleri döndürür.
+func (b *Block) transactionData() [][]byte {
+	var txData [][]byte
+
+	for _, tx := range b.Transactions { //islemi byte dizisine dönüştürür
+		txData = append(txData, tx.Serialize()) //islemi byte dizisine dönüştürür
+	}
+	return txData
+}
+
+// HashTransactions fonksiyonu, bloğun islemlerinin merkle kökünü bloğun sürümüne göre hesaplar.
+// MerkleTreeVersion öncesindeki bir blok için eski kurulumun üretemeyeceği sayıda işlem varsa nil döner.
 func (b *Block) HashTransactions() []byte {
-	var txHashes [][]byte
+	if b.Version < MerkleTreeVersion {
+		return legacyMerkleRoot(b.transactionData())
+	}
+
+	return NewMerkleTree(b.transactionData()).RootNode.Data //merkle treein rootunun byte dizisine dönüştürülür
+}
 
-	for _, tx := range b.Transactions { //islemi byte dizisine dönüştürür
-		txHashes = append(txHashes, tx.Serialize()) //islemi byte dizisine dönüştürür
+// TransactionProof fonksiyonu, ID'si verilen işlemin bloğun merkle köküne bağlandığını gösteren kanıtı döndürür.
+// Kanıt, işlemin serileştirilmiş hali ve blok başlığındaki MerkleRoot ile VerifyMerkleProof'a verilerek doğrulanır.
+func (b *Block) TransactionProof(txID []byte) (*MerkleProof, error) {
+	if b.Version < MerkleTreeVersion {
+		return nil, fmt.Errorf("%w: merkle proofs need block version %d", ErrBadVersion, MerkleTreeVersion)
 	}
-	tree := NewMerkleTree(txHashes) //merkle tree olusturulur
 
-	return tree.RootNode.Data //merkle treein rootunun byte dizisine dönüştürülür
+	for _, tx := range b.Transactions {
+		if bytes.Equal(tx.ID, txID) {
+			return NewMerkleTree(b.transactionData()).Proof(tx.Serialize())
+		}
+	}
+
+	return nil, fmt.Errorf("transaction %x is not in block %x", txID, b.Hash)
 }
 
-// CreateBlock fonksiyonu, yeni bir bloğu olusturur
-func CreateBlock(tsx []*Transaction, prevHash []byte, height int) *Block {
-	block := &Block{time.Now().Unix(), []byte{}, tsx, prevHash, 0, height} //[]byte(data) kısmı strıng ıfadeyi byte dizisine donduruyor
+// CreateBlock fonksiyonu, yeni bir bloğu olusturur. Blogun konsensüs alanları engine tarafından
+// parent'a gore hazırlanır ve blok engine ile mühürlenir. Genesis icin parent nil'dir.
+// ctx iptal edilirse mühürleme yarıda bırakılır ve ctx.Err() döner.
+func CreateBlock(ctx context.Context, chain *BlockChain, engine Engine, tsx []*Transaction, parent *Block) (*Block, error) {
+	block := &Block{Version: BlockVersion, Timestamp: time.Now().Unix(), Hash: []byte{}, Transactions: tsx, PrevHash: []byte{}}
+	block.MerkleRoot = block.HashTransactions() //islemler bır kez hashlenır, kazım sırasında yalnızca baslık hashlenır
+	if parent != nil {
+		block.PrevHash = parent.Hash
+		block.Height = parent.Height + 1
+
+		medianTime, err := chain.CalcPastMedianTime(parent)
+		if err != nil {
+			return nil, err
+		}
+		if block.Timestamp <= medianTime { //zaman damgası onceki blokların medyanından buyuk olmalıdır
+			block.Timestamp = medianTime + 1
+		}
+	}
 
-	pow := NewProof(block)   //yeni bir iş kanıtı olusturuyoruz
-	nonce, hash := pow.Run() //bu işkanıtınını çalıştırıyoruz blogunhasını ve nance degerını eklıyoruz
-	block.Hash = hash[:]
-	block.Nonce = nonce
-	return block
+	if err := engine.Prepare(chain, block, parent); err != nil { //zorluk gibi alanlar konsensüse gore doldurulur
+		return nil, err
+	}
+	if err := engine.Seal(ctx, block); err != nil { //blok mühürlenir, hash (ve nonce) degerı eklenır
+		return nil, err
+	}
+	return block, nil
 }
 
 // Genesis fonksiyonu, ilk bloğu olusturur
-func Genesis(coinbase *Transaction) *Block {
-	return CreateBlock([]*Transaction{coinbase}, []byte{}, 0)
+func Genesis(engine Engine, coinbase *Transaction) (*Block, error) {
+	return CreateBlock(context.Background(), nil, engine, []*Transaction{coinbase}, nil)
 }
 
 //Badger DB sadece byte kabul ettıgı ıcın serılestırme ve deserilize ıslemlerı kolyalastıralım
 
-// Serialize fonksiyonu, bloğu byte dizisine dönüştürür
+// Serialize fonksiyonu, bloğu kanonik olarak byte dizisine dönüştürür (bkz. encoding.go)
 func (b *Block) Serialize() []byte {
-	var res bytes.Buffer // bir bytes.Buffer nesnesi oluşturuluyor
-
-	// encoder adında bir gob.Encoder nesnesi oluşturuluyor
-	// Bu encoder, res adındaki bytes.Buffer üzerine kodlanmış (encoded) veri yazacak
-	encoder := gob.NewEncoder(&res)
-
-	// encoder.Encode(b) çağrısı, 'b' adındaki bir veriyi (muhtemelen bir struct veya başka bir veri yapısı)
-	// encoder kullanarak kodlar (encode) ve bu işlem sırasında bir hata oluşursa 'err' değişkenine atar.
-	err := encoder.Encode(b)
-	Handle(err) // Handle fonksiyonu, oluşan hatayı işlemek için çağrılır. Hata varsa bu fonksiyon kullanıcı tarafından tanımlanmış olmalıdır.
-
-	// res.Bytes() çağrısı, bytes.Buffer nesnesi 'res' içindeki tüm verileri bir byte dilimine (slice) dönüştürür ve döndürür.
-	// Bu dönüştürülmüş byte dilimi, encoder.Encode(b) işlemi sonucunda 'res' nesnesine yazılmış kodlanmış veriyi içerir.
-	return res.Bytes()
-
+	var e encoder
+	b.encode(&e)
+	return e.bytes()
 }
 
 // Deserialize fonksiyonu, verilen byte diliminden (data) bir Block struct'ı oluşturur ve döndürür.
-func Deserialize(data []byte) *Block {
-	var block Block // Block türünde bir değişken oluşturuluyor
+// Kanonik kodlamadan önce gob ile kaydedilmiş bloklar da okunur. Çözülemeyen veri için
+// ErrMalformedEncoding'i saran bir hata döner.
+func Deserialize(data []byte) (*Block, error) {
+	if !isGob(data) {
+		return DecodeBlock(data)
+	}
+
+	// gob ile yazılmış işlemler betiklerden önceki alanları taşır, bu yüzden işlemler gobTransaction ile okunur
+	var legacy struct {
+		Version      int32
+		Timestamp    int64
+		Hash         []byte
+		Transactions []*gobTransaction
+		PrevHash     []byte
+		Nonce        int
+		Height       int
+		Bits         uint32
+		MerkleRoot   []byte
+		Signer       []byte
+		Signature    []byte
+		Vote         []byte
+		VoteAdd      bool
+	}
 
 	// bytes.NewReader(data) ile data byte dilimi üzerinde bir okuyucu (reader) oluşturuluyor
 	decoder := gob.NewDecoder(bytes.NewReader(data))
 
-	// decoder.Decode(&block) çağrısı, data üzerindeki kodlanmış veriyi Block struct'ına çözümleme (decode) işlemini yapar.
-	// Oluşabilecek hatalar err değişkenine atanır ve Handle fonksiyonu ile işlenir.
-	err := decoder.Decode(&block)
-	Handle(err) // Hata varsa bu fonksiyon kullanıcı tarafından tanımlanmış olmalıdır.
+	// decoder.Decode(&legacy) çağrısı, data üzerindeki kodlanmış veriyi çözümleme (decode) işlemini yapar.
+	if err := decoder.Decode(&legacy); err != nil {
+		return nil, fmt.Errorf("%w: block: %v", ErrMalformedEncoding, err)
+	}
+
+	block := Block{ // Block türünde bir değişken oluşturuluyor
+		Version:    legacy.Version,
+		Timestamp:  legacy.Timestamp,
+		Hash:       legacy.Hash,
+		PrevHash:   legacy.PrevHash,
+		Nonce:      legacy.Nonce,
+		Height:     legacy.Height,
+		Bits:       legacy.Bits,
+		MerkleRoot: legacy.MerkleRoot,
+		Signer:     legacy.Signer,
+		Signature:  legacy.Signature,
+		Vote:       legacy.Vote,
+		VoteAdd:    legacy.VoteAdd,
+	}
+	for _, tx := range legacy.Transactions {
+		transaction := tx.transaction()
+		block.Transactions = append(block.Transactions, &transaction)
+	}
 
 	// Çözümlenen (deserialized) Block struct'ı, bellekte oluşturulan bir yapı olduğu için &
 	// ile işaret edilerek ve fonksiyon dışına taşınabilmesi için *Block türünde döndürülür.
-	return &block
-}
-
-// Handle fonksiyonu, oluşan hata durumunda programı durdurur.
-func Handle(err error) {
-	if err != nil {
-		log.Panic(err)
-	}
+	return &block, nil
 }
@@ -2,32 +2,43 @@ package blockchain
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/dgraph-io/badger"
-	"log"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strings"
+	"sync"
 )
 
-const (
-	dbPath      = "./tmp/blocks_%s"
-	genesisData = "First Transaction from Genesis"
-)
+const dbPath = "blocks_%s" // zincirin ağın veri dizinindeki (Params.DataDir) klasörü
+
+// consensusKey, zincirin oluşturulduğu konsensüs uygulamasının adını tutar. Zincir başka bir
+// konsensüsle açılmaya çalışılırsa düğüm başlamaz.
+const consensusKey = "consensus"
+
+// networkKey, zincirin oluşturulduğu ağın adını (Params.Name) tutar. Zincir başka bir ağın
+// parametreleriyle açılmaya çalışılırsa düğüm başlamaz.
+const networkKey = "network"
 
 type BlockChain struct { //Block zıncırını tutar
-	LastHash []byte
-	Database *badger.DB
+	Store  ChainStore //bloklar, zincir ucu, indeksler ve UTXO seti
+	Engine Engine     //blokların mühürlenmesi, dogrulanması, dal secimi ve odul kuralı
+	Params Params     //zincirin acıldıgı ag parametreleri
+
+	lock     sync.Mutex   //ayni anda gelen bloklarin zincir ucunu birlikte degistirmesini engeller
+	tipLock  sync.RWMutex //lastHash'in okunmasını zincir ucunun tasınmasıyla sıralar
+	lastHash []byte       //zincir ucunun hash'i, LastHash ile okunur
 }
 
-//type BlockChainIterator struct { //Blockchain üzerinde gezmek ıcın kullanılır
-//	CurrentHash []byte
-//	Database    *badger.DB
-//}
+// LastHash fonksiyonu, zincir ucundaki bloğun hash'ini döndürür. Zincir ucu başka bir goroutine'de
+// (ağdan gelen blokla) taşınırken de güvenle çağrılabilir.
+func (chain *BlockChain) LastHash() []byte {
+	chain.tipLock.RLock()
+	defer chain.tipLock.RUnlock()
+	return chain.lastHash
+}
 
 func DBexists(path string) bool { //block zıncırın var olup olmadıgını kontrolunu yapıcak
 	if _, err := os.Stat(path + "/MANIFEST"); os.IsNotExist(err) {
@@ -38,227 +49,342 @@ func DBexists(path string) bool { //block zıncırın var olup olmadıgını kon
 
 /*
 ContinueBlockChain :
-Bu fonksiyon, mevcut bir blockchain'in varlığını kontrol eder, varsa veritabanını açar, son bloğun hash değerini alır
-ve bu bilgileri kullanarak bir BlockChain yapısı oluşturur. Daha sonra bu yapının işaretçisini döndürür. Bu işlem,
-mevcut bir blockchain'e devam etmek veya yeni işlemler eklemek için kullanılır.
+Bu fonksiyon, mevcut bir blockchain'in varlığını kontrol eder, varsa veritabanını açar ve OpenBlockChain ile
+son bloğun hash değerini alarak bir BlockChain yapısı oluşturur. Bu işlem, mevcut bir blockchain'e devam etmek
+veya yeni işlemler eklemek için kullanılır.
+Zincir yoksa ErrChainNotFound, başka bir konsensüsle oluşturulmuşsa ErrConsensusMismatch,
+başka bir ağda oluşturulmuşsa ErrNetworkMismatch döner.
 */
-func ContinueBlockChain(nodeId string) *BlockChain {
-	path := fmt.Sprintf(dbPath, nodeId)
+func ContinueBlockChain(nodeId string) (*BlockChain, error) {
+	path := filepath.Join(ActiveParams.DataDir, fmt.Sprintf(dbPath, nodeId))
 	if DBexists(path) == false { //veritabaının olup olmadıgını kontrolunu yapar
-		fmt.Println("Mevcut bir blockchain bulunamadı, bir tane oluşturun!")
-		runtime.Goexit()
+		return nil, fmt.Errorf("%w: %s", ErrChainNotFound, path)
+	}
+
+	store, err := NewBadgerStore(path)
+	if err != nil {
+		return nil, err
 	}
 
+	chain, err := OpenBlockChain(store)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return chain, nil
+}
+
+// OpenBlockChain fonksiyonu, store içindeki mevcut zinciri zincir parametrelerinde seçilen konsensüs ile açar.
+// Eski düzendeki UTXO seti ve eksik yükseklik indeksi bu sırada taşınır. Hata durumunda store kapatılmaz.
+func OpenBlockChain(store ChainStore) (*BlockChain, error) {
 	var lastHash []byte
+	consensus := ProofOfWorkName //konsensüs kaydı olmayan eskı zincirler iş kanıtı ile olusturulmustur
+	network := ActiveParams.Name //ag kaydı olmayan eskı zincirler her agla acılabılır
 
-	opts := badger.DefaultOptions(path)
-	opts.Dir = path
-	opts.ValueDir = path
-	opts.Logger = nil
+	engine, err := NewEngine(ActiveParams) //zincir parametrelerinde secilen konsensüs
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := openDB(path, opts)
-	Handle(err)
+	err = store.View(func(txn StoreTxn) error {
+		lastHash, err = txn.Tip() //son hası alıyoruz
+		if err != nil {
+			return err
+		}
 
-	err = db.Update(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte("lh")) //son hası alıyoruz
-		Handle(err)
-		lastHash, err = item.ValueCopy(nil)
+		value, err := txn.Meta(consensusKey) //zincirin olusturuldugu konsensüs okunur
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			consensus = string(value)
+		}
 
+		value, err = txn.Meta(networkKey) //zincirin olusturuldugu ag okunur
+		if value != nil {
+			network = string(value)
+		}
 		return err
 	})
-	Handle(err)
+	if err != nil {
+		return nil, err
+	}
 
-	chain := BlockChain{lastHash, db} //mevcut chaını devam etırmek ıcın BlockChaın degerlerını koruyarak eklıyoruz
+	if network != ActiveParams.Name {
+		return nil, fmt.Errorf("%w: created on %q, opened on %q", ErrNetworkMismatch, network, ActiveParams.Name)
+	}
 
-	return &chain
-}
+	if consensus != engine.Name() {
+		return nil, fmt.Errorf("%w: created with %q, opened with %q", ErrConsensusMismatch, consensus, engine.Name())
+	}
 
-// InitBlockChain BlockChainin başlatılmasını sağlar
-func InitBlockChain(address, nodeId string) *BlockChain {
-	path := fmt.Sprintf(dbPath, nodeId)
+	chain := BlockChain{lastHash: lastHash, Store: store, Engine: engine, Params: ActiveParams} //mevcut chaını devam etırmek ıcın BlockChaın degerlerını koruyarak eklıyoruz
 
-	if DBexists(path) { //verı tabanını var olup olmadıgının kontrolu
-		fmt.Printf("Blok zinciri zaten mevcut\n")
-		runtime.Goexit()
+	migrated, err := (UTXOSet{Blockchain: &chain}).MigrateLegacy() //eski duzendekı UTXO setı varsa yenı duzene tasınır
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		fmt.Println("UTXO seti yeni (txid, vout) düzenine taşındı")
 	}
-	var lastHash []byte
-	//Database baglantısı olusturulur
-	opts := badger.DefaultOptions(path)
-	opts.Dir = path
-	opts.ValueDir = path
-	opts.Logger = nil
 
-	db, err := openDB(path, opts)
-	Handle(err)
+	scripted, err := (UTXOSet{Blockchain: &chain}).MigrateScripts() //cıktıları eskı bıcımde tutan UTXO setı betıklı bıcıme tasınır
+	if err != nil {
+		return nil, err
+	}
+	if scripted {
+		fmt.Println("UTXO seti kilitleme betikli biçime taşındı")
+	}
 
-	//Databasede bir güncelleme ekleme değişiklik işlemi yapılıcaktır
-	err = db.Update(func(txn *badger.Txn) error {
-		cbtx := CoinbaseTx(address, genesisData) //CoınbaseTx yanı odulu alıcak kısıyı belırlıyoruz burada onun transectıonı olusturuldu
-		genesis := Genesis(cbtx)                 //genesis bloguna buradan gelen transectıonı verdık ve genesis blogu olusturuldu
-		fmt.Println("Genesis created")
-		err = txn.Set(genesis.Hash, genesis.Serialize()) //blogu verıtabanına kaydetik
-		Handle(err)
-		err = txn.Set([]byte("lh"), genesis.Hash) //son hash degerı guncellendi
+	indexed, err := chain.MigrateHeightIndex() //yukseklık ındeksı olmayan eskı verıtabanları ıcın ındeks olusturulur
+	if err != nil {
+		return nil, err
+	}
+	if indexed {
+		fmt.Println("Yükseklik indeksi oluşturuldu")
+	}
 
-		lastHash = genesis.Hash
+	weighed, err := chain.MigrateChainWork() //bırıkmıs ıs kaydı olmayan eskı bloklar ıcın ıs hesaplanır
+	if err != nil {
+		return nil, err
+	}
+	if weighed {
+		fmt.Println("Blokların birikmiş işi kaydedildi")
+	}
 
-		return err
-	})
+	spent, err := chain.MigrateSpendIndex() //harcama ındeksı olmayan eskı verıtabanları ıcın ındeks olusturulur
+	if err != nil {
+		return nil, err
+	}
+	if spent {
+		fmt.Println("Harcama indeksi oluşturuldu")
+	}
 
-	Handle(err)
-	blockChain := BlockChain{lastHash, db} //LastHash ve database degerlerını vererek bır BlockChaın zıncırı olusturduk
-	return &blockChain
+	return &chain, nil
 }
 
-// AddBlock  block zincirine  blok elememızı saglar
-func (chain *BlockChain) AddBlock(block *Block) {
-	err := chain.Database.Update(func(txn *badger.Txn) error {
-		if _, err := txn.Get(block.Hash); err == nil {
-			return nil
-		}
-
-		blockData := block.Serialize()
-		err := txn.Set(block.Hash, blockData)
-		Handle(err)
+// InitBlockChain BlockChainin başlatılmasını sağlar. Zincir zaten varsa ErrChainExists,
+// ödül adresi geçersizse ErrInvalidAddress döner.
+func InitBlockChain(address, nodeId string) (*BlockChain, error) {
+	path := filepath.Join(ActiveParams.DataDir, fmt.Sprintf(dbPath, nodeId))
 
-		item, err := txn.Get([]byte("lh"))
-		Handle(err)
-		lastHash, _ := item.ValueCopy(nil)
+	if DBexists(path) { //verı tabanını var olup olmadıgının kontrolu
+		return nil, fmt.Errorf("%w: %s", ErrChainExists, path)
+	}
 
-		item, err = txn.Get(lastHash)
-		Handle(err)
-		lastBlockData, _ := item.ValueCopy(nil)
+	store, err := NewBadgerStore(path) //Database baglantısı olusturulur
+	if err != nil {
+		return nil, err
+	}
 
-		lastBlock := Deserialize(lastBlockData)
+	chain, err := CreateBlockChain(store, address)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return chain, nil
+}
 
-		if block.Height > lastBlock.Height {
-			err = txn.Set([]byte("lh"), block.Hash)
-			Handle(err)
-			chain.LastHash = block.Hash
-		}
+// CreateBlockChain fonksiyonu, boş bir store içinde genesis bloğu address'e ödenen yeni bir zincir oluşturur.
+// store içinde zaten bir zincir varsa ErrChainExists döner. Hata durumunda store kapatılmaz.
+func CreateBlockChain(store ChainStore, address string) (*BlockChain, error) {
+	engine, err := NewEngine(ActiveParams) //zincir parametrelerinde secilen konsensüs
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
-	})
-	Handle(err)
-}
+	cbtx, err := CoinbaseTx(address, ActiveParams.GenesisData, engine.Reward(0)) //CoınbaseTx yanı odulu alıcak kısıyı belırlıyoruz burada onun transectıonı olusturuldu
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := Genesis(engine, cbtx) //genesis bloguna buradan gelen transectıonı verdık ve genesis blogu olusturuldu
+	if err != nil {
+		return nil, err
+	}
 
-func (chain *BlockChain) MineBlock(transactions []*Transaction) *Block {
-	var lastHash []byte
-	var lastHeight int
+	//Databasede bir güncelleme ekleme değişiklik işlemi yapılıcaktır
+	err = store.Update(func(txn StoreTxn) error {
+		if _, err := txn.Tip(); err == nil {
+			return ErrChainExists
+		} else if !errors.Is(err, ErrChainNotFound) {
+			return err
+		}
 
-	for _, tx := range transactions {
-		if chain.VerifyTransaction(tx) != true {
-			log.Panic("Invalid Transaction")
+		if err := txn.PutBlock(genesis, engine.Work(genesis)); err != nil { //blogu ve bırıktırdıgı ısı verıtabanına kaydetik
+			return err
+		}
+		if err := txn.SetTip(genesis.Hash); err != nil { //son hash degerı guncellendi
+			return err
 		}
+		if err := txn.SetMeta(consensusKey, []byte(engine.Name())); err != nil { //zincirin konsensüsü kaydedildi
+			return err
+		}
+		if err := txn.SetMeta(networkKey, []byte(ActiveParams.Name)); err != nil { //zincirin agı kaydedildi
+			return err
+		}
+		if err := txn.SetMeta(utxoFormatKey, []byte(utxoScriptFormat)); err != nil { //UTXO kayıtlarının bıcımı kaydedildi
+			return err
+		}
+		if err := txn.SetMeta(chainWorkKey, []byte{1}); err != nil { //bloklar bırıkmıs ıslerıyle saklanır
+			return err
+		}
+		if err := txn.SetMeta(spendIndexKey, []byte{1}); err != nil { //harcanan cıktılar harcama ındeksıne yazılır
+			return err
+		}
+		if err := indexTransactions(txn, genesis); err != nil { //genesis ıslemlerı ıslem ındeksıne eklendı
+			return err
+		}
+		return indexHeight(txn, genesis) //genesis yukseklık ındeksıne eklendı
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err := chain.Database.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte("lh"))
-		Handle(err)
-		lastHash, err = item.ValueCopy(nil)
-
-		item, err = txn.Get(lastHash)
-		Handle(err)
-		lastBlockData, _ := item.ValueCopy(nil)
+	blockChain := BlockChain{lastHash: genesis.Hash, Store: store, Engine: engine, Params: ActiveParams} //lastHash ve store degerlerını vererek bır BlockChaın zıncırı olusturduk
+	return &blockChain, nil
+}
 
-		lastBlock := Deserialize(lastBlockData)
+// Close fonksiyonu, zincirin store'unu kapatır.
+func (chain *BlockChain) Close() error {
+	return chain.Store.Close()
+}
 
-		lastHeight = lastBlock.Height
+// AddBlock  block zincirine  blok elememızı saglar
+// Blok kaydedilmeden önce ValidateBlock ile doğrulanır; reddedilen blokların sebebi dönen hatadan
+// errors.Is ile (ErrBadProofOfWork, ErrDoubleSpend ...) öğrenilebilir.
+// Blok, zincir ucundan daha fazla birikmiş işe sahip bir dalı tamamlıyorsa zincir bu dala geçer
+// ve UTXO seti ortak ataya kadar geri alınıp yeni dal üzerinden yeniden güncellenir.
+func (chain *BlockChain) AddBlock(block *Block) error {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+
+	if _, err := chain.GetBlock(block.Hash); err == nil { //blok zaten varsa bir sey yapmıyoruz
+		return nil
+	}
 
+	if err := chain.ValidateBlock(block); err != nil { //gecersız bloklar hıc kaydedılmez
 		return err
-	})
-	Handle(err)
-
-	newBlock := CreateBlock(transactions, lastHash, lastHeight+1)
-
-	err = chain.Database.Update(func(txn *badger.Txn) error {
-		err := txn.Set(newBlock.Hash, newBlock.Serialize())
-		Handle(err)
-		err = txn.Set([]byte("lh"), newBlock.Hash)
+	}
 
-		chain.LastHash = newBlock.Hash
+	extendsTip := bytes.Equal(block.PrevHash, chain.LastHash())
 
+	better, err := chain.storeBlock(block)
+	if err != nil {
 		return err
-	})
-	Handle(err)
+	}
+	if !better {
+		return nil
+	}
+
+	if extendsTip { //blok zincir ucunu uzatıyorsa ıslemlerı zaten dogrulandı, dogrudan baglıyoruz
+		return chain.connectBlocks([]*Block{block})
+	}
 
-	return newBlock
+	return chain.reorganize(block) //yenı blok daha agır bir dalın ucu ise zinciri o dala tasıyoruz
 }
 
-func (chain *BlockChain) GetBestHeight() int {
-	var lastBlock Block
+// MineBlock fonksiyonu, işlemleri zincir ucunun üzerine yeni bir blokta kazar ve zincire ekler.
+// İptal edilebilir kazım için MineBlockContext kullanılır.
+func (chain *BlockChain) MineBlock(transactions []*Transaction) (*Block, error) {
+	return chain.MineBlockContext(context.Background(), transactions)
+}
 
-	err := chain.Database.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte("lh"))
-		Handle(err)
-		lastHash, _ := item.ValueCopy(nil)
+// MineBlockContext fonksiyonu, işlemleri zincir ucunun üzerine yeni bir blokta kazar ve zincire ekler.
+// ctx iptal edilirse kazım durur ve ctx.Err() döner; bu durumda zincir değişmez.
+func (chain *BlockChain) MineBlockContext(ctx context.Context, transactions []*Transaction) (*Block, error) {
+	var lastBlock *Block
 
-		item, err = txn.Get(lastHash)
-		Handle(err)
-		lastBlockData, _ := item.ValueCopy(nil)
+	for _, tx := range transactions {
+		if tx.IsCoinbase() { //coinbase degeri bloktaki ucretlere baglıdır, AddBlock icinde dogrulanır
+			continue
+		}
+		if err := chain.VerifyTransaction(tx); err != nil {
+			return nil, err
+		}
+	}
 
-		lastBlock = *Deserialize(lastBlockData)
+	err := chain.Store.View(func(txn StoreTxn) error {
+		lastHash, err := txn.Tip()
+		if err != nil {
+			return err
+		}
 
-		return nil
+		lastBlock, err = txn.Block(lastHash)
+		return err
 	})
-	Handle(err)
+	if err != nil {
+		return nil, err
+	}
 
-	return lastBlock.Height
-}
+	newBlock, err := CreateBlock(ctx, chain, chain.Engine, transactions, lastBlock) //blok konsensüs kurallarına gore hazırlanıp mühürlenir
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.AddBlock(newBlock); err != nil { //blok dogrulanıp kaydedılır, zincir ucu ve UTXO seti guncellenir
+		return nil, err
+	}
 
-func (chain *BlockChain) GetBlock(blockHash []byte) (Block, error) {
-	var block Block
+	return newBlock, nil
+}
 
-	err := chain.Database.View(func(txn *badger.Txn) error {
-		if item, err := txn.Get(blockHash); err != nil {
-			return errors.New("Block is not found")
-		} else {
-			blockData, _ := item.ValueCopy(nil)
+// GetBestHeight fonksiyonu, zincir ucunun yüksekliğini döndürür.
+func (chain *BlockChain) GetBestHeight() (int, error) {
+	var tip *Block
 
-			block = *Deserialize(blockData)
+	err := chain.Store.View(func(txn StoreTxn) error {
+		lastHash, err := txn.Tip()
+		if err != nil {
+			return err
 		}
-		return nil
+
+		tip, err = txn.Block(lastHash)
+		return err
 	})
 	if err != nil {
-		return block, err
+		return 0, err
 	}
 
-	return block, nil
+	return tip.Height, nil
 }
 
-func (chain *BlockChain) GetBlockHashes() [][]byte {
-	var blocks [][]byte
-
-	iter := chain.Iterator()
-
-	for {
-		block := iter.Next()
-
-		blocks = append(blocks, block.Hash)
+// GetBlock fonksiyonu, hash'i verilen bloğu döndürür. Blok yoksa ErrBlockNotFound'u saran bir hata döner.
+func (chain *BlockChain) GetBlock(blockHash []byte) (Block, error) {
+	var block Block
 
-		if len(block.PrevHash) == 0 {
-			break
+	err := chain.Store.View(func(txn StoreTxn) error {
+		decoded, err := txn.Block(blockHash)
+		if err != nil {
+			return err
 		}
-	}
+		block = *decoded
+		return nil
+	})
 
-	return blocks
+	return block, err
 }
 
-// FindUTXO fonksiyonu, belirtilen bir adrese gönderilmiş ve henüz harcanmamış (UTXO) çıktıları bulmak için kullanılır.
-func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
-	UTXO := make(map[string]TxOutputs)
+// FindUTXO fonksiyonu, ana zincirdeki tüm harcanmamış (UTXO) çıktıları bulmak için kullanılır.
+// Sonuç işlem ID'sine, ardından çıktının işlemdeki asıl indeksine göre gruplanır.
+func (chain *BlockChain) FindUTXO() (map[string]map[int]TxOutput, error) {
+	UTXO := make(map[string]map[int]TxOutput)
 	spentTXOs := make(map[string][]int)
 
 	iter := chain.Iterator()
 
 	for {
-		block := iter.Next()
+		block, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
 
 		for _, tx := range block.Transactions {
 			txID := hex.EncodeToString(tx.ID)
 
 		Outputs:
 			for outIdx, out := range tx.Outputs {
+				if out.ScriptPubKey.IsUnspendable() {
+					continue // harcanamaz çıktılar UTXO setine girmez
+				}
 				if spentTXOs[txID] != nil {
 					for _, spentOut := range spentTXOs[txID] {
 						if spentOut == outIdx {
@@ -266,9 +392,10 @@ func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
 						}
 					}
 				}
-				outs := UTXO[txID]
-				outs.Outputs = append(outs.Outputs, out)
-				UTXO[txID] = outs
+				if UTXO[txID] == nil {
+					UTXO[txID] = make(map[int]TxOutput)
+				}
+				UTXO[txID][outIdx] = out
 			}
 			if tx.IsCoinbase() == false {
 				for _, in := range tx.Inputs {
@@ -282,88 +409,103 @@ func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
 			break
 		}
 	}
-	return UTXO
+	return UTXO, nil
 }
 
 // FindTransaction fonksiyonu, belirtilen bir işlem ID'sine sahip olan işlemi blok zincirinde bulur.
 // ID, işlemin benzersiz tanımlayıcısıdır (genellikle işlemin hash değeri olarak kullanılır).
+// İşlemin konumu işlem indeksinden okunur, zincir baştan sona taranmaz.
+// İşlem ana zincirde yoksa ErrTxNotFound'u saran bir hata döner.
 func (bc *BlockChain) FindTransaction(ID []byte) (Transaction, error) {
-	iter := bc.Iterator() // Blok zinciri üzerinde bir iterator oluşturur
-
-	for {
-		block := iter.Next() // Sonraki bloğu alır
+	loc, err := bc.findTxLocation(ID) // İşlemin hangi blokta olduğunu indeksten okur
+	if err != nil {
+		return Transaction{}, err // İşlem bulunamazsa ErrTxNotFound döner
+	}
 
-		for _, tx := range block.Transactions { // Bloğün işlemleri üzerinde döner
-			if bytes.Compare(tx.ID, ID) == 0 { // İşlem ID'si belirtilen ID'ye eşitse
-				return *tx, nil // İşlemi bulduğunda işlemi ve nil hatasını döndürür
-			}
-		}
+	block, err := bc.GetBlock(loc.BlockHash) // İşlemi içeren bloğu alır
+	if err != nil {
+		return Transaction{}, err
+	}
 
-		if len(block.PrevHash) == 0 { // Eğer bloğun önceki hash değeri yoksa (genesis blok)
-			break // Döngüyü sonlandır
-		}
+	if loc.Index >= len(block.Transactions) || !bytes.Equal(block.Transactions[loc.Index].ID, ID) {
+		return Transaction{}, fmt.Errorf("%w: %x", ErrTxIndexCorrupt, ID)
 	}
 
-	return Transaction{}, errors.New("Transaction does not exist") // İşlem bulunamazsa hata döndürür
+	return *block.Transactions[loc.Index], nil // İşlemi ve nil hatasını döndürür
 }
 
 // SignTransaction fonksiyonu, bir Transaction yapısını imzalar.
 // İmzalamak için verilen private anahtar (privKey) kullanılır ve işlemi daha önce yapılmış olan işlemlerle ilişkilendirir.
-func (bc *BlockChain) SignTransaction(tx *Transaction, privKey ecdsa.PrivateKey) {
+func (bc *BlockChain) SignTransaction(tx *Transaction, privKey ecdsa.PrivateKey) error {
+	prevTXs, err := bc.previousTransactions(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Sign(privKey, prevTXs) // Transaction yapısını imzalar
+}
+
+// previousTransactions fonksiyonu, işlemin girdilerinin harcadığı işlemleri ID'lerinin hex karşılığı ile döndürür.
+func (bc *BlockChain) previousTransactions(tx *Transaction) (map[string]Transaction, error) {
 	prevTXs := make(map[string]Transaction) // Önceki işlemlerin haritasını (map) oluşturur
 
 	// İşlemdeki her girdi için önceki işlemi bulup prevTXs haritasına ekler
 	for _, in := range tx.Inputs {
-		prevTX, err := bc.FindTransaction(in.ID)        // Girdinin referans verdiği önceki işlemi bulur
-		Handle(err)                                     // Hata durumunda işlemi ele alır
+		prevTX, err := bc.FindTransaction(in.ID) // Girdinin referans verdiği önceki işlemi bulur
+		if err != nil {
+			return nil, err
+		}
 		prevTXs[hex.EncodeToString(prevTX.ID)] = prevTX // Önceki işlemi haritaya (map) ekler (ID'si hex olarak kodlanmış olarak)
 	}
 
-	tx.Sign(privKey, prevTXs) // Transaction yapısını imzalar
+	return prevTXs, nil
 }
 
 // VerifyTransaction fonksiyonu, bir Transaction yapısının geçerliliğini doğrular.
-// Geçerlilik kontrolü için verilen önceki işlemler haritası (prevTXs) kullanılır.
-func (bc *BlockChain) VerifyTransaction(tx *Transaction) bool {
+// İmzaların yanında değerlerin geçerliliği ve girdilerin çıktıları karşılayıp karşılamadığı da kontrol edilir.
+// Girdilerin harcadığı çıktılar UTXO setinde olmalıdır; zincirde harcanmış bir çıktıyı harcayan işlem reddedilir.
+// İşlem zincir ucunun üzerine eklenecek blokta kazılabilmelidir: kilit zamanına ve girdilerin göreli kilitlerine
+// o blokta ulaşılmış olmalıdır.
+// Geçersiz işlemler için reddedilme sebebini (ErrMissingInput, ErrBadSignature, ErrTxNotFinal ...) saran bir hata döner.
+func (bc *BlockChain) VerifyTransaction(tx *Transaction) error {
+	if err := CheckTransactionSanity(tx); err != nil {
+		return err
+	}
 
-	if tx.IsCoinbase() {
-		return true
+	height, medianTime, err := bc.nextBlockLockContext()
+	if err != nil {
+		return err
+	}
+	if err := bc.CheckTransactionLocks(tx, height, medianTime, nil); err != nil {
+		return err
 	}
 
-	prevTXs := make(map[string]Transaction) // Önceki işlemlerin haritasını (map) oluşturur
+	if tx.IsCoinbase() { //tek basına bir coinbase yalnızca blok odulunu alabılır, ucretler blok dogrulamasında eklenir
+		return checkCoinbaseValue(tx, bc.Engine.Reward(height))
+	}
 
-	// İşlemdeki her girdi için önceki işlemi bulup prevTXs haritasına ekler
+	UTXOSet := UTXOSet{bc}
 	for _, in := range tx.Inputs {
-		prevTX, err := bc.FindTransaction(in.ID)        // Girdinin referans verdiği önceki işlemi bulur
-		Handle(err)                                     // Hata durumunda işlemi ele alır
-		prevTXs[hex.EncodeToString(prevTX.ID)] = prevTX // Önceki işlemi haritaya (map) ekler (ID'si hex olarak kodlanmış olarak)
+		unspent, err := UTXOSet.hasOutput(in.ID, in.Out)
+		if err != nil {
+			return err
+		}
+		if !unspent { // çıktı ya hiç yok ya da zincirdeki bir işlem tarafından harcanmış
+			return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+		}
 	}
 
-	return tx.Verify(prevTXs) // Transaction yapısının geçerliliğini doğrular
-}
-
-func retry(dir string, originalOpts badger.Options) (*badger.DB, error) {
-	lockPath := filepath.Join(dir, "LOCK")
-	if err := os.Remove(lockPath); err != nil {
-		return nil, fmt.Errorf(`removing "LOCK": %s`, err)
+	prevTXs, err := bc.previousTransactions(tx)
+	if errors.Is(err, ErrTxNotFound) {
+		return fmt.Errorf("%w: %v", ErrMissingInput, err)
+	}
+	if err != nil {
+		return err
 	}
-	retryOpts := originalOpts
-	retryOpts.Truncate = true
-	db, err := badger.Open(retryOpts)
-	return db, err
-}
 
-func openDB(dir string, opts badger.Options) (*badger.DB, error) {
-	if db, err := badger.Open(opts); err != nil {
-		if strings.Contains(err.Error(), "LOCK") {
-			if db, err := retry(dir, opts); err == nil {
-				log.Println("veritabanının kilidi açıldı, değer günlüğü kesildi")
-				return db, nil
-			}
-			log.Println("veritabanının kilidi açılamadı:", err)
-		}
-		return nil, err
-	} else {
-		return db, nil
+	if _, err := CheckTransactionInputs(tx, prevTXs); err != nil {
+		return err
 	}
+
+	return tx.VerifyInputs(prevTXs) // Girdilerin betiklerini (imzalarını) doğrular
 }
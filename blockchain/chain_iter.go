@@ -1,32 +1,64 @@
 package blockchain
 
-import "github.com/dgraph-io/badger"
+import "errors"
 
 type BlockChainIterator struct {
 	CurrentHash []byte
-	Database    *badger.DB
+	Store       ChainStore
 }
 
 func (chain *BlockChain) Iterator() *BlockChainIterator {
-	iter := &BlockChainIterator{chain.LastHash, chain.Database}
+	iter := &BlockChainIterator{chain.LastHash(), chain.Store}
 
 	return iter
 }
 
-func (iter *BlockChainIterator) Next() *Block {
+// Next fonksiyonu, sıradaki bloğu zincir ucundan genesise doğru döndürür.
+// Genesisten sonra çağrılmamalıdır; çağıran taraf PrevHash'i boş olan blokta durur.
+func (iter *BlockChainIterator) Next() (*Block, error) {
 	var block *Block
 
-	err := iter.Database.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(iter.CurrentHash)
-		Handle(err)
-		encodedBlock, err := item.ValueCopy(nil)
-		block = Deserialize(encodedBlock)
-
+	err := iter.Store.View(func(txn StoreTxn) error {
+		var err error
+		block, err = txn.Block(iter.CurrentHash)
 		return err
 	})
-	Handle(err)
+	if err != nil {
+		return nil, err
+	}
 
 	iter.CurrentHash = block.PrevHash
 
-	return block
+	return block, nil
+}
+
+// ForwardIterator, ana zincirin bloklarını yükseklik indeksi üzerinden genesisten zincir ucuna
+// doğru dolaşır.
+type ForwardIterator struct {
+	chain  *BlockChain
+	Height int // Next ile okunacak bir sonraki yükseklik
+}
+
+// ForwardIterator fonksiyonu, from yüksekliğinden başlayan bir ileri yönlü iterator oluşturur.
+func (chain *BlockChain) ForwardIterator(from int) *ForwardIterator {
+	if from < 0 {
+		from = 0
+	}
+
+	return &ForwardIterator{chain, from}
+}
+
+// Next fonksiyonu, sıradaki bloğu döndürür. Zincir ucu geçildiğinde nil, nil döner.
+func (iter *ForwardIterator) Next() (*Block, error) {
+	block, err := iter.chain.GetBlockByHeight(iter.Height)
+	if errors.Is(err, ErrBlockNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	iter.Height++
+
+	return &block, nil
 }
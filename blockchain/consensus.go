@@ -0,0 +1,52 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// Engine, zincirin konsensüs kurallarını soyutlar. Bloğun nasıl mühürleneceği (seal), mührün
+// nasıl doğrulanacağı, dallar arasında hangisinin seçileceği (fork choice) ve blok ödülü
+// bu arayüzün uygulamasına bırakılır. Kullanılacak uygulama Params.Consensus ile seçilir.
+type Engine interface {
+	// Name, konsensüs uygulamasının adını döndürür ("pow" gibi).
+	Name() string
+
+	// Prepare, henüz mühürlenmemiş bloğun konsensüse ait alanlarını (ör. Bits) ebeveynine göre
+	// doldurur. Genesis bloğu için parent nil'dir.
+	Prepare(chain *BlockChain, block, parent *Block) error
+
+	// Seal, hazırlanmış bloğu mühürler; bloğun Hash alanını (ve gerekirse Nonce gibi alanları) doldurur.
+	// ctx iptal edilirse mühürleme yarıda bırakılır ve ctx.Err() döner.
+	Seal(ctx context.Context, block *Block) error
+
+	// VerifySeal, bloğun hash'inin içeriğiyle uyuştuğunu ve mührün geçerli olduğunu kontrol eder.
+	VerifySeal(chain *BlockChain, block *Block) error
+
+	// VerifyHeader, bloğun konsensüse ait alanlarının ebeveynine göre beklenen değerler olduğunu kontrol eder.
+	VerifyHeader(chain *BlockChain, block, parent *Block) error
+
+	// Work, bloğun dal seçiminde birikmiş ağırlığa kattığı değeri döndürür.
+	// Zincir ucu her zaman en fazla ağırlık biriktirmiş dal üzerindedir.
+	Work(block *Block) *big.Int
+
+	// Reward, verilen yükseklikteki bloğun coinbase işlemiyle üretebileceği en fazla token miktarını döndürür.
+	Reward(height int) int
+}
+
+// NewEngine fonksiyonu, zincir parametrelerinde seçilen konsensüs uygulamasını oluşturur.
+func NewEngine(params Params) (Engine, error) {
+	switch params.Consensus {
+	case "", ProofOfWorkName:
+		return NewProofOfWorkEngine(params), nil
+	case ProofOfAuthorityName:
+		engine, err := NewProofOfAuthority(params)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	default:
+		return nil, fmt.Errorf("unknown consensus engine %q", params.Consensus)
+	}
+}
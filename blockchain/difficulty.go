@@ -0,0 +1,132 @@
+package blockchain
+
+import (
+	"math/big"
+)
+
+// legacyDifficulty, Bits alanı eklenmeden önceki sürümlerin bütün blokları kazırken kullandığı sabit
+// zorluktur (hedefin başındaki sıfır bit sayısı). Bu sürümlerin blokları Bits == 0 ile çözülür.
+const legacyDifficulty = 18
+
+// blockBits fonksiyonu, bloğun kazıldığı sıkıştırılmış hedefi döndürür. Bits == 0 olan bloklar Bits
+// alanından önce legacyDifficulty ile kazılmıştır; sıfır hedef hiçbir hash'in sağlayamayacağı bir hedeftir.
+func blockBits(b *Block) uint32 {
+	if b.Bits == 0 {
+		return BigToCompact(new(big.Int).Lsh(big.NewInt(1), 256-legacyDifficulty))
+	}
+	return b.Bits
+}
+
+// CompactToBig fonksiyonu, sıkıştırılmış (compact) "bits" gösterimini büyük bir sayıya çevirir.
+// İlk bayt üs (exponent), kalan 3 bayt ise mantis olarak yorumlanır: target = mantis * 256^(üs-3)
+func CompactToBig(compact uint32) *big.Int {
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+
+	return bn
+}
+
+// BigToCompact fonksiyonu, bir hedef sayıyı sıkıştırılmış "bits" gösterimine çevirir.
+func BigToCompact(n *big.Int) uint32 {
+	if n.Sign() == 0 {
+		return 0
+	}
+
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Bits()[0])
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		tn := new(big.Int).Set(n)
+		mantissa = uint32(tn.Rsh(tn, 8*(exponent-3)).Bits()[0])
+	}
+
+	// Mantisin işaret biti doluysa bir bayt kaydırılır ve üs artırılır
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// CalcWork fonksiyonu, verilen hedefteki bir bloğun temsil ettiği iş miktarını hesaplar: 2^256 / (target+1)
+func CalcWork(bits uint32) *big.Int {
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	denominator := new(big.Int).Add(target, big.NewInt(1))
+	return new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 256), denominator)
+}
+
+// CalcNextBits fonksiyonu, prev bloğunun üzerine eklenecek bloğun sahip olması gereken hedefi hesaplar.
+// Her Params.RetargetWindow blokta bir, son pencerenin gerçek süresi Params.TargetBlockInterval ile
+// karşılaştırılır ve hedef bu orana göre yeniden hesaplanır. Pencere sınırında değilsek önceki
+// bloğun hedefi aynen devam eder.
+func (chain *BlockChain) CalcNextBits(prev *Block) (uint32, error) {
+	prevBits := blockBits(prev)
+	window := chain.Params.RetargetWindow
+
+	nextHeight := prev.Height + 1
+	if window <= 1 || nextHeight%window != 0 {
+		return prevBits, nil
+	}
+
+	// Pencerenin ilk bloğuna kadar geriye doğru yürüyoruz
+	first := prev
+	for i := 0; i < window-1 && len(first.PrevHash) != 0; i++ {
+		block, err := chain.GetBlock(first.PrevHash)
+		if err != nil {
+			return 0, err
+		}
+		first = &block
+	}
+
+	expected := chain.Params.TargetBlockInterval * int64(prev.Height-first.Height)
+	if expected <= 0 {
+		return prevBits, nil
+	}
+
+	// Tek bir pencerede zorluk en fazla 4 kat değişebilir
+	actual := prev.Timestamp - first.Timestamp
+	if actual < expected/4 {
+		actual = expected / 4
+	}
+	if actual > expected*4 {
+		actual = expected * 4
+	}
+	if actual <= 0 {
+		actual = 1
+	}
+
+	newTarget := CompactToBig(prevBits)
+	newTarget.Mul(newTarget, big.NewInt(actual))
+	newTarget.Div(newTarget, big.NewInt(expected))
+
+	if limit := chain.Params.PowLimit(); newTarget.Cmp(limit) > 0 {
+		newTarget = limit
+	}
+
+	return BigToCompact(newTarget), nil
+}
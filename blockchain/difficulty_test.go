@@ -0,0 +1,176 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestCompactToBig(t *testing.T) {
+	tests := []struct {
+		compact uint32
+		target  string // onaltılık, işaretli
+	}{
+		{0x00000000, "0"},
+		{0x01003456, "0"},
+		{0x01123456, "12"},
+		{0x02123456, "1234"},
+		{0x03123456, "123456"},
+		{0x04123456, "12345600"},
+		{0x04923456, "-12345600"},
+		{0x05009234, "92340000"},
+		{0x1d00ffff, "ffff0000000000000000000000000000000000000000000000000000"},
+		{0x20010000, "100000000000000000000000000000000000000000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		want, _ := new(big.Int).SetString(test.target, 16)
+		if got := CompactToBig(test.compact); got.Cmp(want) != 0 {
+			t.Errorf("CompactToBig(%08x) = %x, want %s", test.compact, got, test.target)
+		}
+	}
+}
+
+// Normal biçimdeki her compact değer aynı değere geri sıkıştırılmalıdır. İşaret bitine taşan mantis
+// bir bayt kaydırılıp üs artırılır.
+func TestBigToCompact(t *testing.T) {
+	for _, compact := range []uint32{0x00000000, 0x01120000, 0x02123400, 0x03123456, 0x04123456, 0x04923456, 0x05009234, 0x1d00ffff, 0x1f00ffff} {
+		if got := BigToCompact(CompactToBig(compact)); got != compact {
+			t.Errorf("BigToCompact(CompactToBig(%08x)) = %08x", compact, got)
+		}
+	}
+
+	if got := BigToCompact(big.NewInt(0x80)); got != 0x02008000 {
+		t.Errorf("BigToCompact(0x80) = %08x, want 02008000", got)
+	}
+	genesis := new(big.Int).Lsh(big.NewInt(1), 256-18)
+	if got := CompactToBig(BigToCompact(genesis)); got.Cmp(genesis) != 0 {
+		t.Errorf("2^238 round trip = %x", got)
+	}
+}
+
+// newDifficultyChain fonksiyonu, zorluk hesabının yürüdüğü blokları doğrulama yapmadan bir bellek
+// deposuna yazar. timestamps[i], i yüksekliğindeki bloğun zaman damgasıdır; tüm blokların hedefi bits'tir.
+func newDifficultyChain(t *testing.T, bits uint32, timestamps []int64) (*BlockChain, *Block) {
+	t.Helper()
+
+	chain := &BlockChain{Store: NewMemoryStore(), Params: RegTestParams}
+	var prev *Block
+	for height, timestamp := range timestamps {
+		block := testBlock(fmt.Sprintf("difficulty %d", height), height)
+		block.Timestamp = timestamp
+		block.Bits = bits
+		if prev != nil {
+			block.PrevHash = prev.Hash
+		}
+		err := chain.Store.Update(func(txn StoreTxn) error {
+			return txn.PutBlock(block, big.NewInt(int64(height+1)))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev = block
+	}
+	return chain, prev
+}
+
+// windowTimestamps fonksiyonu, regtest ağında bir pencerelik (RetargetWindow blok) zaman damgalarını blok aralığı
+// interval olacak şekilde üretir.
+func windowTimestamps(interval int64) []int64 {
+	timestamps := make([]int64, RegTestParams.RetargetWindow)
+	for i := range timestamps {
+		timestamps[i] = 1700000000 + int64(i)*interval
+	}
+	return timestamps
+}
+
+// scaledBits fonksiyonu, bits hedefinin num/den katının compact gösterimini döndürür.
+func scaledBits(bits uint32, num, den int64) uint32 {
+	target := CompactToBig(bits)
+	target.Mul(target, big.NewInt(num))
+	target.Div(target, big.NewInt(den))
+	return BigToCompact(target)
+}
+
+// Pencere sınırında hedef, pencerenin gerçek süresiyle orantılı değişir; bir pencerede en fazla 4 kat
+// zorlaşır ya da kolaylaşır ve PowLimit'i aşamaz.
+func TestCalcNextBitsRetarget(t *testing.T) {
+	const bits = 0x1d00ffff
+	interval := RegTestParams.TargetBlockInterval
+	expected := interval * int64(RegTestParams.RetargetWindow-1)
+
+	tests := []struct {
+		name     string
+		bits     uint32
+		interval int64
+		want     uint32
+	}{
+		{"on time", bits, interval, bits},
+		{"twice as slow", bits, 2 * interval, scaledBits(bits, 2*expected, expected)},
+		{"clamped fast", bits, 0, scaledBits(bits, expected/4, expected)},
+		{"clamped slow", bits, 100 * interval, scaledBits(bits, 4*expected, expected)},
+		{"pow limit", RegTestParams.InitialBits(), 100 * interval, BigToCompact(RegTestParams.PowLimit())},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chain, tip := newDifficultyChain(t, test.bits, windowTimestamps(test.interval))
+			got, err := chain.CalcNextBits(tip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("CalcNextBits = %08x, want %08x", got, test.want)
+			}
+		})
+	}
+}
+
+// Bits alanından önce kazılmış bloklar (Bits == 0) eski sabit zorlukta kabul edilir; aksi halde sonraki
+// bloğun hedefi sıfır olur ve hiçbir nonce onu sağlayamaz.
+func TestCalcNextBitsLegacyBlocks(t *testing.T) {
+	legacy := BigToCompact(new(big.Int).Lsh(big.NewInt(1), 256-legacyDifficulty))
+
+	chain, tip := newDifficultyChain(t, 0, windowTimestamps(RegTestParams.TargetBlockInterval)[:3])
+	if got, err := chain.CalcNextBits(tip); err != nil || got != legacy {
+		t.Fatalf("CalcNextBits = %08x, %v, want %08x", got, err, legacy)
+	}
+
+	chain, tip = newDifficultyChain(t, 0, windowTimestamps(2*RegTestParams.TargetBlockInterval))
+	if got, err := chain.CalcNextBits(tip); err != nil || got != scaledBits(legacy, 2, 1) {
+		t.Fatalf("retarget CalcNextBits = %08x, %v, want %08x", got, err, scaledBits(legacy, 2, 1))
+	}
+
+	if work := NewProofOfWorkEngine(RegTestParams).Work(tip); work.Cmp(CalcWork(legacy)) != 0 {
+		t.Fatalf("Work = %v, want %v", work, CalcWork(legacy))
+	}
+}
+
+// Pencere ve hedeflenen blok süresi zincirin parametrelerinden okunur; kullanılamaz değerler Validate'te reddedilir.
+func TestCalcNextBitsParams(t *testing.T) {
+	const bits = 0x1d00ffff
+	timestamps := make([]int64, 4)
+	for i := range timestamps {
+		timestamps[i] = 1700000000 + int64(i)*40
+	}
+
+	chain, tip := newDifficultyChain(t, bits, timestamps)
+	chain.Params.RetargetWindow, chain.Params.TargetBlockInterval = 4, 20
+	if got, err := chain.CalcNextBits(tip); err != nil || got != scaledBits(bits, 2, 1) {
+		t.Fatalf("CalcNextBits = %08x, %v, want %08x", got, err, scaledBits(bits, 2, 1))
+	}
+
+	chain.Params.RetargetWindow = 1
+	if got, err := chain.CalcNextBits(tip); err != nil || got != bits {
+		t.Fatalf("CalcNextBits without retarget = %08x, %v, want %08x", got, err, uint32(bits))
+	}
+
+	for _, params := range []Params{
+		func(p Params) Params { p.RetargetWindow = 0; return p }(RegTestParams),
+		func(p Params) Params { p.TargetBlockInterval = 0; return p }(RegTestParams),
+	} {
+		if err := params.Validate(); err == nil {
+			t.Errorf("Validate accepted RetargetWindow %d, TargetBlockInterval %d", params.RetargetWindow, params.TargetBlockInterval)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Konsensüs açısından kritik yapıların (işlemler, girdiler, çıktılar, blok başlıkları) kanonik bayt
+// kodlaması. Kodlama Go'ya özgü değildir; docs/serialization.md içinde tanımlanmıştır ve örnek
+// (golden) değerleri oradadır. Tüm tamsayılar big-endian yazılır, değişken uzunluklu alanlar önce
+// uzunluklarını varint olarak taşır:
+//
+//	varint    : < 0xfd ise tek bayt; değilse 0xfd + uint16, 0xfe + uint32 ya da 0xff + uint64
+//	varbytes  : varint(uzunluk) || baytlar
+//
+// Eski sürümlerde bu yapılar encoding/gob ile kodlanıyordu. Bir gob akışı her zaman sıfırdan büyük
+// bir mesaj uzunluğuyla başlar; kanonik kodlamalar ise büyük-endian bir sürüm ya da değerle, yani
+// 0x00 ile başlar. Deserialize fonksiyonları eski kayıtları bu sayede ayırt eder (bkz. isGob).
+
+// ErrMalformedEncoding, kanonik kodlaması çözülemeyen veriler için döner.
+var ErrMalformedEncoding = errors.New("malformed canonical encoding")
+
+// maxVarBytes, çözümlenirken kabul edilen en uzun değişken uzunluklu alandır.
+const maxVarBytes = 1 << 24
+
+// isGob fonksiyonu, verinin kanonik değil eski gob kodlamasıyla yazılmış olup olmadığını döndürür.
+func isGob(data []byte) bool {
+	return len(data) > 0 && data[0] != 0
+}
+
+// encoder, kanonik kodlamayı bir tampona yazar.
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf.WriteByte(v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) uint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) varint(v uint64) {
+	switch {
+	case v < 0xfd:
+		e.uint8(uint8(v))
+	case v <= 0xffff:
+		e.uint8(0xfd)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(v))
+		e.buf.Write(b[:])
+	case v <= 0xffffffff:
+		e.uint8(0xfe)
+		e.uint32(uint32(v))
+	default:
+		e.uint8(0xff)
+		e.uint64(v)
+	}
+}
+
+func (e *encoder) varbytes(b []byte) {
+	e.varint(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *encoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// decoder, kanonik kodlamayı okur. İlk hatadan sonra okumalar sıfır değer döndürür ve hata err'de kalır.
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func newDecoder(data []byte) *decoder {
+	return &decoder{r: bytes.NewReader(data)}
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = fmt.Errorf("%w: %v", ErrMalformedEncoding, err)
+		return nil
+	}
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.read(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.read(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.read(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.read(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// varint fonksiyonu, bir varint okur. Değer gereğinden uzun yazılmışsa kodlama kanonik değildir ve reddedilir.
+func (d *decoder) varint() uint64 {
+	var v, min uint64
+	switch prefix := d.uint8(); prefix {
+	case 0xfd:
+		v, min = uint64(d.uint16()), 0xfd
+	case 0xfe:
+		v, min = uint64(d.uint32()), 0x10000
+	case 0xff:
+		v, min = d.uint64(), 0x100000000
+	default:
+		return uint64(prefix)
+	}
+	if d.err == nil && v < min {
+		d.err = fmt.Errorf("%w: non-minimal varint", ErrMalformedEncoding)
+		return 0
+	}
+	return v
+}
+
+// varbytes fonksiyonu, uzunluğu önünde yazılı bir bayt dizisi okur. Boş dizi için nil döner.
+func (d *decoder) varbytes() []byte {
+	n := d.varint()
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	if n > maxVarBytes || n > uint64(d.r.Len()) {
+		d.err = fmt.Errorf("%w: field length %d exceeds data", ErrMalformedEncoding, n)
+		return nil
+	}
+	return d.read(int(n))
+}
+
+// count fonksiyonu, bir liste uzunluğu okur. Her elemanın en az min bayt tuttuğu varsayılarak kalan
+// veriden uzun listeler reddedilir; böylece bozuk veri büyük bellek ayırmaya yol açmaz.
+func (d *decoder) count(min int) int {
+	n := d.varint()
+	if d.err == nil && n > uint64(d.r.Len()/min) {
+		d.err = fmt.Errorf("%w: list length %d exceeds data", ErrMalformedEncoding, n)
+		return 0
+	}
+	return int(n)
+}
+
+// finish fonksiyonu, okuma hatasını ya da verinin sonunda fazladan bayt kaldıysa hatayı döndürür.
+func (d *decoder) finish() error {
+	if d.err == nil && d.r.Len() != 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrMalformedEncoding, d.r.Len())
+	}
+	return d.err
+}
+
+// scriptTxVersion, girdi ve çıktıların betik taşıdığı ilk işlem sürümüdür. Önceki sürümlerde
+// girdiler imza ile public key'i, çıktılar public key hash'ini ayrı alanlarda taşır (bkz. tx.go).
+const scriptTxVersion = 2
+
+// lockTimeTxVersion, işlemlerin kilit zamanı ve girdilerin sıra numarası taşıdığı ilk işlem sürümüdür.
+// Önceki sürümlerdeki işlemlerin kilit zamanı 0, girdilerinin sıra numarası SequenceFinal kabul edilir.
+const lockTimeTxVersion = 3
+
+// encode fonksiyonu, girdiyi işlemin sürümüne göre kanonik olarak yazar:
+// varbytes(ID) || uint32(Out) || varbytes(ScriptSig) || uint32(Sequence); sürüm 2'de Sequence yazılmaz,
+// sürüm 1'de ScriptSig yerine varbytes(Signature) || varbytes(PubKey) yazılır.
+// Coinbase girdisinin Out değeri -1, 0xffffffff olarak yazılır.
+func (in TxInput) encode(e *encoder, version int32) {
+	e.varbytes(in.ID)
+	e.uint32(uint32(int32(in.Out)))
+	if version >= scriptTxVersion {
+		e.varbytes(in.ScriptSig)
+		if version >= lockTimeTxVersion {
+			e.uint32(in.Sequence)
+		}
+		return
+	}
+	signature, pubKey, _ := legacyInputFields(in.ScriptSig) // eski işlemlerde betik her zaman iki veri eklemesidir
+	e.varbytes(signature)
+	e.varbytes(pubKey)
+}
+
+func decodeInput(d *decoder, version int32) TxInput {
+	in := TxInput{
+		ID:       d.varbytes(),
+		Out:      int(int32(d.uint32())),
+		Sequence: SequenceFinal,
+	}
+	if version >= scriptTxVersion {
+		in.ScriptSig = d.varbytes()
+		if version >= lockTimeTxVersion {
+			in.Sequence = d.uint32()
+		}
+	} else {
+		signature := d.varbytes()
+		in.ScriptSig = legacyInputScript(signature, d.varbytes())
+	}
+	return in
+}
+
+// encode fonksiyonu, çıktıyı işlemin sürümüne göre kanonik olarak yazar:
+// int64(Value) || varbytes(ScriptPubKey); sürüm 1'de betik yerine alıcının public key hash'i yazılır.
+func (out TxOutput) encode(e *encoder, version int32) {
+	e.uint64(uint64(int64(out.Value)))
+	if version >= scriptTxVersion {
+		e.varbytes(out.ScriptPubKey)
+		return
+	}
+	hash, ok := legacyOutputHash(out.ScriptPubKey)
+	if !ok {
+		hash = out.ScriptPubKey // eski sürümde yazılamayan çıktılar CheckTransactionSanity tarafından reddedilir
+	}
+	e.varbytes(hash)
+}
+
+func decodeOutput(d *decoder, version int32) TxOutput {
+	out := TxOutput{Value: int(int64(d.uint64()))}
+	if version >= scriptTxVersion {
+		out.ScriptPubKey = d.varbytes()
+	} else {
+		out.ScriptPubKey = PayToPubKeyHashScript(d.varbytes())
+	}
+	return out
+}
+
+// encode fonksiyonu, işlemi kanonik olarak yazar:
+// int32(Version) || varint(girdi sayısı) || girdiler || varint(çıktı sayısı) || çıktılar || uint32(LockTime).
+// LockTime sürüm 3'ten önceki işlemlerde yazılmaz.
+// ID kodlamaya girmez; işlemin ID'si bu kodlamanın SHA-256 hash'idir.
+func (tx *Transaction) encode(e *encoder) {
+	e.uint32(uint32(tx.Version))
+	e.varint(uint64(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		in.encode(e, tx.Version)
+	}
+	e.varint(uint64(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		out.encode(e, tx.Version)
+	}
+	if tx.Version >= lockTimeTxVersion {
+		e.uint32(tx.LockTime)
+	}
+}
+
+func decodeTransaction(d *decoder) Transaction {
+	tx := Transaction{Version: int32(d.uint32())}
+	if d.err == nil && tx.Version < 1 {
+		d.err = fmt.Errorf("%w: transaction version %d", ErrMalformedEncoding, tx.Version)
+		return tx
+	}
+
+	minInput := 4 + 1 + 1 // ID, Out ve ScriptSig uzunluğu
+	if tx.Version < scriptTxVersion {
+		minInput++ // Signature ve PubKey uzunlukları
+	}
+	if tx.Version >= lockTimeTxVersion {
+		minInput += 4 // Sequence
+	}
+	for i, n := 0, d.count(minInput); i < n && d.err == nil; i++ {
+		tx.Inputs = append(tx.Inputs, decodeInput(d, tx.Version))
+	}
+	for i, n := 0, d.count(8+1); i < n && d.err == nil; i++ {
+		tx.Outputs = append(tx.Outputs, decodeOutput(d, tx.Version))
+	}
+	if tx.Version >= lockTimeTxVersion {
+		tx.LockTime = d.uint32()
+	}
+
+	return tx
+}
+
+// DecodeTransaction fonksiyonu, kanonik olarak kodlanmış bir işlemi çözer ve ID'sini hesaplar.
+func DecodeTransaction(data []byte) (Transaction, error) {
+	d := newDecoder(data)
+	tx := decodeTransaction(d)
+	if err := d.finish(); err != nil {
+		return Transaction{}, err
+	}
+	tx.ID = tx.Hash()
+	return tx, nil
+}
+
+// DeserializeBlockHeader fonksiyonu, BlockHeaderSize uzunluğundaki başlık kodlamasını çözer.
+func DeserializeBlockHeader(data []byte) (BlockHeader, error) {
+	var header BlockHeader
+	if len(data) != BlockHeaderSize {
+		return header, fmt.Errorf("%w: header is %d bytes, want %d", ErrMalformedEncoding, len(data), BlockHeaderSize)
+	}
+
+	header.Version = int32(binary.BigEndian.Uint32(data[headerVersionOffset:]))
+	copy(header.PrevHash[:], data[headerPrevHashOffset:])
+	copy(header.MerkleRoot[:], data[headerMerkleRootOffset:])
+	header.Timestamp = int64(binary.BigEndian.Uint64(data[headerTimestampOffset:]))
+	header.Bits = binary.BigEndian.Uint32(data[headerBitsOffset:])
+	header.Nonce = binary.BigEndian.Uint64(data[headerNonceOffset:])
+
+	return header, nil
+}
+
+// encode fonksiyonu, bloğu kanonik olarak yazar:
+// başlık (88 bayt) || uint64(Height) || varbytes(Hash) || varbytes(Signer) || varbytes(Signature) ||
+// varbytes(Vote) || uint8(VoteAdd) || varint(işlem sayısı) || her işlem için varbytes(işlem kodlaması).
+// Hash ayrıca saklanır çünkü yetki kanıtında bloğun hash'i başlığın hash'i değildir.
+func (b *Block) encode(e *encoder) {
+	e.buf.Write(b.Header().Serialize())
+	e.uint64(uint64(b.Height))
+	e.varbytes(b.Hash)
+	e.varbytes(b.Signer)
+	e.varbytes(b.Signature)
+	e.varbytes(b.Vote)
+	if b.VoteAdd {
+		e.uint8(1)
+	} else {
+		e.uint8(0)
+	}
+	e.varint(uint64(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		e.varbytes(tx.Serialize())
+	}
+}
+
+// DecodeBlock fonksiyonu, kanonik olarak kodlanmış bir bloğu çözer.
+func DecodeBlock(data []byte) (*Block, error) {
+	d := newDecoder(data)
+
+	header, err := DeserializeBlockHeader(d.read(BlockHeaderSize))
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block := &Block{
+		Version:    header.Version,
+		Timestamp:  header.Timestamp,
+		PrevHash:   append([]byte{}, header.PrevHash[:]...),
+		MerkleRoot: append([]byte{}, header.MerkleRoot[:]...),
+		Bits:       header.Bits,
+		Nonce:      int(header.Nonce),
+	}
+	block.Height = int(d.uint64())
+	if block.Height == 0 && header.PrevHash == [32]byte{} {
+		block.PrevHash = []byte{} // genesis bloğunun önceki hash'i yoktur
+	}
+	block.Hash = d.varbytes()
+	block.Signer = d.varbytes()
+	block.Signature = d.varbytes()
+	block.Vote = d.varbytes()
+	switch d.uint8() {
+	case 0:
+	case 1:
+		block.VoteAdd = true
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: bad vote flag", ErrMalformedEncoding)
+		}
+	}
+
+	for i, n := 0, d.count(1); i < n && d.err == nil; i++ {
+		txData := d.varbytes()
+		if d.err != nil {
+			break
+		}
+		tx, err := DeserializeTransaction(txData)
+		if err != nil {
+			return nil, err
+		}
+		block.Transactions = append(block.Transactions, &tx)
+	}
+
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// docs/serialization.md içindeki altın vektörler. Kodlamalar çözülmeli, bayt bayt aynı yeniden kodlanmalı
+// ve belgelenen ID'lere hash'lenmelidir; buradaki her değişiklik bir konsensüs değişikliğidir.
+var txVectors = []struct {
+	name     string
+	encoding string
+	txid     string
+}{
+	{
+		"v3 coinbase",
+		"000000030100ffffffff080767656e65736973ffffffff0100000000000000141976a914444444444444444444444444444444444444444488ac00000000",
+		"8f43ad1550dad05b51639603c4374d2eac6993ab5972865828117d2917b5f390",
+	},
+	{
+		"v3 spend",
+		"0000000301201111111111111111111111111111111111111111111111111111111111111111000000010602aabb02ccdd000000030200000000000000051976a914555555555555555555555555555555555555555588ac000000000000012c1976a914666666666666666666666666666666666666666688ac00000078",
+		"bb4805cbd38c8e95c3c0a064c2cf43e5d90ff858c8e88e74823b7375adafee5d",
+	},
+	{
+		"v2 coinbase",
+		"000000020100ffffffff080767656e657369730100000000000000141976a914444444444444444444444444444444444444444488ac",
+		"9a62fd0a5bb328191935530f1136b3a6424ed9870de16f4c1489813d48f58cfa",
+	},
+	{
+		"v2 spend",
+		"0000000201201111111111111111111111111111111111111111111111111111111111111111000000010602aabb02ccdd0200000000000000051976a914555555555555555555555555555555555555555588ac000000000000012c1976a914666666666666666666666666666666666666666688ac",
+		"45e0335a08a70d58202742ecd54fcc5313fe1cf7d009bde75285b1729f4362f2",
+	},
+	{
+		"v1 coinbase",
+		"000000010100ffffffff000767656e65736973010000000000000014144444444444444444444444444444444444444444",
+		"eff234dfcc0c5a69afd28cbfe81ecb35d0c21f4f859a00c30cfa42a0a7bb87bf",
+	},
+	{
+		"v1 spend",
+		"00000001012011111111111111111111111111111111111111111111111111111111111111110000000102aabb02ccdd020000000000000005145555555555555555555555555555555555555555000000000000012c146666666666666666666666666666666666666666",
+		"7fe3df9aceb4c4fb56167d6a0a217e55d9bc2a6a56299bc9ef6ceab26877003f",
+	},
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTransactionGoldenVectors(t *testing.T) {
+	for _, v := range txVectors {
+		t.Run(v.name, func(t *testing.T) {
+			data := mustHex(t, v.encoding)
+			tx, err := DeserializeTransaction(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got := tx.Serialize(); !bytes.Equal(got, data) {
+				t.Fatalf("re-encoding differs:\n got %x\nwant %x", got, data)
+			}
+			if got := hex.EncodeToString(tx.ID); got != v.txid {
+				t.Fatalf("decoded ID %s, want %s", got, v.txid)
+			}
+			if got := hex.EncodeToString(tx.Hash()); got != v.txid {
+				t.Fatalf("Hash %s, want %s", got, v.txid)
+			}
+		})
+	}
+}
+
+// Sürüm 3 harcaması belgelenen kilitleri taşır; sürüm 1 ve 2 harcamaları aynı girdi ve çıktılara
+// çözülür (sürüm 1 çıktıları P2PKH betiğine çevrilir).
+func TestTransactionVectorFields(t *testing.T) {
+	decode := func(i int) Transaction {
+		tx, err := DeserializeTransaction(mustHex(t, txVectors[i].encoding))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	v3, v2, v1 := decode(1), decode(3), decode(5)
+	if v3.Inputs[0].Sequence != 3 || v3.LockTime != 120 {
+		t.Fatalf("v3 sequence %d, lock time %d", v3.Inputs[0].Sequence, v3.LockTime)
+	}
+	if v2.Inputs[0].Sequence != SequenceFinal || v2.LockTime != 0 {
+		t.Fatalf("v2 sequence %#x, lock time %d", v2.Inputs[0].Sequence, v2.LockTime)
+	}
+	if !reflect.DeepEqual(v1.Inputs, v2.Inputs) || !reflect.DeepEqual(v1.Outputs, v2.Outputs) {
+		t.Fatalf("v1 and v2 spends differ:\n%v\n%v", v1, v2)
+	}
+	if !reflect.DeepEqual(v3.Outputs, v2.Outputs) {
+		t.Fatalf("v3 and v2 outputs differ")
+	}
+}
+
+func TestOutputGoldenVector(t *testing.T) {
+	data := mustHex(t, "00000000000000140776a902010288ac")
+	out, err := DeserializeOutput(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Value != 20 || !bytes.Equal(out.ScriptPubKey, PayToPubKeyHashScript([]byte{0x01, 0x02})) {
+		t.Fatalf("decoded %d %x", out.Value, out.ScriptPubKey)
+	}
+	if got := out.Serialize(); !bytes.Equal(got, data) {
+		t.Fatalf("re-encoding differs:\n got %x\nwant %x", got, data)
+	}
+}
+
+func TestHeaderGoldenVector(t *testing.T) {
+	data := mustHex(t, "00000002ab00000000000000000000000000000000000000000000000000000000000000eff234dfcc0c5a69afd28cbfe81ecb35d0c21f4f859a00c30cfa42a0a7bb87bf000000006553f1001f00ffff000000000000002a")
+	header, err := DeserializeBlockHeader(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if header.Version != 2 || header.PrevHash[0] != 0xab || header.Timestamp != 1700000000 || header.Bits != 0x1f00ffff || header.Nonce != 42 {
+		t.Fatalf("decoded %+v", header)
+	}
+	if got := hex.EncodeToString(header.MerkleRoot[:]); got != txVectors[4].txid {
+		t.Fatalf("merkle root %s", got)
+	}
+	if got := header.Serialize(); !bytes.Equal(got, data) {
+		t.Fatalf("re-encoding differs:\n got %x\nwant %x", got, data)
+	}
+	if got := hex.EncodeToString(header.Hash()); got != "ddec104f19ad168f76f95baa51aab35f09e6c7d45068ba02cfbd79e8d2f2acb6" {
+		t.Fatalf("hash %s", got)
+	}
+}
@@ -0,0 +1,26 @@
+package blockchain
+
+import "errors"
+
+// Paketin genel hataları. Dönen hatalar bu değerlerden birini sarar (fmt.Errorf ile %w);
+// çağıran taraf sebebi errors.Is ile ayırt edebilir. Blok ve işlem doğrulama hataları validate.go içindedir.
+var (
+	ErrChainNotFound      = errors.New("blockchain does not exist, create one first")
+	ErrChainExists        = errors.New("blockchain already exists")
+	ErrConsensusMismatch  = errors.New("blockchain was created with a different consensus")
+	ErrNetworkMismatch    = errors.New("blockchain belongs to a different network")
+	ErrBlockNotFound      = errors.New("block is not found")
+	ErrTxNotFound         = errors.New("transaction does not exist")
+	ErrTxIndexCorrupt     = errors.New("transaction index is inconsistent, run reindextx")
+	ErrOutputNotFound     = errors.New("output is not in the UTXO set")
+	ErrUndoNotFound       = errors.New("undo record is not found")
+	ErrInvalidAddress     = errors.New("address is not valid")
+	ErrInvalidAmount      = errors.New("amount must be positive and fee must not be negative")
+	ErrInsufficientFunds  = errors.New("not enough funds")
+	ErrInvalidTransaction = errors.New("transaction is invalid")
+	ErrNonStandardScript  = errors.New("no input is locked by a standard script this key can sign")
+	ErrBadContract        = errors.New("script is not a valid atomic swap contract")
+	ErrNoContractOutput   = errors.New("transaction has no output paying to the contract")
+	ErrSecretMismatch     = errors.New("secret does not match the contract's secret hash")
+	ErrDataTooLarge       = errors.New("data does not fit in a data output")
+)
@@ -0,0 +1,114 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// İşlem ücreti, girdilerin toplam değerinden çıktıların toplam değerinin çıkarılmasıyla kalan
+// miktardır. Ücreti bloğu kazan madenci coinbase işlemiyle alır; bu yüzden coinbase işlemi blok
+// ödülü ile bloktaki işlemlerin ücretlerinin toplamı kadar token dağıtabilir.
+
+// MaxBlockTxSize, madencinin yeni bir bloğa koyacağı işlemlerin serileştirilmiş toplam boyutudur (bayt).
+// Havuzda bu sınıra sığmayan işlemler varsa ücret oranı (ücret / bayt) yüksek olanlar seçilir.
+var MaxBlockTxSize = 100000
+
+// TransactionFee fonksiyonu, ana zincirdeki çıktıları harcayan işlemin ücretini döndürür.
+func (bc *BlockChain) TransactionFee(tx *Transaction) (int, error) {
+	if tx.IsCoinbase() {
+		return 0, nil
+	}
+
+	prevTXs := make(map[string]Transaction)
+	for _, in := range tx.Inputs {
+		prevTX, err := bc.FindTransaction(in.ID)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+		}
+		prevTXs[hex.EncodeToString(prevTX.ID)] = prevTX
+	}
+
+	return CheckTransactionInputs(tx, prevTXs)
+}
+
+// FeeRate fonksiyonu, işlemin serileştirilmiş boyutunun bayt başına ödediği ücreti döndürür.
+func FeeRate(tx *Transaction, fee int) float64 {
+	return float64(fee) / float64(len(tx.Serialize()))
+}
+
+// poolEntry, aday işlemi ücreti ve boyutuyla birlikte tutar.
+type poolEntry struct {
+	tx   *Transaction
+	fee  int
+	size int
+}
+
+// SelectTransactions fonksiyonu, havuzdaki işlemlerden yeni blokta yer alacakları seçer ve
+// toplam ücretleriyle birlikte döndürür. Geçerliler ücret oranına göre büyükten küçüğe sıralanır ve
+// toplam boyut MaxBlockTxSize'ı aşmayacak şekilde eklenir. Aynı çıktıyı harcayan işlemlerden yalnızca
+// ücret oranı yüksek olan seçilir; diğerleri seçilen işlem kazıldığında harcanmış çıktıya bağlı kalır.
+// Üçüncü değer, havuzdan çıkarılması gereken işlemlerdir: geçersiz olanlar ve girdisi UTXO setinde
+// bulunmayanlar (ör. zincirdeki başka bir işlemin harcadığı çıktılar). Kilit zamanına ya da göreli
+// kilidine henüz ulaşmamış işlemler havuzda kalır.
+func (bc *BlockChain) SelectTransactions(pool []Transaction) ([]*Transaction, int, []*Transaction) {
+	var entries []poolEntry
+	var invalid []*Transaction
+
+	for i := range pool {
+		tx := &pool[i]
+		if tx.IsCoinbase() {
+			invalid = append(invalid, tx) // coinbase yalnızca kazan madencinin bloğunda yer alabilir
+			continue
+		}
+		if err := bc.VerifyTransaction(tx); err != nil {
+			if !errors.Is(err, ErrTxNotFinal) && !errors.Is(err, ErrSequenceLocked) {
+				invalid = append(invalid, tx)
+			}
+			continue
+		}
+
+		fee, err := bc.TransactionFee(tx)
+		if err != nil {
+			invalid = append(invalid, tx)
+			continue
+		}
+		entries = append(entries, poolEntry{tx, fee, len(tx.Serialize())})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		// fee_i/size_i > fee_j/size_j, bölme yapmadan karşılaştırılır
+		return entries[i].fee*entries[j].size > entries[j].fee*entries[i].size
+	})
+
+	var selected []*Transaction
+	fees, size := 0, 0
+	spent := make(map[string]bool)
+
+	for _, entry := range entries {
+		if size+entry.size > MaxBlockTxSize {
+			continue
+		}
+
+		conflict := false
+		for _, in := range entry.tx.Inputs {
+			if spent[fmt.Sprintf("%x:%d", in.ID, in.Out)] {
+				conflict = true
+				break
+			}
+		}
+		if conflict {
+			continue
+		}
+
+		for _, in := range entry.tx.Inputs {
+			spent[fmt.Sprintf("%x:%d", in.ID, in.Out)] = true
+		}
+		selected = append(selected, entry.tx)
+		fees += entry.fee
+		size += entry.size
+	}
+
+	return selected, fees, invalid
+}
@@ -0,0 +1,89 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// useParams fonksiyonu, test süresince params'ı etkin zincir parametreleri yapar. SetActiveParams'ın
+// değiştirdiği paket ve cüzdan değişkenleri test bitince eski değerlerine döner.
+func useParams(t *testing.T, params Params) {
+	t.Helper()
+
+	active := ActiveParams
+	version, scriptVersion, dataDir := wallet.AddressVersion, wallet.ScriptAddressVersion, wallet.DataDir
+	t.Cleanup(func() {
+		ActiveParams = active
+		wallet.AddressVersion, wallet.ScriptAddressVersion, wallet.DataDir = version, scriptVersion, dataDir
+	})
+
+	SetActiveParams(params)
+}
+
+// newTestChain fonksiyonu, genesis ödülü w'ye giden bellekte bir regtest zinciri oluşturur.
+func newTestChain(t *testing.T, w *wallet.Wallet) (*BlockChain, *UTXOSet) {
+	t.Helper()
+	useParams(t, RegTestParams)
+	chain, err := CreateBlockChain(NewMemoryStore(), string(w.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &UTXOSet{chain}
+	if err := u.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	return chain, u
+}
+
+// Girdisi kazılmış bir blokta harcanan havuz işlemleri yeniden seçilmek yerine atılır; yalnızca kilit
+// zamanını bekleyen işlemler havuzda kalır.
+func TestSelectTransactionsDropsSpentInputs(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+
+	mined, err := NewTransaction(w, string(to.Address()), 5, 1, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflict, err := NewTransaction(w, string(to.Address()), 6, 1, 0, u) // aynı genesis çıktısını harcar
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(1)+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{cb, mined}); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := NewTransaction(w, string(to.Address()), 1, 0, 100, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selected, _, invalid := chain.SelectTransactions([]Transaction{*mined, *conflict, *locked})
+	if len(selected) != 0 {
+		t.Fatalf("selected %d transactions, want none", len(selected))
+	}
+	if len(invalid) != 2 || string(invalid[0].ID) != string(mined.ID) || string(invalid[1].ID) != string(conflict.ID) {
+		t.Fatalf("dropped %v, want the mined and the conflicting transaction", invalid)
+	}
+}
+
+// Zincir oluşturan bir testten sonra etkin parametreler ve cüzdan sürüm baytları eski haline dönmelidir.
+func TestNewTestChainRestoresParams(t *testing.T) {
+	active, version := ActiveParams, wallet.AddressVersion
+
+	t.Run("chain", func(t *testing.T) {
+		newTestChain(t, wallet.MakeWallet())
+		if ActiveParams.Name != RegTestParams.Name || wallet.AddressVersion != RegTestParams.AddressVersion {
+			t.Fatalf("active params %s, address version %d", ActiveParams.Name, wallet.AddressVersion)
+		}
+	})
+
+	if ActiveParams.Name != active.Name || wallet.AddressVersion != version {
+		t.Fatalf("params not restored: %s, address version %d", ActiveParams.Name, wallet.AddressVersion)
+	}
+}
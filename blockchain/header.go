@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// BlockVersion, yeni oluşturulan blokların başlık sürümüdür.
+// Sürüm 2: merkle ağacı her seviyedeki tek düğümü kopyalayarak kurulur (bkz. MerkleTreeVersion).
+const BlockVersion = 2
+
+// BlockHeaderSize, serileştirilmiş blok başlığının bayt cinsinden sabit uzunluğudur:
+// sürüm (4) | önceki hash (32) | merkle kökü (32) | zaman damgası (8) | bits (4) | nonce (8)
+const BlockHeaderSize = 88
+
+// Başlık içindeki alanların bayt konumları
+const (
+	headerVersionOffset    = 0
+	headerPrevHashOffset   = 4
+	headerMerkleRootOffset = 36
+	headerTimestampOffset  = 68
+	headerBitsOffset       = 76
+	headerNonceOffset      = 80
+)
+
+// BlockHeader, bloğun hash'lenen sabit uzunluklu başlığıdır. İşlemler başlığa yalnızca merkle kökü
+// ile girer; bu sayede hash maliyeti işlem sayısından bağımsızdır ve madenci yalnızca nonce
+// baytlarını değiştirerek yeni bir hash deneyebilir.
+type BlockHeader struct {
+	Version    int32
+	PrevHash   [32]byte
+	MerkleRoot [32]byte
+	Timestamp  int64
+	Bits       uint32
+	Nonce      uint64
+}
+
+// Header fonksiyonu, bloğun başlığını oluşturur. Genesis bloğunun önceki hash'i sıfırlardan oluşur.
+func (b *Block) Header() BlockHeader {
+	header := BlockHeader{
+		Version:   b.Version,
+		Timestamp: b.Timestamp,
+		Bits:      b.Bits,
+		Nonce:     uint64(b.Nonce),
+	}
+	copy(header.PrevHash[:], b.PrevHash)
+	copy(header.MerkleRoot[:], b.MerkleRoot)
+
+	return header
+}
+
+// Serialize fonksiyonu, başlığı big-endian olarak BlockHeaderSize uzunluğunda bir byte dizisine yazar.
+func (h BlockHeader) Serialize() []byte {
+	data := make([]byte, BlockHeaderSize)
+
+	binary.BigEndian.PutUint32(data[headerVersionOffset:], uint32(h.Version))
+	copy(data[headerPrevHashOffset:], h.PrevHash[:])
+	copy(data[headerMerkleRootOffset:], h.MerkleRoot[:])
+	binary.BigEndian.PutUint64(data[headerTimestampOffset:], uint64(h.Timestamp))
+	binary.BigEndian.PutUint32(data[headerBitsOffset:], h.Bits)
+	binary.BigEndian.PutUint64(data[headerNonceOffset:], h.Nonce)
+
+	return data
+}
+
+// Hash fonksiyonu, başlığın SHA-256 hash'ini döndürür.
+func (h BlockHeader) Hash() []byte {
+	hash := sha256.Sum256(h.Serialize())
+	return hash[:]
+}
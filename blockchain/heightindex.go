@@ -0,0 +1,96 @@
+package blockchain
+
+import "bytes"
+
+// Ana zincirdeki her yüksekliğin blok hash'i yükseklik indeksinde tutulur (bkz. store.go).
+// İndeks bloklar zincire bağlanırken yazılır, zincirden çıkarılırken silinir; böylece yeniden
+// düzenlemelerden (reorg) sonra da yalnızca ana zinciri gösterir.
+
+// indexHeight fonksiyonu, bloğu yüksekliği ile yükseklik indeksine ekler.
+func indexHeight(txn StoreTxn, block *Block) error {
+	return txn.PutBlockHash(block.Height, block.Hash)
+}
+
+// unindexHeight fonksiyonu, bloğun yüksekliğini yükseklik indeksinden siler.
+func unindexHeight(txn StoreTxn, block *Block) error {
+	return txn.DeleteBlockHash(block.Height)
+}
+
+// GetBlockHashByHeight fonksiyonu, ana zincirde verilen yükseklikteki bloğun hash'ini döndürür.
+func (chain *BlockChain) GetBlockHashByHeight(height int) ([]byte, error) {
+	var hash []byte
+
+	err := chain.Store.View(func(txn StoreTxn) error {
+		var err error
+		hash, err = txn.BlockHash(height)
+		return err
+	})
+
+	return hash, err
+}
+
+// GetBlockByHeight fonksiyonu, ana zincirde verilen yükseklikteki bloğu döndürür.
+func (chain *BlockChain) GetBlockByHeight(height int) (Block, error) {
+	hash, err := chain.GetBlockHashByHeight(height)
+	if err != nil {
+		return Block{}, err
+	}
+
+	return chain.GetBlock(hash)
+}
+
+// GetBlockHashes fonksiyonu, ana zincirde from ve to yükseklikleri (ikisi de dahil) arasındaki
+// blokların hash değerlerini artan yükseklik sırasıyla döndürür. Aralık zincir ucunda kesilir.
+func (chain *BlockChain) GetBlockHashes(from, to int) ([][]byte, error) {
+	var hashes [][]byte
+
+	err := chain.Store.View(func(txn StoreTxn) error {
+		var err error
+		hashes, err = txn.BlockHashes(from, to)
+		return err
+	})
+
+	return hashes, err
+}
+
+// MigrateHeightIndex fonksiyonu, yükseklik indeksi tutulmadan önce oluşturulmuş veritabanlarında
+// indeksi ana zincirden oluşturur. İndeks zaten mevcutsa hiçbir şey yapmaz ve false döner.
+func (chain *BlockChain) MigrateHeightIndex() (bool, error) {
+	tip, err := chain.GetBlock(chain.LastHash())
+	if err != nil {
+		return false, err
+	}
+
+	if hash, err := chain.GetBlockHashByHeight(tip.Height); err == nil && bytes.Equal(hash, tip.Hash) {
+		return false, nil
+	}
+
+	return true, chain.ReindexHeights()
+}
+
+// ReindexHeights fonksiyonu, yükseklik indeksini silip zincir ucundan geriye doğru yeniden oluşturur.
+func (chain *BlockChain) ReindexHeights() error {
+	if err := chain.Store.Clear(SectionHeightIndex); err != nil {
+		return err
+	}
+
+	iter := chain.Iterator()
+
+	for {
+		block, err := iter.Next()
+		if err != nil {
+			return err
+		}
+
+		err = chain.Store.Update(func(txn StoreTxn) error {
+			return indexHeight(txn, block)
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(block.PrevHash) == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Yükseklik sorguları, aralık sorguları ve ileri yönlü iterator ana zincirin bloklarını yükseklik sırasıyla
+// döndürür; zincir ucunun ötesi boştur. Silinen indeks MigrateHeightIndex ile yeniden oluşturulur.
+func TestHeightIndex(t *testing.T) {
+	w := wallet.MakeWallet()
+	chain, _ := newTestChain(t, w)
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := []*Block{&genesis}
+	for i := 0; i < 4; i++ {
+		blocks = append(blocks, mineCoinbase(t, chain, w))
+	}
+	hashes := func(from, to int) [][]byte {
+		var want [][]byte
+		for _, block := range blocks[from : to+1] {
+			want = append(want, block.Hash)
+		}
+		return want
+	}
+
+	check := func(t *testing.T) {
+		for _, block := range blocks {
+			got, err := chain.GetBlockByHeight(block.Height)
+			if err != nil || !bytes.Equal(got.Hash, block.Hash) {
+				t.Fatalf("GetBlockByHeight(%d) = %x, %v, want %x", block.Height, got.Hash, err, block.Hash)
+			}
+		}
+		if _, err := chain.GetBlockByHeight(len(blocks)); !errors.Is(err, ErrBlockNotFound) {
+			t.Fatalf("GetBlockByHeight past the tip = %v, want ErrBlockNotFound", err)
+		}
+
+		ranges := []struct {
+			from, to int
+			want     [][]byte
+		}{
+			{0, 4, hashes(0, 4)},
+			{1, 3, hashes(1, 3)},
+			{3, 100, hashes(3, 4)},
+			{2, 2, hashes(2, 2)},
+			{5, 9, nil},
+			{3, 1, nil},
+		}
+		for _, r := range ranges {
+			got, err := chain.GetBlockHashes(r.from, r.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(r.want) {
+				t.Fatalf("GetBlockHashes(%d, %d) returned %d hashes, want %d", r.from, r.to, len(got), len(r.want))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], r.want[i]) {
+					t.Fatalf("GetBlockHashes(%d, %d)[%d] = %x, want %x", r.from, r.to, i, got[i], r.want[i])
+				}
+			}
+		}
+
+		for from, want := range map[int][]*Block{-5: blocks, 0: blocks, 3: blocks[3:], 5: nil} {
+			iter := chain.ForwardIterator(from)
+			for _, block := range want {
+				got, err := iter.Next()
+				if err != nil || got == nil || !bytes.Equal(got.Hash, block.Hash) {
+					t.Fatalf("ForwardIterator(%d) at height %d: %v, %v", from, block.Height, got, err)
+				}
+			}
+			if got, err := iter.Next(); got != nil || err != nil {
+				t.Fatalf("ForwardIterator(%d) past the tip: %v, %v", from, got, err)
+			}
+		}
+	}
+
+	check(t)
+
+	if migrated, err := chain.MigrateHeightIndex(); err != nil || migrated {
+		t.Fatalf("MigrateHeightIndex on an indexed chain = %v, %v", migrated, err)
+	}
+	if err := chain.Store.Clear(SectionHeightIndex); err != nil {
+		t.Fatal(err)
+	}
+	if migrated, err := chain.MigrateHeightIndex(); err != nil || !migrated {
+		t.Fatalf("MigrateHeightIndex = %v, %v", migrated, err)
+	}
+	check(t)
+}
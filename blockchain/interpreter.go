@@ -0,0 +1,489 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Betik yorumlayıcısının sınırları. Kötü niyetli betiklerin düğümü yormasını engeller.
+const (
+	MaxScriptSize      = 10000 // bir betiğin en fazla bayt sayısı
+	MaxScriptElement   = 520   // yığındaki bir değerin en fazla bayt sayısı
+	MaxStackSize       = 1000  // yığındaki en fazla değer sayısı
+	MaxOpsPerScript    = 201   // bir betikte çalıştırılabilecek en fazla (veri eklemesi olmayan) işlem kodu
+	MaxMultiSigKeys    = 16    // OP_CHECKMULTISIG'in kabul ettiği en fazla public key sayısı
+	maxScriptNumLength = 4     // aritmetik işlemlerin kabul ettiği en uzun sayı
+	lockTimeNumLength  = 5     // kilit zamanı işlemlerinin kabul ettiği en uzun sayı (uint32 değerler için)
+)
+
+// Betik çalıştırma hataları. ExecuteScript'in döndürdüğü hatalar bunlardan birini sarar.
+var (
+	ErrScriptTooLong     = errors.New("script is too long")
+	ErrScriptFailed      = errors.New("script evaluated to false")
+	ErrStackUnderflow    = errors.New("operation needs more stack items")
+	ErrStackOverflow     = errors.New("stack is too large")
+	ErrElementTooLarge   = errors.New("stack element is too large")
+	ErrTooManyOps        = errors.New("script has too many operations")
+	ErrBadOpcode         = errors.New("opcode is not supported")
+	ErrUnbalancedIf      = errors.New("OP_IF/OP_ELSE/OP_ENDIF are unbalanced")
+	ErrVerifyFailed      = errors.New("verify operation failed")
+	ErrEarlyReturn       = errors.New("OP_RETURN was executed")
+	ErrBadNumber         = errors.New("stack item is not a valid number")
+	ErrSigScriptNotPush  = errors.New("unlocking script must only push data")
+	ErrCheckSigNoChecker = errors.New("signature or lock time operation needs a transaction")
+	ErrBadKeyCount       = errors.New("multisig key or signature count is out of range")
+	ErrNegativeLockTime  = errors.New("lock time is negative")
+	ErrUnsatisfiedLock   = errors.New("transaction does not satisfy the script's lock time")
+)
+
+// SignatureChecker, yorumlayıcının betiği çalıştıran işleme ait kontrolleri yapmasını sağlar.
+// scriptCode, imzanın kapsadığı betiktir (harcanan çıktının kilitleme betiği).
+// CheckLockTime, işlemin kilit zamanının en az lockTime olup olmadığını; CheckSequence, girdinin göreli
+// kilidinin en az sequence kadar olup olmadığını döndürür (bkz. locktime.go).
+type SignatureChecker interface {
+	CheckSignature(signature, pubKey []byte, scriptCode Script) bool
+	CheckLockTime(lockTime int64) bool
+	CheckSequence(sequence int64) bool
+}
+
+// ExecuteScript fonksiyonu, bir girdinin kilit açma betiğini (scriptSig) ve harcadığı çıktının kilitleme
+// betiğini (scriptPubKey) aynı yığın üzerinde sırayla çalıştırır. Betikler hatasız biter ve yığının
+// tepesinde doğru bir değer kalırsa nil, aksi halde sebebi saran bir hata döner.
+// scriptPubKey bir betik hash'ine ödeme (P2SH) şablonuysa scriptSig'in son verisi asıl betik (redeem
+// script) kabul edilir; hash'i tuttuktan sonra bu betik scriptSig'in kalan verileriyle ayrıca çalıştırılır.
+// checker nil ise imza işlemleri ErrCheckSigNoChecker ile başarısız olur.
+func ExecuteScript(scriptSig, scriptPubKey Script, checker SignatureChecker) error {
+	if !scriptSig.IsPushOnly() {
+		return ErrSigScriptNotPush
+	}
+
+	vm := &scriptVM{checker: checker}
+	if err := vm.run(scriptSig); err != nil {
+		return fmt.Errorf("unlocking script: %w", err)
+	}
+	sigStack := append([][]byte(nil), vm.stack...) // P2SH için scriptSig'in bıraktığı yığın saklanır
+
+	if err := vm.run(scriptPubKey); err != nil {
+		return fmt.Errorf("locking script: %w", err)
+	}
+	if len(vm.stack) == 0 || !asBool(vm.stack[len(vm.stack)-1]) {
+		return ErrScriptFailed
+	}
+
+	if _, ok := scriptPubKey.ScriptHash(); !ok {
+		return nil
+	}
+
+	// Hash'i tutan asıl betik, scriptSig'in kalan verileriyle çalıştırılır
+	redeemScript := Script(sigStack[len(sigStack)-1]) // şablon en az bir veri gerektirdiğinden yığın boş değildir
+	vm.stack = sigStack[:len(sigStack)-1]
+	if err := vm.run(redeemScript); err != nil {
+		return fmt.Errorf("redeem script: %w", err)
+	}
+	if len(vm.stack) == 0 || !asBool(vm.stack[len(vm.stack)-1]) {
+		return ErrScriptFailed
+	}
+	return nil
+}
+
+// scriptVM, betiklerin çalıştırıldığı yığın makinesidir.
+type scriptVM struct {
+	stack      [][]byte
+	checker    SignatureChecker
+	scriptCode Script // çalışan betik, imzalar bunu kapsar
+	ops        int    // çalışan betikte sayılan işlem kodu sayısı
+}
+
+// countOps fonksiyonu, çalışan betiğin işlem sayısını n kadar arttırır ve sınırı kontrol eder.
+func (vm *scriptVM) countOps(n int) error {
+	vm.ops += n
+	if vm.ops > MaxOpsPerScript {
+		return ErrTooManyOps
+	}
+	return nil
+}
+
+func (vm *scriptVM) push(data []byte) error {
+	if len(data) > MaxScriptElement {
+		return fmt.Errorf("%w: %d bytes", ErrElementTooLarge, len(data))
+	}
+	if len(vm.stack) >= MaxStackSize {
+		return ErrStackOverflow
+	}
+	vm.stack = append(vm.stack, data)
+	return nil
+}
+
+func (vm *scriptVM) pop() ([]byte, error) {
+	if len(vm.stack) == 0 {
+		return nil, ErrStackUnderflow
+	}
+	top := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return top, nil
+}
+
+// peek fonksiyonu, tepeden depth kadar aşağıdaki değeri (0 tepedir) yığından çıkarmadan döndürür.
+func (vm *scriptVM) peek(depth int) ([]byte, error) {
+	if depth >= len(vm.stack) {
+		return nil, ErrStackUnderflow
+	}
+	return vm.stack[len(vm.stack)-1-depth], nil
+}
+
+func (vm *scriptVM) popNum() (int64, error) {
+	data, err := vm.pop()
+	if err != nil {
+		return 0, err
+	}
+	return scriptNum(data, maxScriptNumLength)
+}
+
+func (vm *scriptVM) pushBool(v bool) error {
+	if v {
+		return vm.push([]byte{1})
+	}
+	return vm.push(nil)
+}
+
+// run fonksiyonu, bir betiği mevcut yığın üzerinde çalıştırır.
+func (vm *scriptVM) run(script Script) error {
+	if len(script) > MaxScriptSize {
+		return fmt.Errorf("%w: %d bytes", ErrScriptTooLong, len(script))
+	}
+
+	instructions, err := script.parse()
+	if err != nil {
+		return err
+	}
+
+	vm.scriptCode = script
+	vm.ops = 0
+	var conditions []bool // iç içe OP_IF dallarının çalışıp çalışmadığı
+
+	for _, in := range instructions {
+		executing := true
+		for _, c := range conditions {
+			executing = executing && c
+		}
+
+		if !in.isPush() {
+			if err := vm.countOps(1); err != nil {
+				return err
+			}
+		}
+
+		// Çalışmayan dallarda yalnızca dal yapısı takip edilir
+		if !executing && (in.op < OpIf || in.op > OpEndIf) {
+			continue
+		}
+
+		if err := vm.step(in, &conditions, executing); err != nil {
+			return fmt.Errorf("%s: %w", opcodeName(in.op), err)
+		}
+	}
+
+	if len(conditions) != 0 {
+		return ErrUnbalancedIf
+	}
+	return nil
+}
+
+// step fonksiyonu, tek bir betik adımını çalıştırır.
+func (vm *scriptVM) step(in instruction, conditions *[]bool, executing bool) error {
+	switch op := in.op; {
+	case op == OpFalse || (op > OpFalse && op <= OpPushData4):
+		return vm.push(in.data)
+
+	case op == Op1Negate:
+		return vm.push(scriptNumBytes(-1))
+
+	case op >= OpTrue && op <= Op16:
+		return vm.push(scriptNumBytes(int64(op - OpTrue + 1)))
+
+	case op == OpNop:
+		return nil
+
+	case op == OpIf || op == OpNotIf:
+		branch := false
+		if executing {
+			cond, err := vm.pop()
+			if err != nil {
+				return err
+			}
+			branch = asBool(cond) == (op == OpIf)
+		}
+		*conditions = append(*conditions, branch)
+		return nil
+
+	case op == OpElse:
+		if len(*conditions) == 0 {
+			return ErrUnbalancedIf
+		}
+		last := len(*conditions) - 1
+		(*conditions)[last] = !(*conditions)[last]
+		return nil
+
+	case op == OpEndIf:
+		if len(*conditions) == 0 {
+			return ErrUnbalancedIf
+		}
+		*conditions = (*conditions)[:len(*conditions)-1]
+		return nil
+
+	case op == OpVerify:
+		return vm.verify()
+
+	case op == OpReturn:
+		return ErrEarlyReturn
+
+	case op == Op2Drop:
+		if len(vm.stack) < 2 {
+			return ErrStackUnderflow
+		}
+		vm.stack = vm.stack[:len(vm.stack)-2]
+		return nil
+
+	case op == OpDepth:
+		return vm.push(scriptNumBytes(int64(len(vm.stack))))
+
+	case op == OpDrop:
+		_, err := vm.pop()
+		return err
+
+	case op == OpDup || op == OpOver:
+		depth := 0
+		if op == OpOver {
+			depth = 1
+		}
+		data, err := vm.peek(depth)
+		if err != nil {
+			return err
+		}
+		return vm.push(data)
+
+	case op == OpSwap:
+		if len(vm.stack) < 2 {
+			return ErrStackUnderflow
+		}
+		n := len(vm.stack)
+		vm.stack[n-1], vm.stack[n-2] = vm.stack[n-2], vm.stack[n-1]
+		return nil
+
+	case op == OpSize:
+		data, err := vm.peek(0)
+		if err != nil {
+			return err
+		}
+		return vm.push(scriptNumBytes(int64(len(data))))
+
+	case op == OpEqual || op == OpEqualVerify:
+		a, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		b, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		if err := vm.pushBool(bytes.Equal(a, b)); err != nil {
+			return err
+		}
+		if op == OpEqualVerify {
+			return vm.verify()
+		}
+		return nil
+
+	case op == OpNot:
+		n, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		return vm.pushBool(n == 0)
+
+	case op == OpAdd || op == OpSub || op == OpNumEqual || op == OpNumEqualVerify ||
+		op == OpLessThan || op == OpGreaterThan:
+		b, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		a, err := vm.popNum()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case OpAdd:
+			return vm.push(scriptNumBytes(a + b))
+		case OpSub:
+			return vm.push(scriptNumBytes(a - b))
+		case OpLessThan:
+			return vm.pushBool(a < b)
+		case OpGreaterThan:
+			return vm.pushBool(a > b)
+		}
+		if err := vm.pushBool(a == b); err != nil {
+			return err
+		}
+		if op == OpNumEqualVerify {
+			return vm.verify()
+		}
+		return nil
+
+	case op == OpSha256:
+		data, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(data)
+		return vm.push(hash[:])
+
+	case op == OpHash160:
+		data, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		return vm.push(wallet.PublicKeyHash(data)) // RIPEMD160(SHA256(data))
+
+	case op == OpCheckSig || op == OpCheckSigVerify:
+		pubKey, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		signature, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		if vm.checker == nil {
+			return ErrCheckSigNoChecker
+		}
+		valid := len(signature) != 0 && vm.checker.CheckSignature(signature, pubKey, vm.scriptCode)
+		if err := vm.pushBool(valid); err != nil {
+			return err
+		}
+		if op == OpCheckSigVerify {
+			return vm.verify()
+		}
+		return nil
+
+	case op == OpCheckMultiSig || op == OpCheckMultiSigVerify:
+		valid, err := vm.checkMultiSig()
+		if err != nil {
+			return err
+		}
+		if err := vm.pushBool(valid); err != nil {
+			return err
+		}
+		if op == OpCheckMultiSigVerify {
+			return vm.verify()
+		}
+		return nil
+
+	case op == OpCheckLockTimeVerify || op == OpCheckSequenceVerify:
+		// Değer yığında bırakılır; betikler genellikle ardından OP_DROP kullanır
+		data, err := vm.peek(0)
+		if err != nil {
+			return err
+		}
+		lock, err := scriptNum(data, lockTimeNumLength)
+		if err != nil {
+			return err
+		}
+		if lock < 0 {
+			return ErrNegativeLockTime
+		}
+		if op == OpCheckSequenceVerify && uint32(lock)&SequenceLockTimeDisabled != 0 {
+			return nil // göreli kilidi kapalı değerler için işlem bir şey yapmaz
+		}
+		if vm.checker == nil {
+			return ErrCheckSigNoChecker
+		}
+		if op == OpCheckLockTimeVerify && !vm.checker.CheckLockTime(lock) ||
+			op == OpCheckSequenceVerify && !vm.checker.CheckSequence(lock) {
+			return ErrUnsatisfiedLock
+		}
+		return nil
+	}
+
+	return ErrBadOpcode
+}
+
+// checkMultiSig fonksiyonu, yığından <sig1> ... <sigM> M <pubKey1> ... <pubKeyN> N değerlerini çıkarır ve
+// M imzanın hepsinin, public key'lerle aynı sırada olmak üzere farklı anahtarlara ait olup olmadığını döndürür.
+// Her imza, kendisinden önceki imzaların eşleştiği anahtarlardan sonraki anahtarlarla denenir.
+func (vm *scriptVM) checkMultiSig() (bool, error) {
+	n, err := vm.popNum()
+	if err != nil {
+		return false, err
+	}
+	if n < 0 || n > MaxMultiSigKeys {
+		return false, fmt.Errorf("%w: %d keys", ErrBadKeyCount, n)
+	}
+	if err := vm.countOps(int(n)); err != nil { // her anahtar bir imza denemesi sayılır
+		return false, err
+	}
+	pubKeys := make([][]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		if pubKeys[i], err = vm.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	m, err := vm.popNum()
+	if err != nil {
+		return false, err
+	}
+	if m < 0 || m > n {
+		return false, fmt.Errorf("%w: %d of %d signatures", ErrBadKeyCount, m, n)
+	}
+	signatures := make([][]byte, m)
+	for i := m - 1; i >= 0; i-- {
+		if signatures[i], err = vm.pop(); err != nil {
+			return false, err
+		}
+	}
+	if m > 0 && vm.checker == nil {
+		return false, ErrCheckSigNoChecker
+	}
+
+	key := 0
+	for _, signature := range signatures {
+		for {
+			if key >= len(pubKeys) {
+				return false, nil // imzayla eşleşecek anahtar kalmadı
+			}
+			matched := len(signature) != 0 && vm.checker.CheckSignature(signature, pubKeys[key], vm.scriptCode)
+			key++
+			if matched {
+				break
+			}
+		}
+	}
+	return true, nil
+}
+
+// verify fonksiyonu, yığının tepesindeki değeri çıkarır; değer yanlışsa ErrVerifyFailed döner.
+func (vm *scriptVM) verify() error {
+	top, err := vm.pop()
+	if err != nil {
+		return err
+	}
+	if !asBool(top) {
+		return ErrVerifyFailed
+	}
+	return nil
+}
+
+// asBool fonksiyonu, yığındaki bir değerin doğruluk değerini döndürür. Tüm baytları sıfır olan
+// değerler (son baytı yalnızca işaret biti taşıyan "negatif sıfır" dahil) yanlıştır.
+func asBool(data []byte) bool {
+	for i, b := range data {
+		if b != 0 {
+			return !(i == len(data)-1 && b == 0x80)
+		}
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// testChecker, işlem olmadan imza ve kilit işlemlerini denemek için kullanılır: bir imza yalnızca
+// "sig-" ile başlayıp public key ile devam ediyorsa geçerlidir, kilitler lockTime ve sequence
+// değerlerine kadar sağlanır.
+type testChecker struct {
+	lockTime, sequence int64
+}
+
+func (c testChecker) CheckSignature(signature, pubKey []byte, scriptCode Script) bool {
+	return bytes.Equal(signature, testSig(pubKey))
+}
+
+func (c testChecker) CheckLockTime(lockTime int64) bool { return lockTime <= c.lockTime }
+
+func (c testChecker) CheckSequence(sequence int64) bool { return sequence <= c.sequence }
+
+func testSig(pubKey []byte) []byte {
+	return append([]byte("sig-"), pubKey...)
+}
+
+// script fonksiyonu, testlerdeki betikleri kısaca yazmak içindir: byte değerler işlem kodu, []byte
+// değerler veri, int değerler sayı olarak eklenir.
+func script(items ...interface{}) Script {
+	builder := NewScriptBuilder()
+	for _, item := range items {
+		switch v := item.(type) {
+		case byte:
+			builder.AddOp(v)
+		case []byte:
+			builder.AddData(v)
+		case int:
+			builder.AddInt64(int64(v))
+		default:
+			panic("unexpected script item")
+		}
+	}
+	return builder.Script()
+}
+
+// repeatOp fonksiyonu, n adet aynı işlem kodundan oluşan betiği döndürür.
+func repeatOp(op byte, n int) Script {
+	return Script(bytes.Repeat([]byte{op}, n))
+}
+
+// Her işlem kodu sınıfı için başarılı ve başarısız betikler; başarısız betiklerin hatası beklenen
+// hatayı sarar. İmza ve kilit işlemleri testChecker ile denenir.
+func TestExecuteScript(t *testing.T) {
+	abcHash := sha256.Sum256([]byte("abc"))
+	redeem := script(2, OpEqual)
+	keyA, keyB, keyC := []byte("key a"), []byte("key b"), []byte("key c")
+	multiSig := script(2, keyA, keyB, keyC, 3, OpCheckMultiSig)
+	checker := testChecker{lockTime: 100, sequence: 10}
+
+	// En uzun betik: en büyük değerler eklenip atılır, kalan baytlar tek bir veriyle doldurulur
+	longest := Script(bytes.Repeat(script(bytes.Repeat([]byte{1}, MaxScriptElement), OpDrop), MaxScriptSize/(MaxScriptElement+4)))
+	longest = append(longest, script(bytes.Repeat([]byte{1}, MaxScriptSize-len(longest)-2), OpTrue)...)
+
+	tests := []struct {
+		name         string
+		scriptSig    Script
+		scriptPubKey Script
+		checker      SignatureChecker
+		want         error // nil ise betik başarılı olmalıdır
+	}{
+		// Veri eklemeleri ve sabitler
+		{"push data", script([]byte("a")), script([]byte("a"), OpEqual), nil, nil},
+		{"OP_0 is false", nil, script(OpFalse), nil, ErrScriptFailed},
+		{"OP_1NEGATE", nil, script(Op1Negate, []byte{0x81}, OpEqual), nil, nil},
+		{"OP_16", nil, script(Op16, []byte{16}, OpEqual), nil, nil},
+		{"PUSHDATA1", Script{OpPushData1, 2, 'a', 'b'}, script([]byte("ab"), OpEqual), nil, nil},
+		{"PUSHDATA2", Script{OpPushData2, 2, 0, 'a', 'b'}, script([]byte("ab"), OpEqual), nil, nil},
+		{"PUSHDATA4", Script{OpPushData4, 2, 0, 0, 0, 'a', 'b'}, script([]byte("ab"), OpEqual), nil, nil},
+		{"empty stack", nil, nil, nil, ErrScriptFailed},
+		{"negative zero is false", nil, script([]byte{0x80}), nil, ErrScriptFailed},
+
+		// Bozuk veri eklemeleri
+		{"push past the end", nil, Script{0x05, 'a'}, nil, ErrMalformedScript},
+		{"truncated PUSHDATA1", nil, Script{OpPushData1}, nil, ErrMalformedScript},
+		{"truncated PUSHDATA2", nil, Script{OpPushData2, 1}, nil, ErrMalformedScript},
+		{"truncated PUSHDATA4", nil, Script{OpPushData4, 1, 0, 0}, nil, ErrMalformedScript},
+		{"PUSHDATA1 past the end", nil, Script{OpPushData1, 5, 'a'}, nil, ErrMalformedScript},
+		{"PUSHDATA4 past the end", nil, Script{OpPushData4, 0xff, 0xff, 0xff, 0xff, 'a'}, nil, ErrMalformedScript},
+		{"malformed unlocking script", Script{OpPushData1, 5, 'a'}, script(1), nil, ErrSigScriptNotPush},
+		{"unlocking script with an opcode", script(1, OpDup), script(OpEqual), nil, ErrSigScriptNotPush},
+
+		// Yığın işlemleri
+		{"OP_DUP", script(7), script(OpDup, OpEqual), nil, nil},
+		{"OP_DROP", script(1, 0), script(OpDrop), nil, nil},
+		{"OP_2DROP", script(1, 0, 0), script(Op2Drop), nil, nil},
+		{"OP_OVER", script(7, 8), script(OpOver, 7, OpEqualVerify, OpDrop, OpDrop, 1), nil, nil},
+		{"OP_SWAP", script(7, 8), script(OpSwap, 7, OpEqualVerify, OpDrop, 1), nil, nil},
+		{"OP_DEPTH", script(0, 0, 0), script(OpDepth, 3, OpNumEqual), nil, nil},
+		{"OP_SIZE", script([]byte("abcd")), script(OpSize, 4, OpNumEqual), nil, nil},
+		{"OP_DUP underflow", nil, script(OpDup), nil, ErrStackUnderflow},
+		{"OP_DROP underflow", nil, script(OpDrop), nil, ErrStackUnderflow},
+		{"OP_2DROP underflow", script(1), script(Op2Drop), nil, ErrStackUnderflow},
+		{"OP_OVER underflow", script(1), script(OpOver), nil, ErrStackUnderflow},
+		{"OP_SWAP underflow", script(1), script(OpSwap), nil, ErrStackUnderflow},
+		{"OP_SIZE underflow", nil, script(OpSize), nil, ErrStackUnderflow},
+		{"OP_EQUAL underflow", script(1), script(OpEqual), nil, ErrStackUnderflow},
+
+		// Aritmetik ve karşılaştırma
+		{"OP_ADD", script(2, 3), script(OpAdd, 5, OpNumEqual), nil, nil},
+		{"OP_SUB", script(2, 3), script(OpSub, -1, OpNumEqual), nil, nil},
+		{"OP_ADD large", script(1000, 24), script(OpAdd, 1024, OpNumEqual), nil, nil},
+		{"OP_LESSTHAN", script(2, 3), script(OpLessThan), nil, nil},
+		{"OP_LESSTHAN false", script(3, 2), script(OpLessThan), nil, ErrScriptFailed},
+		{"OP_GREATERTHAN", script(3, 2), script(OpGreaterThan), nil, nil},
+		{"OP_NOT", script(0), script(OpNot), nil, nil},
+		{"OP_NOT of true", script(5), script(OpNot), nil, ErrScriptFailed},
+		{"number too long", script([]byte{1, 0, 0, 0, 0}), script(1, OpAdd), nil, ErrBadNumber},
+		{"OP_ADD underflow", script(1), script(OpAdd), nil, ErrStackUnderflow},
+
+		// Hash işlemleri
+		{"OP_SHA256", script([]byte("abc")), script(OpSha256, abcHash[:], OpEqual), nil, nil},
+		{"OP_HASH160", script([]byte("abc")), script(OpHash160, wallet.PublicKeyHash([]byte("abc")), OpEqualVerify, 1), nil, nil},
+
+		// Doğrulama işlemleri
+		{"OP_VERIFY", script(1, 1), script(OpVerify), nil, nil},
+		{"failed OP_VERIFY", script(1, 0), script(OpVerify), nil, ErrVerifyFailed},
+		{"OP_VERIFY underflow", nil, script(OpVerify), nil, ErrStackUnderflow},
+		{"failed OP_EQUALVERIFY", script(1, 2), script(OpEqualVerify, 1), nil, ErrVerifyFailed},
+		{"failed OP_NUMEQUALVERIFY", script(1, 2), script(OpNumEqualVerify, 1), nil, ErrVerifyFailed},
+		{"failed OP_CHECKSIGVERIFY", script([]byte("bad"), keyA), script(OpCheckSigVerify, 1), checker, ErrVerifyFailed},
+		{"failed OP_CHECKMULTISIGVERIFY", script(testSig(keyB), testSig(keyA)), script(2, keyA, keyB, 2, OpCheckMultiSigVerify, 1), checker, ErrVerifyFailed},
+		{"OP_RETURN", script(1), script(OpReturn), nil, ErrEarlyReturn},
+
+		// Dallanma
+		{"OP_IF", script(1), script(OpIf, 2, OpElse, 3, OpEndIf, 2, OpNumEqual), nil, nil},
+		{"OP_ELSE", script(0), script(OpIf, 2, OpElse, 3, OpEndIf, 3, OpNumEqual), nil, nil},
+		{"OP_NOTIF", script(0), script(OpNotIf, 2, OpElse, 3, OpEndIf, 2, OpNumEqual), nil, nil},
+		{"OP_IF without OP_ELSE", script(0), script(OpIf, OpReturn, OpEndIf, 1), nil, nil},
+		{"nested OP_IF", script(0, 1), script(OpIf, OpIf, OpReturn, OpElse, 7, OpEndIf, OpElse, OpReturn, OpEndIf, 7, OpNumEqual), nil, nil},
+		{"nested OP_IF in a skipped branch", script(0), script(OpIf, 1, OpIf, OpReturn, OpEndIf, OpElse, 7, OpEndIf, 7, OpNumEqual), nil, nil},
+		{"OP_RETURN in the taken branch", script(1), script(OpIf, OpReturn, OpEndIf, 1), nil, ErrEarlyReturn},
+		{"OP_IF underflow", nil, script(OpIf, OpEndIf, 1), nil, ErrStackUnderflow},
+		{"OP_IF without OP_ENDIF", script(1), script(OpIf, 1), nil, ErrUnbalancedIf},
+		{"nested OP_IF without OP_ENDIF", script(1, 1), script(OpIf, OpIf, 1, OpEndIf), nil, ErrUnbalancedIf},
+		{"OP_ELSE without OP_IF", nil, script(OpElse, 1), nil, ErrUnbalancedIf},
+		{"OP_ENDIF without OP_IF", nil, script(1, OpEndIf), nil, ErrUnbalancedIf},
+		{"OP_IF in the unlocking script", Script{OpIf}, nil, nil, ErrSigScriptNotPush},
+
+		// Desteklenmeyen işlem kodları
+		{"unknown opcode", nil, Script{0xff}, nil, ErrBadOpcode},
+		{"OP_RESERVED", nil, Script{0x50}, nil, ErrBadOpcode},
+
+		// Sınırlar
+		{"largest element", script(bytes.Repeat([]byte{1}, MaxScriptElement)), script(OpSize, MaxScriptElement, OpNumEqual), nil, nil},
+		{"element too large", nil, script(bytes.Repeat([]byte{1}, MaxScriptElement+1)), nil, ErrElementTooLarge},
+		{"full stack", repeatOp(OpTrue, MaxStackSize), nil, nil, nil},
+		{"stack overflow", repeatOp(OpTrue, MaxStackSize), script(1), nil, ErrStackOverflow},
+		{"OP_DUP overflow", repeatOp(OpTrue, MaxStackSize), script(OpDup), nil, ErrStackOverflow},
+		{"most operations", nil, append(repeatOp(OpNop, MaxOpsPerScript), OpTrue), nil, nil},
+		{"too many operations", nil, append(repeatOp(OpNop, MaxOpsPerScript+1), OpTrue), nil, ErrTooManyOps},
+		{"skipped operations are counted", script(0), append(append(Script{OpIf}, repeatOp(OpNop, MaxOpsPerScript)...), OpEndIf, OpTrue), nil, ErrTooManyOps},
+		{"multisig keys are counted", nil, append(repeatOp(OpNop, MaxOpsPerScript-2), script(0, 1, keyA, keyB, 2, OpCheckMultiSig)...), checker, ErrTooManyOps},
+		{"longest script", nil, longest, nil, nil},
+		{"script too long", nil, append(longest, OpNop), nil, ErrScriptTooLong},
+
+		// İmzalar
+		{"OP_CHECKSIG", script(testSig(keyA), keyA), script(OpCheckSig), checker, nil},
+		{"OP_CHECKSIG wrong key", script(testSig(keyA), keyB), script(OpCheckSig), checker, ErrScriptFailed},
+		{"OP_CHECKSIG empty signature", script([]byte(nil), keyA), script(OpCheckSig), checker, ErrScriptFailed},
+		{"OP_CHECKSIG without checker", script(testSig(keyA), keyA), script(OpCheckSig), nil, ErrCheckSigNoChecker},
+		{"OP_CHECKSIG underflow", script(keyA), script(OpCheckSig), checker, ErrStackUnderflow},
+		{"2 of 3", script(testSig(keyA), testSig(keyC)), multiSig, checker, nil},
+		{"2 of 3 last keys", script(testSig(keyB), testSig(keyC)), multiSig, checker, nil},
+		{"2 of 3 out of order", script(testSig(keyC), testSig(keyA)), multiSig, checker, ErrScriptFailed},
+		{"2 of 3 same signature twice", script(testSig(keyA), testSig(keyA)), multiSig, checker, ErrScriptFailed},
+		{"2 of 3 one signature", script(testSig(keyA)), multiSig, checker, ErrStackUnderflow},
+		{"0 of 1", nil, script(0, keyA, 1, OpCheckMultiSig), nil, nil},
+		{"too many keys", nil, script(1, 17, OpCheckMultiSig), checker, ErrBadKeyCount},
+		{"negative key count", nil, script(1, -1, OpCheckMultiSig), checker, ErrBadKeyCount},
+		{"more signatures than keys", script(testSig(keyA), testSig(keyA)), script(2, keyA, 1, OpCheckMultiSig), checker, ErrBadKeyCount},
+		{"multisig without checker", script(testSig(keyA)), script(1, keyA, 1, OpCheckMultiSig), nil, ErrCheckSigNoChecker},
+
+		// Kilit zamanları
+		{"OP_CHECKLOCKTIMEVERIFY", nil, script(100, OpCheckLockTimeVerify), checker, nil},
+		{"OP_CHECKLOCKTIMEVERIFY too early", nil, script(101, OpCheckLockTimeVerify), checker, ErrUnsatisfiedLock},
+		{"OP_CHECKLOCKTIMEVERIFY negative", nil, script(-1, OpCheckLockTimeVerify), checker, ErrNegativeLockTime},
+		{"OP_CHECKLOCKTIMEVERIFY without checker", nil, script(1, OpCheckLockTimeVerify), nil, ErrCheckSigNoChecker},
+		{"OP_CHECKLOCKTIMEVERIFY underflow", nil, script(OpCheckLockTimeVerify), checker, ErrStackUnderflow},
+		{"5 byte lock time", nil, script(0xffffffff, OpCheckLockTimeVerify), testChecker{lockTime: 0xffffffff}, nil},
+		{"6 byte lock time", nil, script([]byte{1, 0, 0, 0, 0, 0}, OpCheckLockTimeVerify), checker, ErrBadNumber},
+		{"OP_CHECKSEQUENCEVERIFY", nil, script(10, OpCheckSequenceVerify), checker, nil},
+		{"OP_CHECKSEQUENCEVERIFY too early", nil, script(11, OpCheckSequenceVerify), checker, ErrUnsatisfiedLock},
+		{"OP_CHECKSEQUENCEVERIFY disabled", nil, script(int(SequenceLockTimeDisabled)|11, OpCheckSequenceVerify), nil, nil},
+		{"OP_CHECKSEQUENCEVERIFY negative", nil, script(-1, OpCheckSequenceVerify), checker, ErrNegativeLockTime},
+
+		// P2SH
+		{"P2SH", script(2, []byte(redeem)), PayToScriptHashScript(redeem.Hash160()), nil, nil},
+		{"P2SH redeem script fails", script(3, []byte(redeem)), PayToScriptHashScript(redeem.Hash160()), nil, ErrScriptFailed},
+		{"P2SH wrong redeem script", script(2, []byte(script(2, OpNumEqual))), PayToScriptHashScript(redeem.Hash160()), nil, ErrScriptFailed},
+		{"P2SH redeem script error", script([]byte(script(OpReturn))), PayToScriptHashScript(script(OpReturn).Hash160()), nil, ErrEarlyReturn},
+		{"P2SH multisig", ScriptHashSigScript([][]byte{testSig(keyA), testSig(keyB)}, multiSig), PayToScriptHashScript(multiSig.Hash160()), checker, nil},
+		{"P2SH multisig one signature", ScriptHashSigScript([][]byte{testSig(keyA), []byte("bad")}, multiSig), PayToScriptHashScript(multiSig.Hash160()), checker, ErrScriptFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ExecuteScript(test.scriptSig, test.scriptPubKey, test.checker)
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("ExecuteScript = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// Gerçek bir P2PKH girdisi, çıktının sahibinin imzasıyla doğrulanır. Başka bir anahtarın imzası,
+// değiştirilmiş bir imza ve imzadan sonra değiştirilmiş bir işlem ErrBadSignature ile reddedilir.
+func TestPayToPubKeyHashSignature(t *testing.T) {
+	w, other := wallet.MakeWallet(), wallet.MakeWallet()
+	prev := testTransaction(TxVersion, []TxInput{{bytes.Repeat([]byte{1}, 32), 0, nil, SequenceFinal}}, 10)
+	prev.Outputs[0] = *NewTXOutput(10, string(w.Address()))
+	prev.ID = prev.Hash()
+	prevTXs := map[string]Transaction{hex.EncodeToString(prev.ID): *prev}
+
+	spend := func(t *testing.T) *Transaction {
+		tx := &Transaction{TxVersion, nil, []TxInput{{prev.ID, 0, nil, SequenceFinal}}, []TxOutput{*NewTXOutput(9, string(other.Address()))}, 0}
+		if err := tx.Sign(w.PrivateKey, prevTXs); err != nil {
+			t.Fatal(err)
+		}
+		tx.ID = tx.Hash()
+		return tx
+	}
+
+	if err := spend(t).VerifyInputs(prevTXs); err != nil {
+		t.Fatalf("signed input: %v", err)
+	}
+
+	if err := spend(t).Sign(other.PrivateKey, prevTXs); !errors.Is(err, ErrNonStandardScript) {
+		t.Fatalf("Sign with another key = %v, want ErrNonStandardScript", err)
+	}
+
+	// Başka anahtarın imzası, kendi public key'iyle de çıktının public key'iyle de kabul edilmez
+	forged := spend(t)
+	signature, err := forged.signInput(other.PrivateKey, 0, prev.Outputs[0].ScriptPubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.Inputs[0].ScriptSig = PubKeyHashSigScript(signature, other.PublicKey)
+	if err := forged.VerifyInputs(prevTXs); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("input signed by another key = %v, want ErrBadSignature", err)
+	}
+	forged.Inputs[0].ScriptSig = PubKeyHashSigScript(signature, w.PublicKey)
+	if err := forged.VerifyInputs(prevTXs); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("input with another key's signature = %v, want ErrBadSignature", err)
+	}
+
+	tampered := spend(t)
+	tampered.Inputs[0].ScriptSig[len(tampered.Inputs[0].ScriptSig)-len(w.PublicKey)-2] ^= 0x01
+	if err := tampered.VerifyInputs(prevTXs); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered signature = %v, want ErrBadSignature", err)
+	}
+
+	changed := spend(t)
+	changed.Outputs[0].Value = 10
+	if err := changed.VerifyInputs(prevTXs); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("output changed after signing = %v, want ErrBadSignature", err)
+	}
+}
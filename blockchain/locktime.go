@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Zaman kilitleri. Bir işlemin LockTime alanı işlemin kazılabileceği en erken noktayı verir:
+// LockTimeThreshold'dan küçük değerler blok yüksekliği, diğerleri unix zamanıdır. Zaman kilidi bloğun
+// kendi zaman damgasıyla değil, önceki blokların medyan zamanıyla (CalcPastMedianTime) karşılaştırılır;
+// böylece madenci zaman damgasını ileri alarak kilidi erken açamaz.
+//
+// Girdilerin Sequence alanı göreli kilit taşır: harcanan çıktıyı içeren blok onaylandıktan sonra geçmesi
+// gereken blok sayısı ya da süre. SequenceLockTimeDisabled biti kuruluysa (ör. SequenceFinal) girdinin
+// kilidi yoktur; SequenceLockTimeIsSeconds biti kuruluysa alt 16 bit 512 saniyelik birimlerle süre,
+// değilse blok sayısıdır. Göreli kilitler yalnızca sürüm 3 ve sonrası işlemlerde geçerlidir.
+//
+// Kilitler imzanın kapsadığı alanlardır; imzalandıktan sonra değiştirilemezler. Çıktılar ayrıca
+// OP_CHECKLOCKTIMEVERIFY ve OP_CHECKSEQUENCEVERIFY ile kendilerini harcayan işlemin kilitlerini şart koşabilir.
+
+const (
+	// LockTimeThreshold'dan küçük kilit zamanları blok yüksekliği, büyük ya da eşit olanlar unix zamanıdır.
+	LockTimeThreshold = 500000000
+
+	// SequenceFinal, göreli kilidi olmayan girdilerin sıra numarasıdır.
+	SequenceFinal uint32 = 0xffffffff
+	// SequenceLockTimeDisabled biti kurulu sıra numaraları göreli kilit taşımaz.
+	SequenceLockTimeDisabled uint32 = 1 << 31
+	// SequenceLockTimeIsSeconds biti kuruluysa göreli kilit blok sayısı değil süredir.
+	SequenceLockTimeIsSeconds uint32 = 1 << 22
+	// SequenceLockTimeMask, sıra numarasının kilit değerini taşıyan bitleridir.
+	SequenceLockTimeMask uint32 = 0x0000ffff
+	// SequenceLockTimeGranularity, süre kilitlerinin birimidir: 1 << 9 = 512 saniye.
+	SequenceLockTimeGranularity = 9
+)
+
+// SequenceLockBlocks fonksiyonu, harcanan çıktının onayından sonra blocks blok beklenmesini isteyen sıra numarasını döndürür.
+func SequenceLockBlocks(blocks uint16) uint32 {
+	return uint32(blocks)
+}
+
+// SequenceLockSeconds fonksiyonu, harcanan çıktının onayından sonra en az seconds saniye beklenmesini isteyen
+// sıra numarasını döndürür. Süre 512 saniyelik birimlere yukarı yuvarlanır.
+func SequenceLockSeconds(seconds uint32) uint32 {
+	units := (uint64(seconds) + 1<<SequenceLockTimeGranularity - 1) >> SequenceLockTimeGranularity
+	if units > uint64(SequenceLockTimeMask) {
+		units = uint64(SequenceLockTimeMask)
+	}
+	return SequenceLockTimeIsSeconds | uint32(units)
+}
+
+// IsFinal fonksiyonu, işlemin height yüksekliğindeki ve önceki blokların medyan zamanı medianTime olan
+// bir bloğa girip giremeyeceğini döndürür. LockTime 0 ise işlem her zaman kazılabilir; aksi halde
+// yükseklik ya da medyan zaman en az LockTime olmalıdır.
+// Bitcoin'den farklı olarak girdilerin sıra numarası kilit zamanını etkilemez: tüm girdileri SequenceFinal
+// olan bir işlem de LockTime'a kadar kazılamaz. Cüzdan (NewTransaction) ve atomik takas iade işlemleri
+// kilitli işlemleri SequenceFinal girdilerle oluşturur; Bitcoin kuralı bu imzalı işlemlerin kilidini
+// kaldırırdı. Bu nedenle OP_CHECKLOCKTIMEVERIFY de girdinin sıra numarasına bakmaz.
+func (tx *Transaction) IsFinal(height int, medianTime int64) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+	if tx.LockTime < LockTimeThreshold {
+		return int64(tx.LockTime) <= int64(height)
+	}
+	return int64(tx.LockTime) <= medianTime
+}
+
+// hasLocks fonksiyonu, işlemin bir kilit zamanı ya da göreli kilidi olan bir girdisi olup olmadığını döndürür.
+// Sürüm 3'ten önceki işlemler bu alanları kodlayamaz.
+func (tx *Transaction) hasLocks() bool {
+	if tx.LockTime != 0 {
+		return true
+	}
+	for _, in := range tx.Inputs {
+		if in.Sequence != SequenceFinal {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransactionLocks fonksiyonu, işlemin height yüksekliğinde, önceki blokların medyan zamanı medianTime
+// olan bir bloğa girebilmesi için kilit zamanını ve girdilerinin göreli kilitlerini kontrol eder.
+// blockTXs, aynı blokta işlemden önce gelen işlemlerdir; bunların çıktıları height yüksekliğinde onaylanmış
+// sayılır. Diğer girdilerin harcadığı işlemler ana zincirde aranır.
+// Kilit zamanına ulaşılmamışsa ErrTxNotFinal'ı, göreli kilidi açılmamış girdi için ErrSequenceLocked'ı saran bir hata döner.
+func (chain *BlockChain) CheckTransactionLocks(tx *Transaction, height int, medianTime int64, blockTXs map[string]Transaction) error {
+	if !tx.IsFinal(height, medianTime) {
+		return fmt.Errorf("%w: %x is locked until %d", ErrTxNotFinal, tx.ID, tx.LockTime)
+	}
+
+	if tx.IsCoinbase() || tx.Version < lockTimeTxVersion {
+		return nil
+	}
+
+	for i, in := range tx.Inputs {
+		if in.Sequence&SequenceLockTimeDisabled != 0 {
+			continue
+		}
+		lock := int64(in.Sequence & SequenceLockTimeMask)
+
+		prevHeight, prevTime := height, medianTime // aynı bloktaki çıktılar bu blokta onaylanır
+		if _, inBlock := blockTXs[hex.EncodeToString(in.ID)]; !inBlock {
+			var err error
+			prevHeight, prevTime, err = chain.confirmation(in.ID)
+			if err != nil {
+				return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+			}
+		}
+
+		if in.Sequence&SequenceLockTimeIsSeconds != 0 {
+			if unlock := prevTime + lock<<SequenceLockTimeGranularity; medianTime < unlock {
+				return fmt.Errorf("%w: %x input %d until time %d", ErrSequenceLocked, tx.ID, i, unlock)
+			}
+		} else if unlock := int64(prevHeight) + lock; int64(height) < unlock {
+			return fmt.Errorf("%w: %x input %d until height %d", ErrSequenceLocked, tx.ID, i, unlock)
+		}
+	}
+
+	return nil
+}
+
+// confirmation fonksiyonu, ana zincirdeki ID işlemini içeren bloğun yüksekliğini ve o bloktan önceki
+// blokların medyan zamanını döndürür. Göreli süre kilitleri bu zamandan itibaren sayılır.
+func (chain *BlockChain) confirmation(ID []byte) (int, int64, error) {
+	loc, err := chain.findTxLocation(ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	block, err := chain.GetBlock(loc.BlockHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(block.PrevHash) == 0 {
+		return block.Height, block.Timestamp, nil // genesis bloğunun öncesi yoktur
+	}
+	parent, err := chain.GetBlock(block.PrevHash)
+	if err != nil {
+		return 0, 0, err
+	}
+	medianTime, err := chain.CalcPastMedianTime(&parent)
+	if err != nil {
+		return 0, 0, err
+	}
+	return block.Height, medianTime, nil
+}
+
+// nextBlockLockContext fonksiyonu, zincir ucunun üzerine eklenecek bloğun yüksekliğini ve kilitlerin
+// karşılaştırılacağı medyan zamanı (zincir ucu dahil önceki blokların medyanı) döndürür.
+func (chain *BlockChain) nextBlockLockContext() (int, int64, error) {
+	tip, err := chain.GetBlock(chain.LastHash())
+	if err != nil {
+		return 0, 0, err
+	}
+	medianTime, err := chain.CalcPastMedianTime(&tip)
+	if err != nil {
+		return 0, 0, err
+	}
+	return tip.Height + 1, medianTime, nil
+}
@@ -0,0 +1,155 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Kilit zamanı, LockTimeThreshold'dan küçükse yükseklikle, değilse medyan zamanla karşılaştırılır ve tam
+// kilit noktasında açılır. Girdilerin SequenceFinal olması kilidi kaldırmaz (bkz. IsFinal).
+func TestIsFinal(t *testing.T) {
+	timeLock := uint32(LockTimeThreshold + 1000)
+	tests := []struct {
+		lockTime   uint32
+		height     int
+		medianTime int64
+		want       bool
+	}{
+		{0, 0, 0, true},
+		{100, 99, 1 << 40, false},
+		{100, 100, 0, true},
+		{LockTimeThreshold - 1, LockTimeThreshold - 2, 1 << 40, false},
+		{LockTimeThreshold - 1, LockTimeThreshold - 1, 0, true},
+		{LockTimeThreshold, 1 << 30, LockTimeThreshold - 1, false},
+		{LockTimeThreshold, 0, LockTimeThreshold, true},
+		{timeLock, 1 << 30, int64(timeLock) - 1, false},
+		{timeLock, 0, int64(timeLock), true},
+	}
+	for _, test := range tests {
+		tx := testTransaction(TxVersion, []TxInput{{bytes.Repeat([]byte{1}, 32), 0, nil, SequenceFinal}}, 1)
+		tx.LockTime = test.lockTime
+		if got := tx.IsFinal(test.height, test.medianTime); got != test.want {
+			t.Errorf("lock time %d at height %d, median time %d: IsFinal = %v, want %v",
+				test.lockTime, test.height, test.medianTime, got, test.want)
+		}
+	}
+}
+
+// OP_CHECKLOCKTIMEVERIFY ve OP_CHECKSEQUENCEVERIFY, harcayan işlemin kilidi betikteki değere eşit ya da
+// daha büyükse ve aynı türdense (yükseklik/zaman, blok sayısı/süre) geçer.
+func TestLockTimeOpcodes(t *testing.T) {
+	timeLock := int64(LockTimeThreshold + 1000)
+	spend := func(version int32, lockTime uint32, sequence uint32) *Transaction {
+		tx := testTransaction(version, []TxInput{{bytes.Repeat([]byte{1}, 32), 0, nil, sequence}}, 1)
+		tx.LockTime = lockTime
+		return tx
+	}
+
+	tests := []struct {
+		name string
+		lock int64
+		op   byte
+		tx   *Transaction
+		want error
+	}{
+		{"height lock reached", 100, OpCheckLockTimeVerify, spend(TxVersion, 100, SequenceFinal), nil},
+		{"height lock passed", 100, OpCheckLockTimeVerify, spend(TxVersion, 101, SequenceFinal), nil},
+		{"height lock not reached", 100, OpCheckLockTimeVerify, spend(TxVersion, 99, SequenceFinal), ErrUnsatisfiedLock},
+		{"height lock without lock time", 100, OpCheckLockTimeVerify, spend(TxVersion, 0, SequenceFinal), ErrUnsatisfiedLock},
+		{"time lock reached", timeLock, OpCheckLockTimeVerify, spend(TxVersion, uint32(timeLock), SequenceFinal), nil},
+		{"time lock not reached", timeLock, OpCheckLockTimeVerify, spend(TxVersion, uint32(timeLock-1), SequenceFinal), ErrUnsatisfiedLock},
+		{"height lock with time lock time", 100, OpCheckLockTimeVerify, spend(TxVersion, uint32(timeLock), SequenceFinal), ErrUnsatisfiedLock},
+		{"time lock with height lock time", timeLock, OpCheckLockTimeVerify, spend(TxVersion, LockTimeThreshold-1, SequenceFinal), ErrUnsatisfiedLock},
+		{"highest lock time", int64(^uint32(0)), OpCheckLockTimeVerify, spend(TxVersion, ^uint32(0), SequenceFinal), nil},
+
+		{"blocks reached", 10, OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockBlocks(10)), nil},
+		{"blocks not reached", 10, OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockBlocks(9)), ErrUnsatisfiedLock},
+		{"seconds reached", int64(SequenceLockSeconds(1024)), OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockSeconds(1024)), nil},
+		{"seconds not reached", int64(SequenceLockSeconds(1024)), OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockSeconds(512)), ErrUnsatisfiedLock},
+		{"blocks with seconds", 1, OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockSeconds(1024)), ErrUnsatisfiedLock},
+		{"seconds with blocks", int64(SequenceLockSeconds(512)), OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockBlocks(10)), ErrUnsatisfiedLock},
+		{"final input", 1, OpCheckSequenceVerify, spend(TxVersion, 0, SequenceFinal), ErrUnsatisfiedLock},
+		{"disabled input lock", 1, OpCheckSequenceVerify, spend(TxVersion, 0, SequenceLockTimeDisabled|10), ErrUnsatisfiedLock},
+		{"transaction before relative locks", 1, OpCheckSequenceVerify, spend(lockTimeTxVersion-1, 0, 10), ErrUnsatisfiedLock},
+		{"disabled script lock", int64(SequenceLockTimeDisabled | 10), OpCheckSequenceVerify, spend(TxVersion, 0, SequenceFinal), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scriptPubKey := NewScriptBuilder().AddInt64(test.lock).AddOp(test.op, OpDrop, OpTrue).Script()
+			err := ExecuteScript(nil, scriptPubKey, txSignatureChecker{test.tx, 0})
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("ExecuteScript = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// CheckTransactionLocks kilit zamanını ve göreli kilitleri tam açıldıkları yükseklik ve medyan zamanda kabul
+// eder, bir önceki noktada reddeder. Göreli kilitler harcanan çıktıyı içeren bloktan (aynı bloktaki
+// çıktılar için bu bloktan) sayılır.
+func TestCheckTransactionLocks(t *testing.T) {
+	w := wallet.MakeWallet()
+	chain, _ := newTestChain(t, w)
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := mineCoinbase(t, chain, w)
+	blockTime, err := chain.CalcPastMedianTime(&genesis) // 1. bloğun çıktılarının onay zamanı
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisCB, blockCB := genesis.Transactions[0].ID, block.Transactions[0].ID
+	timeLock := uint32(LockTimeThreshold + 1000)
+	seconds := SequenceLockSeconds(1024)
+	inBlock := testTransaction(TxVersion, []TxInput{{bytes.Repeat([]byte{2}, 32), 0, nil, SequenceFinal}}, 1)
+	missing := bytes.Repeat([]byte{3}, 32)
+
+	tests := []struct {
+		name       string
+		version    int32
+		lockTime   uint32
+		prev       []byte
+		sequence   uint32
+		height     int
+		medianTime int64
+		want       error
+	}{
+		{"height lock not reached", TxVersion, 10, genesisCB, SequenceFinal, 9, 0, ErrTxNotFinal},
+		{"height lock reached", TxVersion, 10, genesisCB, SequenceFinal, 10, 0, nil},
+		{"time lock not reached", TxVersion, timeLock, genesisCB, SequenceFinal, 1 << 20, int64(timeLock) - 1, ErrTxNotFinal},
+		{"time lock reached", TxVersion, timeLock, genesisCB, SequenceFinal, 0, int64(timeLock), nil},
+
+		{"blocks after genesis not reached", TxVersion, 0, genesisCB, SequenceLockBlocks(5), 4, 1 << 40, ErrSequenceLocked},
+		{"blocks after genesis reached", TxVersion, 0, genesisCB, SequenceLockBlocks(5), 5, 0, nil},
+		{"blocks after block 1 not reached", TxVersion, 0, blockCB, SequenceLockBlocks(5), 5, 1 << 40, ErrSequenceLocked},
+		{"blocks after block 1 reached", TxVersion, 0, blockCB, SequenceLockBlocks(5), 6, 0, nil},
+		{"seconds after genesis not reached", TxVersion, 0, genesisCB, seconds, 1 << 20, genesis.Timestamp + 1023, ErrSequenceLocked},
+		{"seconds after genesis reached", TxVersion, 0, genesisCB, seconds, 0, genesis.Timestamp + 1024, nil},
+		{"seconds after block 1 not reached", TxVersion, 0, blockCB, seconds, 1 << 20, blockTime + 1023, ErrSequenceLocked},
+		{"seconds after block 1 reached", TxVersion, 0, blockCB, seconds, 0, blockTime + 1024, nil},
+		{"disabled relative lock", TxVersion, 0, genesisCB, SequenceLockTimeDisabled | 5, 0, 0, nil},
+		{"relative lock before version 3", lockTimeTxVersion - 1, 0, genesisCB, SequenceLockBlocks(5), 0, 0, nil},
+
+		{"output of the same block", TxVersion, 0, inBlock.ID, SequenceLockBlocks(1), 1 << 20, 1 << 40, ErrSequenceLocked},
+		{"output of the same block without lock", TxVersion, 0, inBlock.ID, SequenceLockBlocks(0), 2, 0, nil},
+		{"missing output", TxVersion, 0, missing, SequenceLockBlocks(1), 1 << 20, 1 << 40, ErrMissingInput},
+		{"missing output without relative lock", TxVersion, 0, missing, SequenceFinal, 0, 0, nil},
+	}
+	blockTXs := map[string]Transaction{hex.EncodeToString(inBlock.ID): *inBlock}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := testTransaction(test.version, []TxInput{{test.prev, 0, nil, test.sequence}}, 1)
+			tx.LockTime = test.lockTime
+			err := chain.CheckTransactionLocks(tx, test.height, test.medianTime, blockTXs)
+			if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("CheckTransactionLocks = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
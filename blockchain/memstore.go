@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var errReadOnlyTxn = errors.New("write in a read-only transaction")
+
+// memoryBackend, zincir verisini bellekte tutar. Testler ve aynı süreçte çalışan çok düğümlü
+// simülasyonlar için kullanılır; süreç bittiğinde veri kaybolur.
+type memoryBackend struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStore fonksiyonu, boş bir bellek içi ChainStore oluşturur.
+func NewMemoryStore() ChainStore {
+	return kvStore{&memoryBackend{data: make(map[string][]byte)}}
+}
+
+func (m *memoryBackend) view(fn func(kvTxn) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return fn(&memoryTxn{m: m})
+}
+
+// update fonksiyonu, değişiklikleri doğrudan uygular ve her anahtarın önceki değerini saklar;
+// fn hata döndürürse değişiklikler geri alınır. Yazma kilidi tutulduğu için okuyucular ara durumu görmez.
+func (m *memoryBackend) update(fn func(kvTxn) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txn := &memoryTxn{m: m, writable: true, previous: make(map[string][]byte)}
+	if err := fn(txn); err != nil {
+		txn.rollback()
+		return err
+	}
+	return nil
+}
+
+func (m *memoryBackend) deletePrefix(prefix []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.data {
+		if bytes.HasPrefix([]byte(key), prefix) {
+			delete(m.data, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryBackend) close() error {
+	return nil
+}
+
+// memoryTxn, kvTxn'i memoryBackend üzerinde uygular. previous, Update sırasında değişen
+// anahtarların ilk değerlerini tutar (nil: anahtar yoktu).
+type memoryTxn struct {
+	m        *memoryBackend
+	writable bool
+	previous map[string][]byte
+}
+
+func (t *memoryTxn) get(key []byte) ([]byte, error) {
+	value, ok := t.m.data[string(key)]
+	if !ok {
+		return nil, errKeyNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+func (t *memoryTxn) set(key, value []byte) error {
+	if !t.writable {
+		return errReadOnlyTxn
+	}
+	t.remember(string(key))
+	t.m.data[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (t *memoryTxn) delete(key []byte) error {
+	if !t.writable {
+		return errReadOnlyTxn
+	}
+	t.remember(string(key))
+	delete(t.m.data, string(key))
+	return nil
+}
+
+func (t *memoryTxn) remember(key string) {
+	if _, ok := t.previous[key]; ok {
+		return
+	}
+	t.previous[key] = t.m.data[key]
+}
+
+func (t *memoryTxn) rollback() {
+	for key, value := range t.previous {
+		if value == nil {
+			delete(t.m.data, key)
+		} else {
+			t.m.data[key] = value
+		}
+	}
+}
+
+// seek fonksiyonu, anahtarları fn çağrılmadan önce toplar; fn içinde yapılan yazmalar dolaşımı etkilemez.
+func (t *memoryTxn) seek(prefix, start []byte, fn func(key, value []byte) (bool, error)) error {
+	var keys []string
+	for key := range t.m.data {
+		if bytes.HasPrefix([]byte(key), prefix) && key >= string(start) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := t.m.data[key]
+		if !ok {
+			continue
+		}
+		more, err := fn([]byte(key), append([]byte{}, value...))
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
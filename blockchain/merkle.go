@@ -1,9 +1,18 @@
 package blockchain
 
-import "crypto/sha256"
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+// MerkleTreeVersion, işlemlerin merkle ağacının her seviyede tek kalan düğümü kopyalayarak
+// kurulduğu ilk blok sürümüdür. Daha eski bloklar legacyMerkleRoot ile doğrulanır.
+const MerkleTreeVersion = 2
 
 type MerkleTree struct {
 	RootNode *MerkleNode
+	levels   [][]*MerkleNode // levels[0] yapraklar, son seviye yalnızca kökü içerir
 }
 
 type MerkleNode struct {
@@ -12,6 +21,14 @@ type MerkleNode struct {
 	Data  []byte
 }
 
+// MerkleProof, bir yaprağın merkle köküne bağlandığını gösteren kanıttır. Hashes, yapraktan köke
+// doğru her seviyedeki kardeş düğümün hash'idir; Index yaprağın sırasıdır ve bitleri kardeşin
+// hangi tarafta olduğunu belirler (0: sağda, 1: solda).
+type MerkleProof struct {
+	Index  int
+	Hashes [][]byte
+}
+
 func NewMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 	node := MerkleNode{}
 
@@ -19,9 +36,7 @@ func NewMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 		hash := sha256.Sum256(data)
 		node.Data = hash[:]
 	} else {
-		prevHash := append(left.Data, right.Data...)
-		hash := sha256.Sum256(prevHash)
-		node.Data = hash[:]
+		node.Data = hashPair(left.Data, right.Data)
 	}
 
 	node.Left = left
@@ -30,26 +45,130 @@ func NewMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 	return &node
 }
 
+// hashPair fonksiyonu, iki çocuk düğümün hash'lerini birleştirip hash'ler.
+func hashPair(left, right []byte) []byte {
+	data := make([]byte, 0, len(left)+len(right))
+	data = append(data, left...)
+	data = append(data, right...)
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// NewMerkleTree fonksiyonu, verilerden bir merkle ağacı kurar. Her seviyede düğüm sayısı tekse
+// son düğüm kendisiyle eşlenir. Boş veri için kök sıfırlardan oluşur.
+// Aynı verinin iki kez bulunması aynı kökü üretebileceğinden bloklarda tekrar eden işlem kabul edilmez.
 func NewMerkleTree(data [][]byte) *MerkleTree {
-	var nodes []MerkleNode
+	if len(data) == 0 {
+		return &MerkleTree{RootNode: &MerkleNode{Data: make([]byte, sha256.Size)}}
+	}
+
+	var nodes []*MerkleNode
+	for _, d := range data {
+		nodes = append(nodes, NewMerkleNode(nil, nil, d))
+	}
+
+	levels := [][]*MerkleNode{nodes}
+	for len(nodes) > 1 {
+		var level []*MerkleNode
+		for j := 0; j < len(nodes); j += 2 {
+			right := nodes[j]
+			if j+1 < len(nodes) {
+				right = nodes[j+1]
+			}
+			level = append(level, NewMerkleNode(nodes[j], right, nil))
+		}
+		levels = append(levels, level)
+		nodes = level
+	}
+
+	return &MerkleTree{RootNode: nodes[0], levels: levels}
+}
+
+// Proof fonksiyonu, verinin ağaçta bulunduğunu gösteren kanıtı oluşturur.
+func (t *MerkleTree) Proof(data []byte) (*MerkleProof, error) {
+	if len(t.levels) == 0 {
+		return nil, errors.New("merkle tree has no leaves")
+	}
+
+	leaf := sha256.Sum256(data)
+	index := -1
+	for i, node := range t.levels[0] {
+		if bytes.Equal(node.Data, leaf[:]) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("data is not in the merkle tree")
+	}
+
+	proof := &MerkleProof{Index: index}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling >= len(level) { // tek kalan düğüm kendisiyle eşlenmişti
+			sibling = index
+		}
+		proof.Hashes = append(proof.Hashes, level[sibling].Data)
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyProof fonksiyonu, kanıtın veriyi bu ağacın köküne bağladığını kontrol eder.
+func (t *MerkleTree) VerifyProof(data []byte, proof *MerkleProof) bool {
+	return VerifyMerkleProof(t.RootNode.Data, data, proof)
+}
+
+// VerifyMerkleProof fonksiyonu, kanıtın veriyi verilen merkle köküne bağladığını kontrol eder.
+// Ağacın kendisine ihtiyaç duymaz; yalnızca blok başlığını bilen hafif istemciler tarafından kullanılabilir.
+func VerifyMerkleProof(root, data []byte, proof *MerkleProof) bool {
+	if proof == nil || proof.Index < 0 || (len(proof.Hashes) < 63 && proof.Index >= 1<<len(proof.Hashes)) {
+		return false
+	}
+
+	leaf := sha256.Sum256(data)
+	hash := leaf[:]
+	index := proof.Index
+
+	for _, sibling := range proof.Hashes {
+		if index%2 == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+		index /= 2
+	}
+
+	return bytes.Equal(hash, root)
+}
+
+// legacyMerkleRoot fonksiyonu, MerkleTreeVersion öncesindeki bloklarda kullanılan kökü hesaplar.
+// Eski kurulum yalnızca en alttaki tek yaprağı kopyalar ve len(data)/2 kez eşleme yapar; bu yüzden
+// yalnızca 1-4 işlem için kök üretebilir. Diğer durumlarda nil döner.
+func legacyMerkleRoot(data [][]byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
 	if len(data)%2 != 0 {
-		data = append(data, data[len(data)-1])
+		data = append(data[:len(data):len(data)], data[len(data)-1])
 	}
 
-	for _, data := range data {
-		node := NewMerkleNode(nil, nil, data)
-		nodes = append(nodes, *node)
+	var nodes []*MerkleNode
+	for _, d := range data {
+		nodes = append(nodes, NewMerkleNode(nil, nil, d))
 	}
 
 	for i := 0; i < len(data)/2; i++ {
-		var level []MerkleNode
+		if len(nodes)%2 != 0 {
+			return nil
+		}
+		var level []*MerkleNode
 		for j := 0; j < len(nodes); j += 2 {
-			node := NewMerkleNode(&nodes[j], &nodes[j+1], nil)
-			level = append(level, *node)
+			level = append(level, NewMerkleNode(nodes[j], nodes[j+1], nil))
 		}
 		nodes = level
 	}
 
-	tree := MerkleTree{&nodes[0]}
-	return &tree
+	return nodes[0].Data
 }
@@ -0,0 +1,163 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// merkleLeaves fonksiyonu, testlerde kullanılan n farklı yaprak verisini döndürür.
+func merkleLeaves(n int) [][]byte {
+	var data [][]byte
+	for i := 0; i < n; i++ {
+		data = append(data, []byte(fmt.Sprintf("tx %d", i)))
+	}
+	return data
+}
+
+// referenceMerkleRoot fonksiyonu, kökü ağaç yapısı kurmadan özyinelemeli olarak hesaplar: yapraklar
+// sha256, her seviyede tek kalan düğüm kendisiyle eşlenir.
+func referenceMerkleRoot(hashes [][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	var level [][]byte
+	for i := 0; i < len(hashes); i += 2 {
+		right := hashes[i]
+		if i+1 < len(hashes) {
+			right = hashes[i+1]
+		}
+		level = append(level, hashPair(hashes[i], right))
+	}
+	return referenceMerkleRoot(level)
+}
+
+func leafHash(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Her yaprak sayısında (tek sayılar dahil) kök referans hesapla aynıdır ve her yaprağın kanıtı köke
+// bağlanır. Değiştirilmiş kanıtlar, başka veriler ve aralık dışı indeksler reddedilir.
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		data := merkleLeaves(n)
+		tree := NewMerkleTree(data)
+
+		var leaves [][]byte
+		for _, d := range data {
+			leaves = append(leaves, leafHash(d))
+		}
+		root := referenceMerkleRoot(leaves)
+		if !bytes.Equal(tree.RootNode.Data, root) {
+			t.Fatalf("%d leaves: root %x, want %x", n, tree.RootNode.Data, root)
+		}
+
+		for i, d := range data {
+			proof, err := tree.Proof(d)
+			if err != nil {
+				t.Fatalf("%d leaves: Proof(%d): %v", n, i, err)
+			}
+			if proof.Index != i {
+				t.Fatalf("%d leaves: proof index %d, want %d", n, proof.Index, i)
+			}
+			if !tree.VerifyProof(d, proof) || !VerifyMerkleProof(root, d, proof) {
+				t.Fatalf("%d leaves: proof of leaf %d does not verify", n, i)
+			}
+
+			for level := range proof.Hashes {
+				tampered := &MerkleProof{Index: proof.Index, Hashes: append([][]byte(nil), proof.Hashes...)}
+				tampered.Hashes[level] = append([]byte(nil), proof.Hashes[level]...)
+				tampered.Hashes[level][0] ^= 0x01
+				if VerifyMerkleProof(root, d, tampered) {
+					t.Fatalf("%d leaves: proof of leaf %d verifies with hash %d tampered", n, i, level)
+				}
+			}
+			if VerifyMerkleProof(root, []byte("other"), proof) {
+				t.Fatalf("%d leaves: proof of leaf %d verifies other data", n, i)
+			}
+			if len(proof.Hashes) > 0 && VerifyMerkleProof(root, d, &MerkleProof{Index: proof.Index, Hashes: proof.Hashes[1:]}) {
+				t.Fatalf("%d leaves: truncated proof of leaf %d verifies", n, i)
+			}
+			for _, index := range []int{-1, 1 << len(proof.Hashes)} {
+				if VerifyMerkleProof(root, d, &MerkleProof{Index: index, Hashes: proof.Hashes}) {
+					t.Fatalf("%d leaves: proof of leaf %d verifies with index %d", n, i, index)
+				}
+			}
+			if sibling := i ^ 1; sibling < n && VerifyMerkleProof(root, d, &MerkleProof{Index: sibling, Hashes: proof.Hashes}) {
+				t.Fatalf("%d leaves: proof of leaf %d verifies at index %d", n, i, sibling)
+			}
+		}
+
+		if _, err := tree.Proof([]byte("missing")); err == nil {
+			t.Fatalf("%d leaves: proof for missing data", n)
+		}
+	}
+
+	if VerifyMerkleProof(NewMerkleTree(merkleLeaves(2)).RootNode.Data, []byte("tx 0"), nil) {
+		t.Fatal("nil proof verifies")
+	}
+	if _, err := NewMerkleTree(nil).Proof([]byte("tx 0")); err == nil {
+		t.Fatal("proof from an empty tree")
+	}
+}
+
+// Eski kurulum yalnızca en alttaki tek yaprağı kopyalar ve len(data)/2 kez eşler. 1-4 işlem için kök
+// bu şekilde hesaplanır; daha fazla işlemde eski kurulum çöktüğünden bu tür eski bloklar hiç
+// oluşturulmamıştır ve nil döner (CheckBlockSanity bunları ErrBadMerkleRoot ile reddeder).
+func TestLegacyMerkleRoot(t *testing.T) {
+	h := func(i int) []byte { return leafHash([]byte(fmt.Sprintf("tx %d", i))) }
+
+	want := [][]byte{
+		hashPair(h(0), h(0)),
+		hashPair(h(0), h(1)),
+		hashPair(hashPair(h(0), h(1)), hashPair(h(2), h(2))),
+		hashPair(hashPair(h(0), h(1)), hashPair(h(2), h(3))),
+	}
+	for i, root := range want {
+		if got := legacyMerkleRoot(merkleLeaves(i + 1)); !bytes.Equal(got, root) {
+			t.Errorf("%d transactions: legacy root %x, want %x", i+1, got, root)
+		}
+	}
+
+	for n := 5; n <= 9; n++ {
+		if got := legacyMerkleRoot(merkleLeaves(n)); got != nil {
+			t.Errorf("%d transactions: legacy root %x, want nil", n, got)
+		}
+	}
+	if got := legacyMerkleRoot(nil); got != nil {
+		t.Errorf("no transactions: legacy root %x, want nil", got)
+	}
+}
+
+// Bir bloğun işlem kanıtı, işlemin serileştirilmiş hali ile blok başlığındaki MerkleRoot'a bağlanır.
+// Merkle ağacından önceki sürümdeki bloklar ve blokta olmayan işlemler için kanıt üretilmez.
+func TestTransactionProof(t *testing.T) {
+	var txs []*Transaction
+	for i := 0; i < 3; i++ {
+		tx := testTransaction(TxVersion, []TxInput{{bytes.Repeat([]byte{byte(i)}, 32), 0, nil, SequenceFinal}}, i+1)
+		txs = append(txs, tx)
+	}
+	block := &Block{Version: BlockVersion, Transactions: txs}
+	block.MerkleRoot = block.HashTransactions()
+
+	for _, tx := range txs {
+		proof, err := block.TransactionProof(tx.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMerkleProof(block.MerkleRoot, tx.Serialize(), proof) {
+			t.Fatalf("proof of %x does not verify", tx.ID)
+		}
+	}
+
+	if _, err := block.TransactionProof(bytes.Repeat([]byte{0x99}, 32)); err == nil {
+		t.Fatal("proof for a transaction that is not in the block")
+	}
+	block.Version = MerkleTreeVersion - 1
+	if _, err := block.TransactionProof(txs[0].ID); !errors.Is(err, ErrBadVersion) {
+		t.Fatalf("proof from a legacy block: %v, want ErrBadVersion", err)
+	}
+}
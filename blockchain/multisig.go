@@ -0,0 +1,160 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Çoklu imzalı (M-of-N) çıktılar, MultiSigScript betiğinin hash'ine ödeme yapan P2SH çıktılarıdır; adresleri
+// ScriptAddress ile alınır. Harcayan girdinin kilit açma betiği <sig1> ... <sigK> <redeemScript> biçimindedir.
+// NewMultiSigTransaction girdileri yalnızca asıl betikle (imzasız) oluşturur; her anahtar sahibi Sign ile kendi
+// imzasını ekler. İmzalar asıl betikteki public key sırasıyla tutulur ve M imzaya ulaşıldığında girdi geçerli olur.
+
+// NewMultiSigTransaction fonksiyonu, redeemScript çoklu imza betiğinin adresindeki çıktılardan to adresine
+// amount gönderen imzasız bir işlem oluşturur. Para üstü aynı çoklu imza adresine döner.
+// İşlem, betikteki anahtarlardan gereken sayıda imza Sign ile eklenene kadar geçersizdir.
+// Geçersiz girdiler için ErrNonStandardScript, ErrInvalidAddress, ErrInvalidAmount ya da
+// ErrInsufficientFunds'ı saran bir hata döner.
+func NewMultiSigTransaction(redeemScript Script, to string, amount, fee int, UTXO *UTXOSet) (*Transaction, error) {
+	var inputs []TxInput
+	var outputs []TxOutput
+
+	if _, _, ok := redeemScript.MultiSig(); !ok {
+		return nil, fmt.Errorf("%w: redeem script is %s", ErrNonStandardScript, redeemScript.Class())
+	}
+
+	if !wallet.ValidateAddress(to) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, to)
+	}
+
+	if amount <= 0 || fee < 0 || amount > MaxMoney || fee > MaxMoney {
+		return nil, fmt.Errorf("%w: amount %d, fee %d", ErrInvalidAmount, amount, fee)
+	}
+
+	lockingScript := PayToScriptHashScript(redeemScript.Hash160())
+	acc, validOutputs, err := UTXO.FindSpendableScriptOutputs(lockingScript, amount+fee)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc < amount+fee {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrInsufficientFunds, acc, amount+fee)
+	}
+
+	for txid, outs := range validOutputs {
+		txID, err := hex.DecodeString(txid)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, out := range outs {
+			input := TxInput{txID, out, ScriptHashSigScript(nil, redeemScript), SequenceFinal} //imzalar Sign ile eklenir
+			inputs = append(inputs, input)
+		}
+	}
+
+	outputs = append(outputs, *NewTXOutput(amount, to))
+
+	if change := acc - amount - fee; change > 0 {
+		outputs = append(outputs, TxOutput{change, lockingScript})
+	}
+
+	tx := Transaction{TxVersion, nil, inputs, outputs, 0}
+	tx.ID = tx.Hash()
+
+	return &tx, nil
+}
+
+// signMultiSigInput fonksiyonu, inIdx numaralı P2SH girdisinin asıl betiği bir çoklu imza betiğiyse ve
+// pubKey betikteki anahtarlardan biriyse girdiye privKey'in imzasını ekler. Girdide bulunan geçerli
+// imzalar korunur, geçersiz olanlar atılır. Gereken imza sayısına zaten ulaşılmışsa imza eklenmez.
+// Anahtar bu girdiyi imzalayabiliyorsa (imzası zaten olsa bile) true döner.
+func (tx *Transaction) signMultiSigInput(privKey ecdsa.PrivateKey, pubKey []byte, inIdx int, scriptPubKey Script) (bool, error) {
+	data, err := tx.Inputs[inIdx].ScriptSig.PushedData()
+	if err != nil || len(data) == 0 {
+		return false, nil // asıl betik girdide yoksa hangi anahtarların imzalayacağı bilinemez
+	}
+	redeemScript := Script(data[len(data)-1])
+
+	scriptHash, _ := scriptPubKey.ScriptHash()
+	if !bytes.Equal(redeemScript.Hash160(), scriptHash) {
+		return false, nil
+	}
+	required, pubKeys, ok := redeemScript.MultiSig()
+	if !ok {
+		return false, nil
+	}
+
+	keyIdx := -1
+	for i, key := range pubKeys {
+		if bytes.Equal(key, pubKey) {
+			keyIdx = i
+		}
+	}
+	if keyIdx < 0 {
+		return false, nil // anahtar bu çoklu imzada yok
+	}
+
+	signatures, count := tx.matchMultiSigSignatures(inIdx, data[:len(data)-1], redeemScript, pubKeys)
+	if signatures[keyIdx] == nil && count < required {
+		signature, err := tx.signInput(privKey, inIdx, redeemScript)
+		if err != nil {
+			return false, err
+		}
+		signatures[keyIdx] = signature
+	}
+
+	var ordered [][]byte
+	for _, signature := range signatures {
+		if signature != nil {
+			ordered = append(ordered, signature)
+		}
+	}
+	tx.Inputs[inIdx].ScriptSig = ScriptHashSigScript(ordered, redeemScript)
+	return true, nil
+}
+
+// matchMultiSigSignatures fonksiyonu, inIdx numaralı girdideki imzaları işlemin imza hash'ine göre doğrular
+// ve her geçerli imzayı ait olduğu anahtarın sırasına yerleştirir. Hiçbir anahtara uymayan imzalar ve aynı
+// anahtarın ikinci imzası atılır. Anahtar sırasındaki imzaları ve geçerli imza sayısını döndürür.
+func (tx *Transaction) matchMultiSigSignatures(inIdx int, data [][]byte, redeemScript Script, pubKeys [][]byte) ([][]byte, int) {
+	checker := txSignatureChecker{tx, inIdx}
+	signatures := make([][]byte, len(pubKeys))
+	count := 0
+	for _, signature := range data {
+		for i, key := range pubKeys {
+			if signatures[i] == nil && checker.CheckSignature(signature, key, redeemScript) {
+				signatures[i] = signature
+				count++
+				break
+			}
+		}
+	}
+	return signatures, count
+}
+
+// MultiSigProgress fonksiyonu, inIdx numaralı girdi çoklu imzalı bir P2SH çıktısını harcıyorsa girdideki
+// geçerli imza sayısını ve gereken imza sayısını döndürür. Yalnızca işlemin imza hash'ine göre betikteki
+// farklı bir anahtarla doğrulanan imzalar sayılır; bozuk, başka bir işleme ait ya da aynı anahtarın
+// tekrarlanan imzaları sayılmaz. Girdi böyle bir kilit açma betiği taşımıyorsa ok false olur.
+// Asıl betiğin harcanan çıktıyla eşleştiği kontrol edilmez; bunun için Transaction.VerifyInputs kullanılır.
+func (tx *Transaction) MultiSigProgress(inIdx int) (signed, required int, ok bool) {
+	if inIdx < 0 || inIdx >= len(tx.Inputs) {
+		return 0, 0, false
+	}
+	data, err := tx.Inputs[inIdx].ScriptSig.PushedData()
+	if err != nil || len(data) == 0 {
+		return 0, 0, false
+	}
+	redeemScript := Script(data[len(data)-1])
+	required, pubKeys, ok := redeemScript.MultiSig()
+	if !ok {
+		return 0, 0, false
+	}
+	_, signed = tx.matchMultiSigSignatures(inIdx, data[:len(data)-1], redeemScript, pubKeys)
+	return signed, required, true
+}
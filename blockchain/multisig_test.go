@@ -0,0 +1,127 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// 2-of-2 çoklu imzalı bir çıktı sırayla iki cüzdan tarafından imzalanır: ilk imzadan sonra girdi 1 of 2
+// durumundadır ve geçersizdir, ikinci imzadan sonra 2 of 2 olur ve işlem zincire girer. Bozuk, başka
+// bir işleme ait, betikte olmayan bir anahtara ait ya da tekrarlanan imzalar sayılmaz.
+func TestMultiSigPartialSigning(t *testing.T) {
+	a, b, to := wallet.MakeWallet(), wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, a)
+
+	redeem, err := MultiSigScript(2, [][]byte{a.PublicKey, b.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fund, err := NewTransaction(a, ScriptAddress(redeem), 30, 0, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := CoinbaseTx(string(a.Address()), "", chain.Engine.Reward(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{cb, fund}); err != nil {
+		t.Fatal(err)
+	}
+	prevTXs := map[string]Transaction{hex.EncodeToString(fund.ID): *fund}
+
+	spend, err := NewMultiSigTransaction(redeem, string(to.Address()), 20, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spend.Inputs) != 1 {
+		t.Fatalf("multisig spend has %d inputs, want 1", len(spend.Inputs))
+	}
+
+	progress := func(t *testing.T, tx *Transaction, want int) {
+		t.Helper()
+		signed, required, ok := tx.MultiSigProgress(0)
+		if !ok || signed != want || required != 2 {
+			t.Fatalf("MultiSigProgress = %d of %d, %v, want %d of 2", signed, required, ok, want)
+		}
+		if err := tx.VerifyInputs(prevTXs); (err == nil) != (want == 2) {
+			t.Fatalf("VerifyInputs with %d of 2 signatures = %v", want, err)
+		}
+	}
+
+	progress(t, spend, 0)
+
+	if err := spend.Sign(b.PrivateKey, prevTXs); err != nil {
+		t.Fatal(err)
+	}
+	progress(t, spend, 1)
+
+	// Sayılmaması gereken imzalar
+	data, err := spend.Inputs[0].ScriptSig.PushedData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := data[0]
+	tampered := append([]byte(nil), signature...)
+	tampered[0] ^= 0x01
+	outsider, err := spend.signInput(to.PrivateKey, 0, redeem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := *spend
+	other.Outputs = []TxOutput{*NewTXOutput(29, string(to.Address()))}
+	otherTx, err := other.signInput(a.PrivateKey, 0, redeem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, sigs := range map[string][][]byte{
+		"repeated signature":                    {signature, signature},
+		"tampered signature":                    {signature, tampered},
+		"signature of a key outside the script": {signature, outsider},
+		"signature of another transaction":      {signature, otherTx},
+	} {
+		bad := *spend
+		bad.Inputs = []TxInput{spend.Inputs[0]}
+		bad.Inputs[0].ScriptSig = ScriptHashSigScript(sigs, redeem)
+		t.Run(name, func(t *testing.T) { progress(t, &bad, 1) })
+	}
+
+	if err := spend.Sign(a.PrivateKey, prevTXs); err != nil {
+		t.Fatal(err)
+	}
+	progress(t, spend, 2)
+
+	// İmzalar anahtar sırasına yerleştirilir; tamamlanmış girdiye yeniden imza eklenmez
+	signed := spend.Inputs[0].ScriptSig
+	if err := spend.Sign(b.PrivateKey, prevTXs); err != nil {
+		t.Fatal(err)
+	}
+	if string(spend.Inputs[0].ScriptSig) != string(signed) {
+		t.Fatal("signing a complete input changed it")
+	}
+
+	if signed, required, ok := fund.MultiSigProgress(0); ok {
+		t.Fatalf("MultiSigProgress of a P2PKH input = %d of %d", signed, required)
+	}
+	if _, _, ok := spend.MultiSigProgress(1); ok {
+		t.Fatal("MultiSigProgress of a missing input")
+	}
+
+	spend.ID = spend.Hash() // ID imzalar dahil edilerek hesaplanır
+	cb, err = CoinbaseTx(string(a.Address()), "", chain.Engine.Reward(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{cb, spend}); err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := u.FindScriptUTXO(PayToScriptHashScript(redeem.Hash160()))
+	if err != nil || len(outputs) != 1 || outputs[0].Value != 10 {
+		t.Fatalf("multisig outputs after the spend = %v, %v, want the change of 10", outputs, err)
+	}
+	if err := chain.VerifyTransaction(spend); !errors.Is(err, ErrMissingInput) {
+		t.Fatalf("spending the multisig output again = %v, want ErrMissingInput", err)
+	}
+}
@@ -0,0 +1,216 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Params, bir zincirin düğüm başlarken seçilen kurallarını ve ağ profilini tutar.
+// Aynı ağdaki tüm düğümler ve cüzdanlar aynı parametreleri kullanmalıdır; farklı Name, Magic ve
+// AddressVersion değerleri sayesinde birden fazla zincir yan yana birbirine karışmadan çalışabilir.
+type Params struct {
+	Name        string   // ağın adı, zincirle birlikte kaydedilir
+	Consensus   string   // kullanılacak konsensüs uygulaması, bkz. NewEngine
+	Authorities []string // PoA: genesisteki yetkili cüzdan adresleri
+
+	GenesisData string // genesis coinbase işleminin verisi, her ağın genesis hash'i farklı olur
+
+	InitialSubsidy  int // genesis bloğundan itibaren her bloğun coinbase ödülü
+	HalvingInterval int // ödülün kaç blokta bir yarıya indiği, 0 ise hiç yarılanmaz
+
+	InitialDifficulty int // genesis bloğu için hedefin başındaki sıfır bit sayısı
+	MinDifficulty     int // hedefin alabileceği en kolay değer (powLimit) için sıfır bit sayısı
+
+	RetargetWindow      int   // zorluğun kaç blokta bir yeniden hesaplanacağı, 1 ise hiç değişmez
+	TargetBlockInterval int64 // saniye cinsinden hedeflenen blok süresi
+
+	MedianTimeBlocks   int   // zaman damgasının geçmesi gereken medyan için bakılan önceki blok sayısı
+	MaxFutureBlockTime int64 // saniye cinsinden, zaman damgasının düğüm saatinin ne kadar ilerisinde olabileceği
+
+	AddressVersion       byte     // cüzdan adreslerinin ilk (sürüm) baytı
+	ScriptAddressVersion byte     // betik (P2SH, ör. çoklu imza) adreslerinin sürüm baytı
+	Magic                uint32   // ağ mesajlarının başına yazılan ağ kimliği
+	DefaultPort          int      // NODE_ID verilmediğinde kullanılan port
+	Seeds                []string // düğümün başlarken bağlandığı bilinen düğümler, ilki merkez düğümdür
+	DataDir              string   // zincir ve cüzdan dosyalarının tutulduğu dizin
+}
+
+// MainNetParams, iş kanıtı (proof of work) kullanan ana ağın parametreleridir.
+var MainNetParams = Params{
+	Name:      "main",
+	Consensus: ProofOfWorkName,
+
+	GenesisData: "First Transaction from Genesis",
+
+	InitialSubsidy:  20,
+	HalvingInterval: 210,
+
+	InitialDifficulty: 18,
+	MinDifficulty:     8,
+
+	RetargetWindow:      10,
+	TargetBlockInterval: 10,
+
+	MedianTimeBlocks:   11,
+	MaxFutureBlockTime: 2 * 60 * 60,
+
+	AddressVersion:       0x00,
+	ScriptAddressVersion: 0x05,
+	Magic:                0xf9beb4d9,
+	DefaultPort:          3000,
+	Seeds:                []string{"localhost:3000"},
+	DataDir:              "./tmp",
+}
+
+// TestNetParams, ana ağdan ayrı tutulan ve daha kolay zorlukla çalışan test ağının parametreleridir.
+var TestNetParams = Params{
+	Name:      "test",
+	Consensus: ProofOfWorkName,
+
+	GenesisData: "First Transaction from Testnet Genesis",
+
+	InitialSubsidy:  20,
+	HalvingInterval: 210,
+
+	InitialDifficulty: 14,
+	MinDifficulty:     8,
+
+	RetargetWindow:      10,
+	TargetBlockInterval: 10,
+
+	MedianTimeBlocks:   11,
+	MaxFutureBlockTime: 2 * 60 * 60,
+
+	AddressVersion:       0x6f,
+	ScriptAddressVersion: 0x3a,
+	Magic:                0x0b110907,
+	DefaultPort:          13000,
+	Seeds:                []string{"localhost:13000"},
+	DataDir:              "./tmp/test",
+}
+
+// RegTestParams, yerel denemeler için neredeyse anında blok üreten regresyon testi ağının parametreleridir.
+var RegTestParams = Params{
+	Name:      "regtest",
+	Consensus: ProofOfWorkName,
+
+	GenesisData: "First Transaction from Regtest Genesis",
+
+	InitialSubsidy:  50,
+	HalvingInterval: 150,
+
+	InitialDifficulty: 1,
+	MinDifficulty:     1,
+
+	RetargetWindow:      10,
+	TargetBlockInterval: 10,
+
+	MedianTimeBlocks:   11,
+	MaxFutureBlockTime: 2 * 60 * 60,
+
+	AddressVersion:       0xc4,
+	ScriptAddressVersion: 0x7a,
+	Magic:                0xfabfb5da,
+	DefaultPort:          23000,
+	Seeds:                []string{"localhost:23000"},
+	DataDir:              "./tmp/regtest",
+}
+
+// DefaultParams, ağ seçilmediğinde kullanılan zincir parametreleridir.
+var DefaultParams = MainNetParams
+
+// ActiveParams, InitBlockChain ve ContinueBlockChain tarafından kullanılan zincir parametreleridir.
+// Düğüm başlamadan önce SetActiveParams ile değiştirilerek başka bir ağ ya da konsensüs seçilebilir.
+var ActiveParams = DefaultParams
+
+// SetActiveParams fonksiyonu, params'ı etkin zincir parametreleri yapar ve cüzdan ile betik adreslerinin
+// sürüm baytları ile dosya dizinini bu ağa göre ayarlar.
+func SetActiveParams(params Params) {
+	ActiveParams = params
+	wallet.AddressVersion = params.AddressVersion
+	wallet.ScriptAddressVersion = params.ScriptAddressVersion
+	wallet.DataDir = params.DataDir
+}
+
+// ParamsByName fonksiyonu, adı verilen hazır ağ profilini (main, test, regtest) döndürür.
+func ParamsByName(name string) (Params, error) {
+	switch name {
+	case "", MainNetParams.Name:
+		return MainNetParams, nil
+	case TestNetParams.Name:
+		return TestNetParams, nil
+	case RegTestParams.Name:
+		return RegTestParams, nil
+	default:
+		return Params{}, fmt.Errorf("unknown network %q", name)
+	}
+}
+
+// LoadParams fonksiyonu, zincir parametrelerini JSON dosyasından okur.
+// Dosyadaki "Base" alanı üzerine yazılacak hazır ağ profilini seçer (verilmezse main);
+// dosyada verilmeyen alanlar bu profildeki değerlerini korur.
+func LoadParams(path string) (Params, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Params{}, err
+	}
+
+	var base struct{ Base string }
+	if err := json.Unmarshal(data, &base); err != nil {
+		return Params{}, err
+	}
+	params, err := ParamsByName(base.Base)
+	if err != nil {
+		return Params{}, err
+	}
+	params.Seeds = append([]string(nil), params.Seeds...) // hazır profilin listesi paylaşılmasın
+
+	if err := json.Unmarshal(data, &params); err != nil {
+		return Params{}, err
+	}
+	return params, params.Validate()
+}
+
+// Validate fonksiyonu, parametrelerin kullanılabilir olduğunu kontrol eder.
+func (p Params) Validate() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("chain params: Name is empty")
+	case p.MinDifficulty < 1 || p.MinDifficulty > 255:
+		return fmt.Errorf("chain params: MinDifficulty %d out of range", p.MinDifficulty)
+	case p.InitialDifficulty < p.MinDifficulty || p.InitialDifficulty > 255:
+		return fmt.Errorf("chain params: InitialDifficulty %d out of range", p.InitialDifficulty)
+	case p.RetargetWindow < 1:
+		return fmt.Errorf("chain params: RetargetWindow %d out of range", p.RetargetWindow)
+	case p.TargetBlockInterval < 1:
+		return fmt.Errorf("chain params: TargetBlockInterval %d out of range", p.TargetBlockInterval)
+	case p.MedianTimeBlocks < 1:
+		return fmt.Errorf("chain params: MedianTimeBlocks %d out of range", p.MedianTimeBlocks)
+	case p.MaxFutureBlockTime < 0:
+		return fmt.Errorf("chain params: MaxFutureBlockTime %d out of range", p.MaxFutureBlockTime)
+	case p.AddressVersion == p.ScriptAddressVersion:
+		return fmt.Errorf("chain params: AddressVersion and ScriptAddressVersion are both %#x", p.AddressVersion)
+	case p.Magic == 0:
+		return fmt.Errorf("chain params: Magic is zero")
+	case len(p.Seeds) == 0:
+		return fmt.Errorf("chain params: no Seeds")
+	case p.DataDir == "":
+		return fmt.Errorf("chain params: DataDir is empty")
+	}
+	return nil
+}
+
+// PowLimit fonksiyonu, zorluğun düşebileceği en kolay hedefi döndürür.
+func (p Params) PowLimit() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(256-p.MinDifficulty))
+}
+
+// InitialBits fonksiyonu, genesis bloğunda kullanılan sıkıştırılmış hedefi döndürür.
+func (p Params) InitialBits() uint32 {
+	target := new(big.Int).Lsh(big.NewInt(1), uint(256-p.InitialDifficulty))
+	return BigToCompact(target)
+}
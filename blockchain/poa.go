@@ -0,0 +1,444 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// ProofOfAuthorityName, yetki kanıtı konsensüsünün Params.Consensus içindeki adıdır.
+const ProofOfAuthorityName = "poa"
+
+// Yetki kanıtı (PoA) kuralları:
+// Bloklar nonce aranarak değil, yetkili cüzdanlardan birinin imzasıyla mühürlenir. Yetkililer
+// Params.Authorities ile genesiste belirlenir ve sırayla blok imzalar: h yüksekliğindeki bloğu,
+// ebeveyndeki yetkili listesinin (açık anahtar hash'ine göre sıralı) h % N. elemanı imzalar.
+// Her blok bir oy taşıyabilir; bir adayın eklenmesi ya da çıkarılması için yetkililerin
+// yarısından fazlası aynı yönde oy vermelidir. Dal seçiminde her blok 1 ağırlık taşır.
+
+// Signer, blokları bir cüzdanla imzalayan konsensüs uygulamalarının (PoA) ek arayüzüdür.
+type Signer interface {
+	// Authorize, bu düğümün blokları imzalayacağı cüzdanı belirler.
+	Authorize(w *wallet.Wallet)
+
+	// InTurn, sıradaki bloğu imzalama sırasının bu düğümün cüzdanında olup olmadığını döndürür.
+	InTurn(chain *BlockChain) bool
+
+	// Propose, bu düğümün imzaladığı bloklarda verilecek oyu ekler.
+	Propose(p Proposal) error
+}
+
+// Proposal, bir adresin yetkili listesine eklenmesi (Add) ya da çıkarılması için verilen oydur.
+type Proposal struct {
+	Address string
+	Add     bool
+}
+
+// authoritySnapshot, bir bloktan sonraki yetkili listesini ve sonuçlanmamış oyları tutar.
+type authoritySnapshot struct {
+	Authorities [][]byte                   // yetkililerin açık anahtar hash'leri, sıralı
+	Tally       map[string]map[string]bool // oy anahtarı -> oy veren yetkililer
+}
+
+// ProofOfAuthorityEngine, yetki kanıtı konsensüsünü uygular.
+type ProofOfAuthorityEngine struct {
+	genesis [][]byte // genesisteki yetkililerin açık anahtar hash'leri
+	params  Params
+
+	mu        sync.Mutex
+	signer    *wallet.Wallet
+	proposals map[string]bool // aday açık anahtar hash'i (hex) -> eklenmesi mi
+	snapshots map[string]*authoritySnapshot
+}
+
+// NewProofOfAuthority fonksiyonu, params.Authorities içindeki yetkili adresleriyle bir PoA uygulaması oluşturur.
+func NewProofOfAuthority(params Params) (*ProofOfAuthorityEngine, error) {
+	authorities := params.Authorities
+	if len(authorities) == 0 {
+		return nil, errors.New("proof of authority needs at least one authority")
+	}
+
+	var hashes [][]byte
+	for _, address := range authorities {
+		hash, err := addressPubKeyHash(address)
+		if err != nil {
+			return nil, err
+		}
+		hashes = insertAuthority(hashes, hash)
+	}
+
+	return &ProofOfAuthorityEngine{
+		genesis:   hashes,
+		params:    params,
+		proposals: make(map[string]bool),
+		snapshots: make(map[string]*authoritySnapshot),
+	}, nil
+}
+
+// addressPubKeyHash fonksiyonu, adresi doğrular ve içindeki açık anahtar hash'ini döndürür.
+func addressPubKeyHash(address string) ([]byte, error) {
+	version, pubKeyHash, ok := wallet.DecodeAddress(address)
+	if !ok || version != wallet.AddressVersion { // yetkililer betik adresi olamaz
+		return nil, fmt.Errorf("invalid authority address %q", address)
+	}
+	return pubKeyHash, nil
+}
+
+// signerKey fonksiyonu, cüzdanın açık anahtarını blokta taşınan sabit uzunluklu (X||Y, 64 bayt) hale getirir.
+func signerKey(w *wallet.Wallet) []byte {
+	key := make([]byte, 64)
+	w.PrivateKey.PublicKey.X.FillBytes(key[:32])
+	w.PrivateKey.PublicKey.Y.FillBytes(key[32:])
+	return key
+}
+
+// signerPubKeyHash fonksiyonu, blokta taşınan imzacı anahtarından cüzdan adresindeki açık anahtar hash'ini hesaplar.
+// Cüzdanlar açık anahtarı sabit uzunlukta (X||Y, 64 bayt) hash'lediğinden anahtar olduğu gibi hash'lenir;
+// baştaki sıfır baytlar atılırsa koordinatı sıfırla başlayan anahtarların hash'i adrestekiyle eşleşmez.
+func signerPubKeyHash(key []byte) []byte {
+	return wallet.PublicKeyHash(key)
+}
+
+func insertAuthority(list [][]byte, hash []byte) [][]byte {
+	for _, a := range list {
+		if bytes.Equal(a, hash) {
+			return list
+		}
+	}
+	list = append(list, hash)
+	sort.Slice(list, func(i, j int) bool { return bytes.Compare(list[i], list[j]) < 0 })
+	return list
+}
+
+func removeAuthority(list [][]byte, hash []byte) [][]byte {
+	var result [][]byte
+	for _, a := range list {
+		if !bytes.Equal(a, hash) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func (s *authoritySnapshot) isAuthority(hash []byte) bool {
+	for _, a := range s.Authorities {
+		if bytes.Equal(a, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// inTurn fonksiyonu, height yüksekliğindeki bloğu imzalama sırasının hangi yetkilide olduğunu döndürür.
+func (s *authoritySnapshot) inTurn(height int) []byte {
+	return s.Authorities[height%len(s.Authorities)]
+}
+
+func voteKey(candidate []byte, add bool) string {
+	return fmt.Sprintf("%t:%x", add, candidate)
+}
+
+// checkVote fonksiyonu, oyun mevcut yetkili listesine göre anlamlı olduğunu kontrol eder:
+// yetkili olan eklenemez, yetkili olmayan çıkarılamaz.
+func (s *authoritySnapshot) checkVote(block *Block) error {
+	if len(block.Vote) == 0 {
+		return nil
+	}
+	if s.isAuthority(block.Vote) == block.VoteAdd {
+		return fmt.Errorf("%w: %x", ErrBadVote, block.Vote)
+	}
+	return nil
+}
+
+// apply fonksiyonu, bloğun oyunu uygulayarak yeni bir snapshot döndürür.
+func (s *authoritySnapshot) apply(block *Block) *authoritySnapshot {
+	next := &authoritySnapshot{
+		Authorities: append([][]byte{}, s.Authorities...),
+		Tally:       make(map[string]map[string]bool),
+	}
+	for key, voters := range s.Tally {
+		next.Tally[key] = make(map[string]bool)
+		for voter := range voters {
+			next.Tally[key][voter] = true
+		}
+	}
+
+	if len(block.Vote) == 0 || next.checkVote(block) != nil {
+		return next
+	}
+
+	key := voteKey(block.Vote, block.VoteAdd)
+	if next.Tally[key] == nil {
+		next.Tally[key] = make(map[string]bool)
+	}
+	next.Tally[key][hex.EncodeToString(signerPubKeyHash(block.Signer))] = true
+
+	if len(next.Tally[key]) <= len(next.Authorities)/2 {
+		return next
+	}
+
+	// Oylama sonuçlandı: aday eklenir ya da çıkarılır ve adayla ilgili bekleyen oylar silinir
+	delete(next.Tally, voteKey(block.Vote, true))
+	delete(next.Tally, voteKey(block.Vote, false))
+
+	if block.VoteAdd {
+		next.Authorities = insertAuthority(next.Authorities, block.Vote)
+	} else {
+		next.Authorities = removeAuthority(next.Authorities, block.Vote)
+		removed := hex.EncodeToString(block.Vote)
+		for key := range next.Tally {
+			delete(next.Tally[key], removed)
+			if len(next.Tally[key]) == 0 {
+				delete(next.Tally, key)
+			}
+		}
+	}
+
+	return next
+}
+
+// snapshot fonksiyonu, verilen bloktan sonraki yetkili listesini hesaplar. Hesaplanan snapshot'lar
+// blok hash'ine göre saklanır; eksik olanlar en yakın bilinen atadan itibaren ileri doğru oluşturulur.
+func (e *ProofOfAuthorityEngine) snapshot(chain *BlockChain, block *Block) (*authoritySnapshot, error) {
+	var pending []*Block
+	var snap *authoritySnapshot
+
+	for {
+		if s, ok := e.snapshots[hex.EncodeToString(block.Hash)]; ok {
+			snap = s
+			break
+		}
+		if len(block.PrevHash) == 0 {
+			snap = &authoritySnapshot{e.genesis, make(map[string]map[string]bool)}
+			e.snapshots[hex.EncodeToString(block.Hash)] = snap
+			break
+		}
+
+		pending = append(pending, block)
+		parent, err := chain.GetBlock(block.PrevHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %x", ErrUnknownParent, block.PrevHash)
+		}
+		block = &parent
+	}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		snap = snap.apply(pending[i])
+		e.snapshots[hex.EncodeToString(pending[i].Hash)] = snap
+	}
+
+	return snap, nil
+}
+
+// sealHash fonksiyonu, PoA bloğunun imzalanan hash'ini hesaplar: blok başlığı ile imzacı ve oy
+// alanları birlikte hash'lenir. İmza hash'e dahil değildir.
+func sealHash(block *Block) []byte {
+	header := block.Header()
+	data := bytes.Join(
+		[][]byte{
+			header.Serialize(),
+			ToHex(int64(block.Height)),
+			block.Signer,
+			block.Vote,
+			ToHex(int64(boolToInt(block.VoteAdd))),
+		},
+		[]byte{},
+	)
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (e *ProofOfAuthorityEngine) Name() string {
+	return ProofOfAuthorityName
+}
+
+// Authorize fonksiyonu, bu düğümün blokları imzalayacağı cüzdanı belirler.
+func (e *ProofOfAuthorityEngine) Authorize(w *wallet.Wallet) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.signer = w
+}
+
+// Propose fonksiyonu, bu düğümün imzalayacağı bloklara eklenecek oyu kaydeder.
+func (e *ProofOfAuthorityEngine) Propose(p Proposal) error {
+	hash, err := addressPubKeyHash(p.Address)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.proposals[hex.EncodeToString(hash)] = p.Add
+	return nil
+}
+
+// InTurn fonksiyonu, zincir ucunun üzerine eklenecek bloğu imzalama sırasının bu düğümde olup olmadığını döndürür.
+func (e *ProofOfAuthorityEngine) InTurn(chain *BlockChain) bool {
+	tip, err := chain.GetBlock(chain.LastHash())
+	if err != nil {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.signer == nil {
+		return false
+	}
+	snap, err := e.snapshot(chain, &tip)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(snap.inTurn(tip.Height+1), signerPubKeyHash(signerKey(e.signer)))
+}
+
+// Prepare fonksiyonu, bloğa imzacının anahtarını ve varsa bekleyen bir oyu ekler. İmzalama sırası
+// bu düğümde değilse ErrNotInTurn döner.
+func (e *ProofOfAuthorityEngine) Prepare(chain *BlockChain, block, parent *Block) error {
+	if parent == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.signer == nil {
+		return errors.New("proof of authority needs a signing wallet")
+	}
+	snap, err := e.snapshot(chain, parent)
+	if err != nil {
+		return err
+	}
+
+	signer := signerPubKeyHash(signerKey(e.signer)) // VerifyHeader'ın bloktaki anahtardan hesapladığı hash
+	if !bytes.Equal(snap.inTurn(block.Height), signer) {
+		return fmt.Errorf("%w: height %d", ErrNotInTurn, block.Height)
+	}
+	block.Signer = signerKey(e.signer)
+
+	// Sonucu zaten gerçekleşmiş oylar atlanır, kalanlardan ilki (sıralı) bloğa eklenir
+	var candidates []string
+	for candidate := range e.proposals {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+	for _, candidate := range candidates {
+		hash, _ := hex.DecodeString(candidate)
+		add := e.proposals[candidate]
+		if snap.isAuthority(hash) == add {
+			continue
+		}
+		if snap.Tally[voteKey(hash, add)][hex.EncodeToString(signer)] {
+			continue
+		}
+		block.Vote = hash
+		block.VoteAdd = add
+		break
+	}
+
+	return nil
+}
+
+// Seal fonksiyonu, bloğun hash'ini hesaplar ve imzacının anahtarıyla imzalar.
+// Genesis bloğu imzalanmaz.
+func (e *ProofOfAuthorityEngine) Seal(ctx context.Context, block *Block) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	block.Hash = sealHash(block)
+	if len(block.PrevHash) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	signer := e.signer
+	e.mu.Unlock()
+	if signer == nil {
+		return errors.New("proof of authority needs a signing wallet")
+	}
+
+	signature, err := ecdsa.SignASN1(rand.Reader, &signer.PrivateKey, block.Hash)
+	if err != nil {
+		return err
+	}
+	block.Signature = signature
+	return nil
+}
+
+// VerifySeal fonksiyonu, bloğun hash'ini ve imzacının imzasını doğrular.
+func (e *ProofOfAuthorityEngine) VerifySeal(chain *BlockChain, block *Block) error {
+	if !bytes.Equal(sealHash(block), block.Hash) {
+		return fmt.Errorf("%w: %x", ErrBadBlockHash, block.Hash)
+	}
+	if len(block.PrevHash) == 0 {
+		return nil
+	}
+
+	if len(block.Signer) != 64 {
+		return fmt.Errorf("%w: %x", ErrBadBlockSignature, block.Hash)
+	}
+	pubKey := ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(block.Signer[:32]),
+		Y:     new(big.Int).SetBytes(block.Signer[32:]),
+	}
+	if !ecdsa.VerifyASN1(&pubKey, block.Hash, block.Signature) {
+		return fmt.Errorf("%w: %x", ErrBadBlockSignature, block.Hash)
+	}
+	return nil
+}
+
+// VerifyHeader fonksiyonu, imzacının ebeveyndeki yetkili listesinde olduğunu, sıranın onda
+// olduğunu ve bloğun oyunun geçerli olduğunu kontrol eder.
+func (e *ProofOfAuthorityEngine) VerifyHeader(chain *BlockChain, block, parent *Block) error {
+	if block.Bits != 0 {
+		return fmt.Errorf("%w: got %08x, want 0", ErrBadDifficulty, block.Bits)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	snap, err := e.snapshot(chain, parent)
+	if err != nil {
+		return err
+	}
+
+	signer := signerPubKeyHash(block.Signer)
+	if !snap.isAuthority(signer) {
+		return fmt.Errorf("%w: %x", ErrUnauthorizedSigner, signer)
+	}
+	if !bytes.Equal(snap.inTurn(block.Height), signer) {
+		return fmt.Errorf("%w: height %d", ErrNotInTurn, block.Height)
+	}
+
+	return snap.checkVote(block)
+}
+
+// Work fonksiyonu, her bloğa eşit ağırlık verir; böylece en uzun dal seçilir.
+func (e *ProofOfAuthorityEngine) Work(block *Block) *big.Int {
+	return big.NewInt(1)
+}
+
+// Reward fonksiyonu, iş kanıtındaki ile aynı yarılanan blok ödülünü döndürür.
+func (e *ProofOfAuthorityEngine) Reward(height int) int {
+	return e.params.BlockSubsidy(height)
+}
@@ -0,0 +1,29 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Açık anahtarı sıfır baytla başlayan bir cüzdanın imzaladığı blok da cüzdanın adresine eşlenmelidir;
+// aksi halde VerifyHeader yetkilinin kendi bloklarını reddeder.
+func TestSignerPubKeyHashLeadingZero(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		w := wallet.MakeWallet()
+		if w.PublicKey[0] != 0 && w.PublicKey[32] != 0 {
+			continue
+		}
+
+		hash, err := addressPubKeyHash(string(w.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := signerPubKeyHash(signerKey(w)); !bytes.Equal(got, hash) {
+			t.Fatalf("signer hash %x, address hash %x", got, hash)
+		}
+		return
+	}
+	t.Fatal("no key with a leading zero byte generated")
+}
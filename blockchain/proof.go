@@ -2,12 +2,18 @@ package blockchain
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 //Bu kodumuzda proof-work kullanıcaz işkanıtı olarak makınelerın sıfre cozmelerını ıstıycez
@@ -22,8 +28,7 @@ import (
 
 // Gereksinimler:
 // ilk birkaç bayt 0 içermelidir  (bu zorluk derecesıdır)
-
-const Difficulty = 18
+// Zorluk artık sabit değildir, blogun Bits alanında saklanır (bkz. difficulty.go)
 
 type ProofOfWork struct {
 	Block  *Block
@@ -31,26 +36,17 @@ type ProofOfWork struct {
 }
 
 func NewProof(b *Block) *ProofOfWork {
-	target := big.NewInt(1)                  //	buyuk bır ınt degerı olusturduk ve 1 degerı ıel baslattık
-	target.Lsh(target, uint(256-Difficulty)) //LSH sol a kaydırma yapar target degerını uint(256-Difficulty) bu kadar sola kaydırır
-	pow := &ProofOfWork{b, target}           //iş kanıtı oluşturuldu b blogu ıcın bu target olmalıdır
+	target := CompactToBig(b.Bits) //blogun Bits alanındakı sıkıstırılmıs hedefı buyuk ınte cevırıyoruz
+	pow := &ProofOfWork{b, target} //iş kanıtı oluşturuldu b blogu ıcın bu target olmalıdır
 	return pow
 }
 
-// InitData, Proof of Work için gerekli olan verileri hazırlar ve birleştirir.
-// Hazırlanan veriler bloğun önceki hash'i, verisi, nonce değeri ve zorluk seviyesini içerir.
+// InitData, Proof of Work için hash'lenecek veriyi hazırlar: verilen nonce değeriyle
+// bloğun sabit uzunluktaki başlığı (bkz. BlockHeader).
 func (pow *ProofOfWork) InitData(nonce int) []byte {
-	// Verileri birleştirmek için bytes.Join kullanılır.
-	data := bytes.Join(
-		[][]byte{
-			pow.Block.PrevHash,           // Bloğun önceki hash'i
-			pow.Block.HashTransactions(), // Bloğun verisi
-			ToHex(int64(nonce)),          // Nonce değeri (int64 türünde hexadecimal'e dönüştürülür)
-			ToHex(int64(Difficulty)),     // Zorluk seviyesi (int64 türünde hexadecimal'e dönüştürülür)
-		},
-		[]byte{}, // Ayraç olarak kullanılacak boş bir byte slice'ı
-	)
-	return data
+	header := pow.Block.Header()
+	header.Nonce = uint64(nonce)
+	return header.Serialize()
 }
 
 // ToHex, bir int64 değerini hexadecimal formatına dönüştürür ve byte dizisi olarak döndürür.
@@ -66,33 +62,103 @@ func ToHex(num int64) []byte {
 	return buf.Bytes() // Byte dizisi olarak buf içeriği döndürülür.
 }
 
-func (pow *ProofOfWork) Run() (int, []byte) {
-	var intHash big.Int //buyuk bır ınt olusturulur
-	var hash [32]byte   //hashlenmıs sıfreyı tutucaz burada
+// Mine fonksiyonu, hedefi sağlayan nonce değerini workers adet goroutine ile arar. Nonce uzayı
+// goroutine'ler arasında bölünür: i. goroutine i, i+workers, i+2*workers ... değerlerini dener.
+// ctx iptal edilirse arama durur ve ctx.Err() döner. onHashRate nil değilse saniyede bir,
+// saniye başına denenen hash sayısı ile çağrılır.
+func (pow *ProofOfWork) Mine(ctx context.Context, workers int, onHashRate func(hashesPerSecond float64)) (int, []byte, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	mineCtx, cancel := context.WithCancel(ctx) //nonce bulununca diger goroutine'ler de durdurulur
+	defer cancel()
+
+	type result struct {
+		nonce int
+		hash  []byte
+	}
+	found := make(chan result, 1)
+	var hashes uint64 //tum goroutine'lerin denedigi hash sayısı
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			var intHash big.Int //buyuk bır ınt olusturulur
+
+			data := pow.InitData(start) //baslık bir kez olusturulur, dongude yalnızca nonce baytları degisir
+			nonceBytes := data[headerNonceOffset : headerNonceOffset+8]
+
+			for i, nonce := 0, start; nonce >= 0 && nonce < math.MaxInt64; i, nonce = i+1, nonce+workers { //nerdeyse sonsuz bır dongu
+				if i%1024 == 0 && mineCtx.Err() != nil { //iptal her adımda degil, ara ara kontrol edilir
+					return
+				}
+
+				binary.BigEndian.PutUint64(nonceBytes, uint64(nonce))
+				hash := sha256.Sum256(data) //nonce degerı ıle baslıgı sıfrelıyoruz
+				atomic.AddUint64(&hashes, 1)
+				intHash.SetBytes(hash[:]) //hası buyuk ınte donduruyoruz kıyas yapabılmek ıcın
+
+				if intHash.Cmp(pow.Target) == -1 { //eger targeten kucuk ıse dogru bulundu
+					select {
+					case found <- result{nonce, hash[:]}:
+					default: //baska bir goroutine once buldu
+					}
+					cancel()
+					return
+				}
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	nonce := 0                  //aranıcak degere
-	for nonce < math.MaxInt64 { //nerdeyse sonsuz bır dongu olusturuyoruz
-		data := pow.InitData(nonce) //nonce degerı ıle datayı olusturuyoruz
-		hash = sha256.Sum256(data)  //datayı sıfrelıuoz
-		fmt.Printf("\r%x", hash)    //hası ekrana bastırıyoruz
-		intHash.SetBytes(hash[:])   //hası buyuk ınte donduruyoruz kıyas yapabılmek ıcın
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	last := time.Now()
+	var lastHashes uint64
 
-		if intHash.Cmp(pow.Target) == -1 { //eger targeten kucuk ıse dogru bulundu has cıkabılrısın
-			break
-		} else { //yanlıs bulundu ıse nonce 1 artır ve devam et
-			nonce++
+	for {
+		select {
+		case <-done:
+			select {
+			case r := <-found:
+				return r.nonce, r.hash, nil
+			default:
+			}
+			if err := ctx.Err(); err != nil { //madencilik dışarıdan iptal edildi
+				return 0, nil, err
+			}
+			return 0, nil, errors.New("nonce space exhausted")
+		case now := <-ticker.C:
+			if onHashRate != nil {
+				total := atomic.LoadUint64(&hashes)
+				onHashRate(float64(total-lastHashes) / now.Sub(last).Seconds())
+				lastHashes, last = total, now
+			}
 		}
 	}
-	fmt.Println()
-	return nonce, hash[:] //nonce ve hash deerlerını gerıye dondur
+}
+
+// Hash fonksiyonu, blogun kendi nonce degeri ile hesaplanan hasını döndürür.
+func (pow *ProofOfWork) Hash() []byte {
+	hash := sha256.Sum256(pow.InitData(pow.Block.Nonce)) //blogun noncenı vererek data olusturulur ve haslenır
+	return hash[:]
 }
 
 func (pow *ProofOfWork) Validate() bool {
-	var intHash big.Int                   //buyuk bır ınt tanımlanır
-	data := pow.InitData(pow.Block.Nonce) //blogun noncenı vererek data olusturulur
+	if pow.Target.Sign() <= 0 { //hedef gecersızse blok reddedılır
+		return false
+	}
 
-	hash := sha256.Sum256(data) //olusturulan datayı haslerız
-	intHash.SetBytes(hash[:])   //heshlenmıs datayı int turune donustururu
+	var intHash big.Int          //buyuk bır ınt tanımlanır
+	intHash.SetBytes(pow.Hash()) //heshlenmıs datayı int turune donustururu
 
 	return intHash.Cmp(pow.Target) == -1
 	/*
@@ -101,3 +167,92 @@ func (pow *ProofOfWork) Validate() bool {
 		1: intHash, pow.Target'ten büyüktür.
 	*/
 }
+
+// ProofOfWorkName, iş kanıtı konsensüsünün Params.Consensus içindeki adıdır.
+const ProofOfWorkName = "pow"
+
+// ProofOfWorkEngine, iş kanıtını Engine arayüzü üzerinden sunar: bloklar nonce aranarak mühürlenir,
+// zorluk CalcNextBits ile ayarlanır ve en fazla işi biriktirmiş dal seçilir.
+type ProofOfWorkEngine struct {
+	Workers    int                           // nonce arayan goroutine sayısı, 0 ise işlemci sayısı kadar
+	OnHashRate func(hashesPerSecond float64) // madencilik sırasında saniyede bir çağrılır, nil olabilir
+
+	params Params
+}
+
+// NewProofOfWorkEngine fonksiyonu, tüm işlemci çekirdeklerini kullanan ve ödülü params'taki plana
+// göre hesaplayan bir iş kanıtı uygulaması oluşturur.
+func NewProofOfWorkEngine(params Params) *ProofOfWorkEngine {
+	return &ProofOfWorkEngine{Workers: runtime.NumCPU(), params: params}
+}
+
+func (*ProofOfWorkEngine) Name() string {
+	return ProofOfWorkName
+}
+
+// Prepare fonksiyonu, bloğun Bits alanını ebeveynine göre beklenen zorlukla doldurur.
+func (e *ProofOfWorkEngine) Prepare(chain *BlockChain, block, parent *Block) error {
+	if parent == nil {
+		block.Bits = e.params.InitialBits()
+		return nil
+	}
+
+	bits, err := chain.CalcNextBits(parent)
+	if err != nil {
+		return err
+	}
+	block.Bits = bits
+	return nil
+}
+
+// Seal fonksiyonu, bloğun hedefini sağlayan nonce değerini Workers adet goroutine ile arar.
+// ctx iptal edilirse blok mühürlenmeden ctx.Err() döner.
+func (e *ProofOfWorkEngine) Seal(ctx context.Context, block *Block) error {
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	pow := NewProof(block)
+	nonce, hash, err := pow.Mine(ctx, workers, e.OnHashRate)
+	if err != nil {
+		return err
+	}
+	block.Hash = hash
+	block.Nonce = nonce
+	return nil
+}
+
+// VerifySeal fonksiyonu, bloğun hash'ini yeniden hesaplar ve hedefin altında olduğunu kontrol eder.
+func (e *ProofOfWorkEngine) VerifySeal(chain *BlockChain, block *Block) error {
+	pow := NewProof(block)
+	if !bytes.Equal(pow.Hash(), block.Hash) {
+		return fmt.Errorf("%w: %x", ErrBadBlockHash, block.Hash)
+	}
+	if pow.Target.Cmp(e.params.PowLimit()) > 0 || !pow.Validate() { //hedef agın ızın verdıgınden kolaysa blok reddedılır
+		return fmt.Errorf("%w: %x", ErrBadProofOfWork, block.Hash)
+	}
+	return nil
+}
+
+// VerifyHeader fonksiyonu, bloğun zorluğunun zincirin beklediği değer olduğunu kontrol eder.
+func (*ProofOfWorkEngine) VerifyHeader(chain *BlockChain, block, parent *Block) error {
+	expected, err := chain.CalcNextBits(parent)
+	if err != nil {
+		return err
+	}
+	if block.Bits != expected {
+		return fmt.Errorf("%w: got %08x, want %08x", ErrBadDifficulty, block.Bits, expected)
+	}
+	return nil
+}
+
+// Work fonksiyonu, bloğun hedefine karşılık gelen iş miktarını döndürür.
+func (*ProofOfWorkEngine) Work(block *Block) *big.Int {
+	return CalcWork(blockBits(block))
+}
+
+// Reward fonksiyonu, yüksekliğe göre yarılanan blok ödülünü döndürür.
+func (e *ProofOfWorkEngine) Reward(height int) int {
+	return e.params.BlockSubsidy(height)
+}
@@ -0,0 +1,268 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Her bloğun genesisten itibaren biriktirdiği toplam iş (Engine.Work) blokla birlikte saklanır.
+// Zincir ucu her zaman en fazla birikmiş işe sahip dal üzerindedir.
+
+// storeBlock fonksiyonu, bloğu ve birikmiş işini kaydeder. Blok, mevcut zincir ucundan
+// daha fazla iş biriktirmişse true döner.
+func (chain *BlockChain) storeBlock(block *Block) (bool, error) {
+	better := false
+
+	err := chain.Store.Update(func(txn StoreTxn) error {
+		parentWork, err := txn.ChainWork(block.PrevHash)
+		if err != nil {
+			return fmt.Errorf("önceki blok %x bulunamadı: %w", block.PrevHash, err)
+		}
+		work := new(big.Int).Add(parentWork, chain.Engine.Work(block))
+
+		if err := txn.PutBlock(block, work); err != nil {
+			return err
+		}
+
+		lastHash, err := txn.Tip()
+		if err != nil {
+			return err
+		}
+		tipWork, err := txn.ChainWork(lastHash)
+		if err != nil {
+			return err
+		}
+
+		better = work.Cmp(tipWork) > 0
+		return nil
+	})
+
+	return better, err
+}
+
+// chainWorkKey, her bloğun birikmiş işinin saklandığını belirten kayıttır. Anahtar, store.go içindeki
+// bölüm öneklerinden biriyle başlamamalıdır.
+const chainWorkKey = "chainwork"
+
+// MigrateChainWork fonksiyonu, birikmiş iş tutulmadan önce oluşturulmuş veritabanlarında birikmiş işi
+// olmayan her blok için (yan dallardakiler dahil) genesisten itibaren birikmiş işi hesaplayıp kaydeder;
+// aksi halde bu blokların üzerine eklenen ilk blok ErrBlockNotFound ile reddedilir. Taşıma bir kez
+// yapılır ve chainWorkKey ile işaretlenir. Yeni kayıt yazıldıysa true döner.
+func (chain *BlockChain) MigrateChainWork() (bool, error) {
+	type pending struct {
+		hash, prevHash []byte
+		height         int
+		work           *big.Int
+	}
+	var blocks []pending
+	done := false
+
+	err := chain.Store.View(func(txn StoreTxn) error {
+		value, err := txn.Meta(chainWorkKey)
+		if err != nil || value != nil {
+			done = value != nil
+			return err
+		}
+
+		return txn.ForEachBlock(func(block *Block) error {
+			if _, err := txn.ChainWork(block.Hash); err == nil {
+				return nil
+			} else if !errors.Is(err, ErrBlockNotFound) {
+				return err
+			}
+			blocks = append(blocks, pending{block.Hash, block.PrevHash, block.Height, chain.Engine.Work(block)})
+			return nil
+		})
+	})
+	if err != nil || done {
+		return false, err
+	}
+
+	// Ebeveynin işi çocuğundan önce hesaplanmalıdır; yükseklik sırası bunu sağlar
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].height < blocks[j].height })
+
+	err = chain.Store.Update(func(txn StoreTxn) error {
+		for _, b := range blocks {
+			work := b.work
+			if len(b.prevHash) != 0 {
+				parentWork, err := txn.ChainWork(b.prevHash)
+				if err != nil {
+					return fmt.Errorf("önceki blok %x bulunamadı: %w", b.prevHash, err)
+				}
+				work = new(big.Int).Add(parentWork, b.work)
+			}
+
+			block, err := txn.Block(b.hash)
+			if err != nil {
+				return err
+			}
+			if err := txn.PutBlock(block, work); err != nil {
+				return err
+			}
+		}
+		return txn.SetMeta(chainWorkKey, []byte{1})
+	})
+	return err == nil && len(blocks) > 0, err
+}
+
+// setTip fonksiyonu, zincir ucunu verilen bloğa taşır. update, aynı veritabanı işlemi içinde
+// zincir ucuna bağlı indeksleri güncellemek için kullanılır.
+func (chain *BlockChain) setTip(hash []byte, update func(txn StoreTxn) error) error {
+	err := chain.Store.Update(func(txn StoreTxn) error {
+		if err := update(txn); err != nil {
+			return err
+		}
+		return txn.SetTip(hash)
+	})
+	if err != nil {
+		return err
+	}
+	chain.tipLock.Lock()
+	chain.lastHash = hash
+	chain.tipLock.Unlock()
+	return nil
+}
+
+// findFork fonksiyonu, mevcut zincir ucu ile yeni uç arasındaki ortak atayı bulur.
+// detach mevcut uçtan geriye doğru, attach ise ortak atadan yeni uca doğru sıralıdır.
+func (chain *BlockChain) findFork(oldTip, newTip *Block) (detach, attach []*Block, err error) {
+	parent := func(b *Block) (*Block, error) {
+		block, err := chain.GetBlock(b.PrevHash)
+		return &block, err
+	}
+
+	for oldTip.Height > newTip.Height {
+		detach = append(detach, oldTip)
+		if oldTip, err = parent(oldTip); err != nil {
+			return nil, nil, err
+		}
+	}
+	for newTip.Height > oldTip.Height {
+		attach = append([]*Block{newTip}, attach...)
+		if newTip, err = parent(newTip); err != nil {
+			return nil, nil, err
+		}
+	}
+	for !bytes.Equal(oldTip.Hash, newTip.Hash) {
+		detach = append(detach, oldTip)
+		attach = append([]*Block{newTip}, attach...)
+		if oldTip, err = parent(oldTip); err != nil {
+			return nil, nil, err
+		}
+		if newTip, err = parent(newTip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return detach, attach, nil
+}
+
+// reorganize fonksiyonu, zincir ucunu newTip'e taşır. Ortak ataya kadar olan bloklar
+// UTXO setinden geri alınır, ardından yeni dalın blokları işlemleri doğrulanarak sırayla uygulanır.
+// Yeni daldaki bir blok geçersiz çıkarsa eski dal geri yüklenir ve geçersiz bloklar silinir.
+func (chain *BlockChain) reorganize(newTip *Block) error {
+	oldTip, err := chain.GetBlock(chain.LastHash())
+	if err != nil {
+		return err
+	}
+
+	detach, attach, err := chain.findFork(&oldTip, newTip)
+	if err != nil {
+		return err
+	}
+
+	if len(detach) > 0 {
+		fmt.Printf("Zincir yeniden düzenleniyor: %d blok çıkarılıyor, %d blok ekleniyor\n", len(detach), len(attach))
+	}
+
+	if err := chain.disconnectBlocks(detach); err != nil {
+		return err
+	}
+
+	for i, block := range attach {
+		if err := chain.checkBlockTransactions(block); err != nil {
+			// Yeni dalın bağlanan kısmı geri alınır ve eski dal tekrar bağlanır
+			connected := make([]*Block, i)
+			for j := range connected {
+				connected[j] = attach[i-1-j]
+			}
+			if rerr := chain.disconnectBlocks(connected); rerr != nil {
+				return rerr
+			}
+			restore := make([]*Block, len(detach))
+			for j := range restore {
+				restore[j] = detach[len(detach)-1-j]
+			}
+			if rerr := chain.connectBlocks(restore); rerr != nil {
+				return rerr
+			}
+			if rerr := chain.deleteBlocks(attach[i:]); rerr != nil {
+				return rerr
+			}
+			return err
+		}
+
+		if err := chain.connectBlocks([]*Block{block}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// connectBlocks fonksiyonu, blokları verilen sırayla UTXO setine uygular, işlemlerini ve
+// yüksekliklerini indeksler ve zincir ucunu ilerletir. Her blok tek bir veritabanı işlemiyle
+// bağlanır; bağlama yarıda kalırsa UTXO seti, geri alma kaydı, indeksler ve zincir ucu birlikte
+// bloktan önceki halinde kalır.
+func (chain *BlockChain) connectBlocks(blocks []*Block) error {
+	for _, block := range blocks {
+		err := chain.setTip(block.Hash, func(txn StoreTxn) error {
+			if err := updateUTXO(txn, block); err != nil {
+				return err
+			}
+			if err := indexTransactions(txn, block); err != nil {
+				return err
+			}
+			return indexHeight(txn, block)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// disconnectBlocks fonksiyonu, blokları verilen sırayla (uçtan geriye doğru) UTXO setinden,
+// işlem indeksinden ve yükseklik indeksinden geri alır. Her blok tek bir veritabanı işlemiyle çıkarılır.
+func (chain *BlockChain) disconnectBlocks(blocks []*Block) error {
+	for _, block := range blocks {
+		err := chain.setTip(block.PrevHash, func(txn StoreTxn) error {
+			if err := revertUTXO(txn, block); err != nil {
+				return err
+			}
+			if err := unindexTransactions(txn, block); err != nil {
+				return err
+			}
+			return unindexHeight(txn, block)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteBlocks fonksiyonu, geçersiz olduğu anlaşılan blokları ve birikmiş iş kayıtlarını siler.
+func (chain *BlockChain) deleteBlocks(blocks []*Block) error {
+	return chain.Store.Update(func(txn StoreTxn) error {
+		for _, block := range blocks {
+			if err := txn.DeleteBlock(block.Hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,231 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// mineCoinbase fonksiyonu, zincir ucunun üzerine yalnızca w'ye ödeme yapan coinbase işlemini içeren bir blok kazar.
+func mineCoinbase(t *testing.T, chain *BlockChain, w *wallet.Wallet) *Block {
+	t.Helper()
+
+	height, err := chain.GetBestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(height+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := chain.MineBlock([]*Transaction{cb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return block
+}
+
+// Birikmiş işi kaydedilmemiş bloklar (birikmiş iş tutulmadan önceki veritabanları) taşımayla aynı
+// değerleri almalı ve zincir yeniden blok kabul etmelidir.
+func TestMigrateChainWork(t *testing.T) {
+	w := wallet.MakeWallet()
+	chain, _ := newTestChain(t, w)
+	for i := 0; i < 3; i++ {
+		mineCoinbase(t, chain, w)
+	}
+
+	want := make(map[string]*big.Int)
+	err := chain.Store.View(func(txn StoreTxn) error {
+		return txn.ForEachBlock(func(block *Block) error {
+			work, err := txn.ChainWork(block.Hash)
+			want[string(block.Hash)] = work
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kv := chain.Store.(kvStore).kv.(*memoryBackend)
+	for key := range kv.data {
+		if strings.HasPrefix(key, string(chainWorkPrefix)) || key == chainWorkKey {
+			delete(kv.data, key)
+		}
+	}
+
+	if migrated, err := chain.MigrateChainWork(); err != nil || !migrated {
+		t.Fatalf("MigrateChainWork = %v, %v", migrated, err)
+	}
+	err = chain.Store.View(func(txn StoreTxn) error {
+		for hash, work := range want {
+			got, err := txn.ChainWork([]byte(hash))
+			if err != nil {
+				return err
+			}
+			if got.Cmp(work) != 0 {
+				t.Errorf("chain work of %x = %v, want %v", hash, got, work)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migrated, err := chain.MigrateChainWork(); err != nil || migrated {
+		t.Fatalf("second MigrateChainWork = %v, %v", migrated, err)
+	}
+
+	block := mineCoinbase(t, chain, w)
+	if !bytes.Equal(chain.LastHash(), block.Hash) {
+		t.Fatalf("tip %x, want %x", chain.LastHash(), block.Hash)
+	}
+}
+
+// addForkBlock fonksiyonu, parent'ın üzerine (zincir ucu olması gerekmeden) txs işlemlerini içeren bir
+// blok kazıp AddBlock ile zincire verir.
+func addForkBlock(t *testing.T, chain *BlockChain, parent *Block, to *wallet.Wallet, data string, txs ...*Transaction) *Block {
+	t.Helper()
+
+	cb, err := CoinbaseTx(string(to.Address()), data, chain.Engine.Reward(parent.Height+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := CreateBlock(context.Background(), chain, chain.Engine, append([]*Transaction{cb}, txs...), parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.AddBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	return block
+}
+
+// utxoSnapshot fonksiyonu, depodaki UTXO setini "txid:vout" anahtarlarıyla döndürür.
+func utxoSnapshot(t *testing.T, chain *BlockChain) map[string]string {
+	t.Helper()
+
+	set := make(map[string]string)
+	err := chain.Store.View(func(txn StoreTxn) error {
+		return txn.ForEachOutput(func(txID []byte, out int, output TxOutput) error {
+			set[fmt.Sprintf("%x:%d", txID, out)] = fmt.Sprintf("%d/%x", output.Value, output.ScriptPubKey)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+// checkChainState fonksiyonu, zincir ucunun tip olduğunu, yükseklik indeksinin ana dalı izlediğini ve
+// blok blok güncellenen UTXO setinin zincirden yeniden oluşturulan setle aynı olduğunu doğrular.
+func checkChainState(t *testing.T, chain *BlockChain, u *UTXOSet, branch []*Block) {
+	t.Helper()
+
+	tip := branch[len(branch)-1]
+	if !bytes.Equal(chain.LastHash(), tip.Hash) {
+		t.Fatalf("tip %x, want %x", chain.LastHash(), tip.Hash)
+	}
+	if best, err := chain.GetBestHeight(); err != nil || best != tip.Height {
+		t.Fatalf("best height %d, %v, want %d", best, err, tip.Height)
+	}
+	for _, block := range branch {
+		hash, err := chain.GetBlockHashByHeight(block.Height)
+		if err != nil || !bytes.Equal(hash, block.Hash) {
+			t.Fatalf("height %d: %x, %v, want %x", block.Height, hash, err, block.Hash)
+		}
+		if _, err := chain.FindTransaction(block.Transactions[0].ID); err != nil {
+			t.Fatalf("coinbase of block %d: %v", block.Height, err)
+		}
+	}
+	if _, err := chain.GetBlockHashByHeight(tip.Height + 1); err == nil {
+		t.Fatalf("height %d is still indexed", tip.Height+1)
+	}
+
+	incremental := utxoSnapshot(t, chain)
+	if err := u.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt := utxoSnapshot(t, chain); !reflect.DeepEqual(incremental, rebuilt) {
+		t.Fatalf("UTXO set %v, rebuilt from the chain %v", incremental, rebuilt)
+	}
+}
+
+// Daha fazla iş biriktiren dal zincir ucunu taşır: eski dalın işlemleri, yükseklikleri ve çıktıları
+// geri alınır, yeni dalınkiler uygulanır. Geçersiz işlem içeren ağır bir dal ise reddedilir ve zincir
+// önceki dalda bozulmadan kalır.
+func TestReorganize(t *testing.T) {
+	w, other := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send, err := NewTransaction(w, string(other.Address()), 3, 0, 0, u) // w'nin tek çıktısı olan genesis ödülünü harcar
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := addForkBlock(t, chain, &genesis, w, "a1")
+	a2 := addForkBlock(t, chain, a1, w, "a2", send)
+
+	// Eşit iş zincir ucunu değiştirmez
+	b1 := addForkBlock(t, chain, &genesis, other, "b1")
+	b2 := addForkBlock(t, chain, b1, other, "b2")
+	checkChainState(t, chain, u, []*Block{&genesis, a1, a2})
+
+	b3 := addForkBlock(t, chain, b2, other, "b3")
+	checkChainState(t, chain, u, []*Block{&genesis, b1, b2, b3})
+	for _, tx := range []*Transaction{send, a1.Transactions[0]} {
+		if _, err := chain.FindTransaction(tx.ID); err == nil {
+			t.Fatalf("transaction %x of the detached branch is still indexed", tx.ID)
+		}
+	}
+
+	// Eski dal uzayınca zincir geri döner; A dalındaki işlem yeniden bağlanır
+	a3 := addForkBlock(t, chain, a2, w, "a3")
+	a4 := addForkBlock(t, chain, a3, w, "a4")
+	checkChainState(t, chain, u, []*Block{&genesis, a1, a2, a3, a4})
+	if _, err := chain.FindTransaction(send.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// B dalında var olmayan bir çıktıyı harcayan blok, dal ağırlaşınca reddedilir
+	before := utxoSnapshot(t, chain)
+	spend, err := NewTransaction(w, string(other.Address()), 1, 0, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b4 := addForkBlock(t, chain, b3, other, "b4", spend)
+	cb, err := CoinbaseTx(string(other.Address()), "b5", chain.Engine.Reward(b4.Height+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b5, err := CreateBlock(context.Background(), chain, chain.Engine, []*Transaction{cb}, b4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.AddBlock(b5); !errors.Is(err, ErrMissingInput) {
+		t.Fatalf("AddBlock = %v, want ErrMissingInput", err)
+	}
+	if after := utxoSnapshot(t, chain); !reflect.DeepEqual(before, after) {
+		t.Fatalf("UTXO set changed by the rejected branch: %v, want %v", after, before)
+	}
+	checkChainState(t, chain, u, []*Block{&genesis, a1, a2, a3, a4})
+	for _, block := range []*Block{b4, b5} {
+		if _, err := chain.GetBlock(block.Hash); err == nil {
+			t.Fatalf("invalid block %d was kept", block.Height)
+		}
+	}
+	if _, err := chain.GetBlock(b3.Hash); err != nil {
+		t.Fatalf("valid block %d was deleted: %v", b3.Height, err)
+	}
+}
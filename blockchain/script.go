@@ -0,0 +1,325 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Çıktılar bir kilitleme betiği (ScriptPubKey), girdiler ise bir kilit açma betiği (ScriptSig) taşır.
+// Betik, yığın (stack) tabanlı küçük bir dildir: her bayt bir işlem kodudur (opcode) ya da bir veri
+// ekleme işleminin parçasıdır. Bir girdi, önce kendi ScriptSig'i sonra harcadığı çıktının ScriptPubKey'i
+// aynı yığın üzerinde çalıştırıldığında yığının tepesinde doğru (true) bir değer kalıyorsa geçerlidir
+// (bkz. interpreter.go). Standart betik şablonları standard.go içindedir.
+
+// Script, bir betiğin bayt kodudur.
+type Script []byte
+
+// İşlem kodları. Değerler Bitcoin betik diliyle aynıdır.
+const (
+	OpFalse     byte = 0x00 // boş bir değer ekler (OP_0)
+	OpPushData1 byte = 0x4c // sonraki 1 bayt veri uzunluğudur
+	OpPushData2 byte = 0x4d // sonraki 2 bayt (little-endian) veri uzunluğudur
+	OpPushData4 byte = 0x4e // sonraki 4 bayt (little-endian) veri uzunluğudur
+	Op1Negate   byte = 0x4f // -1 ekler
+	OpTrue      byte = 0x51 // 1 ekler (OP_1), OP_2 ... OP_16 sırayla devam eder
+	Op16        byte = 0x60
+
+	OpNop    byte = 0x61
+	OpIf     byte = 0x63
+	OpNotIf  byte = 0x64
+	OpElse   byte = 0x67
+	OpEndIf  byte = 0x68
+	OpVerify byte = 0x69
+	OpReturn byte = 0x6a
+
+	Op2Drop byte = 0x6d
+	OpDepth byte = 0x74
+	OpDrop  byte = 0x75
+	OpDup   byte = 0x76
+	OpOver  byte = 0x78
+	OpSwap  byte = 0x7c
+	OpSize  byte = 0x82
+
+	OpEqual       byte = 0x87
+	OpEqualVerify byte = 0x88
+
+	OpNot            byte = 0x91
+	OpAdd            byte = 0x93
+	OpSub            byte = 0x94
+	OpNumEqual       byte = 0x9c
+	OpNumEqualVerify byte = 0x9d
+	OpLessThan       byte = 0x9f
+	OpGreaterThan    byte = 0xa0
+	OpSha256         byte = 0xa8
+	OpHash160        byte = 0xa9
+	OpCheckSig       byte = 0xac
+	OpCheckSigVerify byte = 0xad
+
+	OpCheckMultiSig       byte = 0xae
+	OpCheckMultiSigVerify byte = 0xaf
+
+	OpCheckLockTimeVerify byte = 0xb1
+	OpCheckSequenceVerify byte = 0xb2
+)
+
+// opcodeNames, betiklerin okunabilir gösteriminde kullanılan işlem kodu adlarıdır.
+var opcodeNames = map[byte]string{
+	OpFalse: "OP_0", OpPushData1: "OP_PUSHDATA1", OpPushData2: "OP_PUSHDATA2", OpPushData4: "OP_PUSHDATA4",
+	Op1Negate: "OP_1NEGATE",
+	OpNop:     "OP_NOP", OpIf: "OP_IF", OpNotIf: "OP_NOTIF", OpElse: "OP_ELSE", OpEndIf: "OP_ENDIF",
+	OpVerify: "OP_VERIFY", OpReturn: "OP_RETURN",
+	Op2Drop: "OP_2DROP", OpDepth: "OP_DEPTH", OpDrop: "OP_DROP", OpDup: "OP_DUP", OpOver: "OP_OVER",
+	OpSwap: "OP_SWAP", OpSize: "OP_SIZE",
+	OpEqual: "OP_EQUAL", OpEqualVerify: "OP_EQUALVERIFY",
+	OpNot: "OP_NOT", OpAdd: "OP_ADD", OpSub: "OP_SUB", OpNumEqual: "OP_NUMEQUAL", OpNumEqualVerify: "OP_NUMEQUALVERIFY",
+	OpLessThan: "OP_LESSTHAN", OpGreaterThan: "OP_GREATERTHAN",
+	OpSha256: "OP_SHA256", OpHash160: "OP_HASH160", OpCheckSig: "OP_CHECKSIG", OpCheckSigVerify: "OP_CHECKSIGVERIFY",
+	OpCheckMultiSig: "OP_CHECKMULTISIG", OpCheckMultiSigVerify: "OP_CHECKMULTISIGVERIFY",
+	OpCheckLockTimeVerify: "OP_CHECKLOCKTIMEVERIFY", OpCheckSequenceVerify: "OP_CHECKSEQUENCEVERIFY",
+}
+
+func init() {
+	for op := OpTrue; op <= Op16; op++ {
+		opcodeNames[op] = fmt.Sprintf("OP_%d", op-OpTrue+1)
+	}
+}
+
+// ErrMalformedScript, ayrıştırılamayan (ör. veri eklemesi yarıda kalan) betikler için döner.
+var ErrMalformedScript = errors.New("script is malformed")
+
+// instruction, ayrıştırılmış tek bir betik adımıdır. Veri ekleyen adımlarda data eklenecek veridir.
+type instruction struct {
+	op   byte
+	data []byte
+}
+
+// isPush fonksiyonu, adımın yığına yalnızca veri ya da sayı ekleyip eklemediğini döndürür.
+func (in instruction) isPush() bool {
+	return in.op <= Op16 && in.op != 0x50 // 0x50 (OP_RESERVED) bir veri eklemesi değildir
+}
+
+// parse fonksiyonu, betiği adımlarına ayırır.
+func (s Script) parse() ([]instruction, error) {
+	var instructions []instruction
+
+	for i := 0; i < len(s); {
+		op := s[i]
+		i++
+
+		var size int
+		switch {
+		case op > OpFalse && op < OpPushData1:
+			size = int(op)
+		case op == OpPushData1:
+			if i+1 > len(s) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA1", ErrMalformedScript)
+			}
+			size = int(s[i])
+			i++
+		case op == OpPushData2:
+			if i+2 > len(s) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA2", ErrMalformedScript)
+			}
+			size = int(binary.LittleEndian.Uint16(s[i:]))
+			i += 2
+		case op == OpPushData4:
+			if i+4 > len(s) {
+				return nil, fmt.Errorf("%w: truncated OP_PUSHDATA4", ErrMalformedScript)
+			}
+			size = int(binary.LittleEndian.Uint32(s[i:]))
+			i += 4
+		default:
+			instructions = append(instructions, instruction{op: op})
+			continue
+		}
+
+		if size < 0 || size > len(s)-i {
+			return nil, fmt.Errorf("%w: push of %d bytes exceeds script", ErrMalformedScript, size)
+		}
+		instructions = append(instructions, instruction{op: op, data: append([]byte{}, s[i:i+size]...)})
+		i += size
+	}
+
+	return instructions, nil
+}
+
+// IsPushOnly fonksiyonu, betiğin yalnızca veri ve sayı eklemelerinden oluşup oluşmadığını döndürür.
+// Kilit açma betiklerinin bu koşulu sağlaması gerekir.
+func (s Script) IsPushOnly() bool {
+	instructions, err := s.parse()
+	if err != nil {
+		return false
+	}
+	for _, in := range instructions {
+		if !in.isPush() {
+			return false
+		}
+	}
+	return true
+}
+
+// PushedData fonksiyonu, yalnızca veri eklemelerinden oluşan bir betiğin eklediği verileri sırasıyla döndürür.
+// OP_0 boş bir veri olarak döner; başka bir işlem kodu içeren betikler için hata döner.
+func (s Script) PushedData() ([][]byte, error) {
+	instructions, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var data [][]byte
+	for _, in := range instructions {
+		if in.op >= Op1Negate {
+			return nil, fmt.Errorf("%w: %s is not a data push", ErrMalformedScript, opcodeName(in.op))
+		}
+		data = append(data, in.data)
+	}
+	return data, nil
+}
+
+// String fonksiyonu, betiği okunabilir biçimde döndürür: "OP_DUP OP_HASH160 <hex> OP_EQUALVERIFY OP_CHECKSIG".
+// Eklenen veriler hex olarak yazılır.
+func (s Script) String() string {
+	instructions, err := s.parse()
+	if err != nil {
+		return fmt.Sprintf("[malformed %x]", []byte(s))
+	}
+
+	var parts []string
+	for _, in := range instructions {
+		if in.op > OpFalse && in.op <= OpPushData4 {
+			parts = append(parts, hex.EncodeToString(in.data))
+		} else {
+			parts = append(parts, opcodeName(in.op))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// opcodeName fonksiyonu, işlem kodunun adını döndürür; bilinmeyen kodlar için "OP_UNKNOWN_<hex>" döner.
+func opcodeName(op byte) string {
+	if name, ok := opcodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("OP_UNKNOWN_%02x", op)
+}
+
+// ScriptBuilder, işlem kodlarını ve verileri en kısa kodlamalarıyla ekleyerek bir betik oluşturur.
+type ScriptBuilder struct {
+	script Script
+}
+
+// NewScriptBuilder fonksiyonu, boş bir betik oluşturucu döndürür.
+func NewScriptBuilder() *ScriptBuilder {
+	return &ScriptBuilder{}
+}
+
+// AddOp fonksiyonu, betiğe bir işlem kodu ekler.
+func (b *ScriptBuilder) AddOp(ops ...byte) *ScriptBuilder {
+	b.script = append(b.script, ops...)
+	return b
+}
+
+// AddData fonksiyonu, verilen veriyi yığına ekleyecek en kısa veri eklemesini betiğe yazar.
+// Boş veri OP_0 olarak yazılır. Tek baytlık veriler de sayı koduna (OP_1 ...) çevrilmeden eklenir.
+func (b *ScriptBuilder) AddData(data []byte) *ScriptBuilder {
+	switch n := len(data); {
+	case n == 0:
+		b.script = append(b.script, OpFalse)
+		return b
+	case n < int(OpPushData1):
+		b.script = append(b.script, byte(n))
+	case n <= 0xff:
+		b.script = append(b.script, OpPushData1, byte(n))
+	case n <= 0xffff:
+		b.script = append(b.script, OpPushData2, 0, 0)
+		binary.LittleEndian.PutUint16(b.script[len(b.script)-2:], uint16(n))
+	default:
+		b.script = append(b.script, OpPushData4, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b.script[len(b.script)-4:], uint32(n))
+	}
+	b.script = append(b.script, data...)
+	return b
+}
+
+// AddInt64 fonksiyonu, bir sayıyı betiğe ekler. 0, -1 ve 1-16 arası sayılar tek işlem koduyla yazılır.
+func (b *ScriptBuilder) AddInt64(n int64) *ScriptBuilder {
+	switch {
+	case n == 0:
+		return b.AddOp(OpFalse)
+	case n == -1:
+		return b.AddOp(Op1Negate)
+	case n >= 1 && n <= 16:
+		return b.AddOp(OpTrue + byte(n-1))
+	}
+	return b.AddData(scriptNumBytes(n))
+}
+
+// Script fonksiyonu, oluşturulan betiği döndürür.
+func (b *ScriptBuilder) Script() Script {
+	return append(Script{}, b.script...)
+}
+
+// Betik sayıları yığında little-endian, işaret bitli büyüklük (sign-magnitude) biçiminde tutulur:
+// en yüksek baytın en üst biti işarettir. Sıfır boş dizidir.
+
+// scriptNumBytes fonksiyonu, sayının yığındaki en kısa gösterimini döndürür.
+func scriptNumBytes(n int64) []byte {
+	if n == 0 {
+		return nil
+	}
+
+	negative := n < 0
+	abs := uint64(n)
+	if negative {
+		abs = uint64(-n)
+	}
+
+	var result []byte
+	for abs > 0 {
+		result = append(result, byte(abs&0xff))
+		abs >>= 8
+	}
+
+	// En yüksek baytın işaret biti doluysa işaret için bir bayt daha eklenir
+	if result[len(result)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		result = append(result, extra)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+
+	return result
+}
+
+// scriptNum fonksiyonu, yığındaki bir değeri sayıya çevirir. Değer maxLen bayttan uzunsa ya da
+// en kısa biçimde yazılmamışsa hata döner.
+func scriptNum(data []byte, maxLen int) (int64, error) {
+	if len(data) > maxLen {
+		return 0, fmt.Errorf("%w: %d bytes, limit %d", ErrBadNumber, len(data), maxLen)
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	// Son bayt yalnızca işaret biti taşıyorsa ve bir önceki baytın işaret biti boşsa sayı en kısa biçimde değildir
+	if data[len(data)-1]&0x7f == 0 && (len(data) == 1 || data[len(data)-2]&0x80 == 0) {
+		return 0, fmt.Errorf("%w: non-minimal encoding %x", ErrBadNumber, data)
+	}
+
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << uint(8*i)
+	}
+
+	if data[len(data)-1]&0x80 != 0 {
+		n &^= int64(0x80) << uint(8*(len(data)-1))
+		return -n, nil
+	}
+	return n, nil
+}
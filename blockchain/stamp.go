@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Zaman damgası (stamp), bir verinin (ör. bir dosyanın hash'i) belirli bir bloktan önce var olduğunu kanıtlar.
+// Veri, NullDataScript ile harcanamaz bir çıktıya yazılır; işlemin bloğun merkle köküne bağlandığı
+// Block.TransactionProof ile gösterilir. Kanıtı doğrulamak için yalnızca blok başlığı gerekir.
+
+// NewDataTransaction fonksiyonu, data verisini değeri 0 olan bir veri çıktısına yazan işlemi oluşturur.
+// fee w cüzdanının çıktılarından ödenir, kalan para üstü w'ye döner. İşlemin en az bir girdisi olması
+// gerektiğinden fee 0 olsa da cüzdanda harcanabilir bir çıktı bulunmalıdır.
+// Geçersiz girdiler için ErrDataTooLarge, ErrInvalidAmount ya da ErrInsufficientFunds'ı saran bir hata döner.
+func NewDataTransaction(w *wallet.Wallet, data []byte, fee int, UTXO *UTXOSet) (*Transaction, error) {
+	var inputs []TxInput
+	var outputs []TxOutput
+
+	script, err := NullDataScript(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if fee < 0 || fee > MaxMoney {
+		return nil, fmt.Errorf("%w: fee %d", ErrInvalidAmount, fee)
+	}
+
+	need := fee
+	if need == 0 {
+		need = 1 // coinbase olmayan her işlem en az bir çıktı harcar
+	}
+	pubKeyHash := wallet.PublicKeyHash(w.PublicKey)
+	acc, validOutputs, err := UTXO.FindSpendableOutputs(pubKeyHash, need)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc < need {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrInsufficientFunds, acc, need)
+	}
+
+	for txid, outs := range validOutputs {
+		txID, err := hex.DecodeString(txid)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, out := range outs {
+			input := TxInput{txID, out, nil, SequenceFinal} //kilit açma betiği imzalanırken yazılır
+			inputs = append(inputs, input)
+		}
+	}
+
+	outputs = append(outputs, TxOutput{0, script})
+
+	if change := acc - fee; change > 0 {
+		outputs = append(outputs, TxOutput{change, PayToPubKeyHashScript(pubKeyHash)})
+	}
+
+	tx := Transaction{TxVersion, nil, inputs, outputs, 0}
+	if err := UTXO.Blockchain.SignTransaction(&tx, w.PrivateKey); err != nil {
+		return nil, err
+	}
+	tx.ID = tx.Hash()
+
+	return &tx, nil
+}
+
+// FindDataTransaction fonksiyonu, ana zincirde data verisini bir veri çıktısında taşıyan ilk (en eski) işlemi
+// ve işlemi içeren bloğu döndürür. Zincir uçtan genesis'e kadar taranır. Böyle bir işlem yoksa
+// ErrTxNotFound'u saran bir hata döner.
+func (chain *BlockChain) FindDataTransaction(data []byte) (*Block, *Transaction, error) {
+	var foundBlock *Block
+	var foundTx *Transaction
+
+	iter := chain.Iterator()
+
+	for {
+		block, err := iter.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+
+	Transactions:
+		for _, tx := range block.Transactions {
+			for _, out := range tx.Outputs {
+				if d, ok := out.ScriptPubKey.NullData(); ok && bytes.Equal(d, data) {
+					foundBlock, foundTx = block, tx // daha eski bir blokta bulunursa o tercih edilir
+					break Transactions
+				}
+			}
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	if foundTx == nil {
+		return nil, nil, fmt.Errorf("%w: no data output holds %x", ErrTxNotFound, data)
+	}
+	return foundBlock, foundTx, nil
+}
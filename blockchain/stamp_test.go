@@ -0,0 +1,149 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Yalnızca en kısa kodlamayla yazılmış <OP_RETURN> <veri> betikleri veri çıktısı sayılır.
+func TestNullData(t *testing.T) {
+	for _, n := range []int{0, 1, 75, 76, MaxNullDataSize} {
+		data := bytes.Repeat([]byte{0xda}, n)
+		script, err := NullDataScript(data)
+		if err != nil {
+			t.Fatalf("NullDataScript(%d bytes): %v", n, err)
+		}
+		if got, ok := script.NullData(); !ok || !bytes.Equal(got, data) {
+			t.Fatalf("NullData of %d bytes = %x, %v", n, got, ok)
+		}
+		if !script.IsUnspendable() || script.Class() != NullDataClass {
+			t.Fatalf("%d byte data output is spendable or of class %s", n, script.Class())
+		}
+	}
+	if _, err := NullDataScript(make([]byte, MaxNullDataSize+1)); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("NullDataScript of %d bytes = %v, want ErrDataTooLarge", MaxNullDataSize+1, err)
+	}
+
+	for name, script := range map[string]Script{
+		"PUSHDATA1 for short data": {OpReturn, OpPushData1, 2, 'a', 'b'},
+		"number instead of data":   {OpReturn, OpTrue},
+		"two pushes":               {OpReturn, 1, 'a', 1, 'b'},
+		"opcode after data":        {OpReturn, 1, 'a', OpDrop},
+		"no OP_RETURN":             {1, 'a'},
+		"only OP_RETURN":           {OpReturn},
+		"truncated push":           {OpReturn, 2, 'a'},
+		"data too large":           append(Script{OpReturn, OpPushData1, MaxNullDataSize + 1}, make([]byte, MaxNullDataSize+1)...),
+	} {
+		if data, ok := script.NullData(); ok {
+			t.Errorf("%s: NullData = %x", name, data)
+		}
+	}
+}
+
+// Bir dosya hash'i damgalanır, kazılır ve verifystamp'in yaptığı gibi bulunup bloğun merkle köküne bağlanır.
+// Aynı veri tekrar damgalanırsa en eski damga döner; veri çıktıları UTXO setine girmez.
+func TestStamp(t *testing.T) {
+	w, poor := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+	hash := sha256.Sum256([]byte("hello world\n"))
+
+	mine := func(t *testing.T, txs ...*Transaction) *Block {
+		t.Helper()
+		height, err := chain.GetBestHeight()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(height+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := chain.MineBlock(append([]*Transaction{cb}, txs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return block
+	}
+
+	if _, err := NewDataTransaction(w, make([]byte, MaxNullDataSize+1), 1, u); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("stamp of too large data = %v, want ErrDataTooLarge", err)
+	}
+	if _, err := NewDataTransaction(w, hash[:], -1, u); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("stamp with a negative fee = %v, want ErrInvalidAmount", err)
+	}
+	if _, err := NewDataTransaction(poor, hash[:], 0, u); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("stamp from an empty wallet = %v, want ErrInsufficientFunds", err)
+	}
+	if _, _, err := chain.FindDataTransaction(hash[:]); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindDataTransaction before the stamp = %v, want ErrTxNotFound", err)
+	}
+
+	before, err := u.TotalValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp, err := NewDataTransaction(w, hash[:], 2, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := stamp.Outputs[0].ScriptPubKey.NullData(); !ok || !bytes.Equal(data, hash[:]) || stamp.Outputs[0].Value != 0 {
+		t.Fatalf("stamp output = %d %x, want a 0 value data output", stamp.Outputs[0].Value, stamp.Outputs[0].ScriptPubKey)
+	}
+	stamped := mine(t, stamp)
+
+	// Veri çıktısı UTXO setine girmez ve harcanamaz; ücreti madenci almadığından toplamdan düşer
+	after, err := u.TotalValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := before + chain.Engine.Reward(1) - 2; after != want {
+		t.Fatalf("total value after the stamp %d, want %d", after, want)
+	}
+	spend := &Transaction{TxVersion, nil, []TxInput{{stamp.ID, 0, nil, SequenceFinal}}, []TxOutput{*NewTXOutput(1, string(w.Address()))}, 0}
+	spend.ID = spend.Hash()
+	if err := chain.VerifyTransaction(spend); !errors.Is(err, ErrMissingInput) {
+		t.Fatalf("spending the data output = %v, want ErrMissingInput", err)
+	}
+
+	// Aynı veri sonraki bir blokta tekrar damgalanır; en eski damga döner
+	again, err := NewDataTransaction(w, hash[:], 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, again)
+
+	block, tx, err := chain.FindDataTransaction(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(block.Hash, stamped.Hash) || !bytes.Equal(tx.ID, stamp.ID) {
+		t.Fatalf("FindDataTransaction = block %x tx %x, want block %x tx %x", block.Hash, tx.ID, stamped.Hash, stamp.ID)
+	}
+
+	// verifystamp: kanıt, zincirdeki blok başlığının merkle köküne bağlanır
+	header, err := chain.GetBlockByHeight(block.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := block.TransactionProof(tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Index != 1 || len(block.Transactions) != 2 {
+		t.Fatalf("stamp at leaf %d of %d transactions, want 1 of 2", proof.Index, len(block.Transactions))
+	}
+	if !VerifyMerkleProof(header.MerkleRoot, tx.Serialize(), proof) {
+		t.Fatal("stamp proof does not verify against the block header")
+	}
+	if VerifyMerkleProof(header.MerkleRoot, again.Serialize(), proof) {
+		t.Fatal("stamp proof verifies another transaction")
+	}
+
+	if _, _, err := chain.FindDataTransaction([]byte(fmt.Sprintf("%x", hash))); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindDataTransaction of other data = %v, want ErrTxNotFound", err)
+	}
+}
@@ -0,0 +1,253 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Standart betik şablonları. Cüzdanlar ve komut satırı çıktıları bu şablonlarla kilitler;
+// yorumlayıcı ise her betiği şablondan bağımsız olarak çalıştırır.
+
+// ScriptClass, bir kilitleme betiğinin hangi standart şablona uyduğunu belirtir.
+type ScriptClass int
+
+const (
+	NonStandardClass ScriptClass = iota // bilinen bir şablona uymayan betik
+	PubKeyHashClass                     // P2PKH: public key hash'ine ödeme
+	ScriptHashClass                     // P2SH: betik hash'ine ödeme
+	MultiSigClass                       // M-of-N çoklu imza
+	AtomicSwapClass                     // hash zaman kilitli atomik takas sözleşmesi
+	NullDataClass                       // OP_RETURN ile harcanamaz kılınmış veri çıktısı
+)
+
+func (c ScriptClass) String() string {
+	switch c {
+	case PubKeyHashClass:
+		return "pubkeyhash"
+	case ScriptHashClass:
+		return "scripthash"
+	case MultiSigClass:
+		return "multisig"
+	case AtomicSwapClass:
+		return "atomicswap"
+	case NullDataClass:
+		return "nulldata"
+	default:
+		return "nonstandard"
+	}
+}
+
+// pubKeyHashLength, RIPEMD160(SHA256(pubKey)) hash'inin uzunluğudur.
+const pubKeyHashLength = 20
+
+// PayToPubKeyHashScript fonksiyonu, P2PKH kilitleme betiğini döndürür:
+//
+//	OP_DUP OP_HASH160 <pubKeyHash> OP_EQUALVERIFY OP_CHECKSIG
+//
+// Çıktı, hash'i pubKeyHash olan public key ile ve o anahtarın imzasıyla harcanabilir.
+func PayToPubKeyHashScript(pubKeyHash []byte) Script {
+	return NewScriptBuilder().
+		AddOp(OpDup, OpHash160).
+		AddData(pubKeyHash).
+		AddOp(OpEqualVerify, OpCheckSig).
+		Script()
+}
+
+// PubKeyHashSigScript fonksiyonu, P2PKH çıktısını harcayan kilit açma betiğini döndürür: <signature> <pubKey>
+func PubKeyHashSigScript(signature, pubKey []byte) Script {
+	return NewScriptBuilder().AddData(signature).AddData(pubKey).Script()
+}
+
+// payToPubKeyHashData fonksiyonu, betik P2PKH şablonuna uyuyorsa içindeki hash'i döndürür.
+// Şablonun hash uzunluğu kontrol edilmez; eski çıktılar bu sayede kayıpsız geri çevrilir.
+func (s Script) payToPubKeyHashData() ([]byte, bool) {
+	instructions, err := s.parse()
+	if err != nil || len(instructions) != 5 {
+		return nil, false
+	}
+	if instructions[0].op != OpDup || instructions[1].op != OpHash160 ||
+		instructions[3].op != OpEqualVerify || instructions[4].op != OpCheckSig {
+		return nil, false
+	}
+	if op := instructions[2].op; op > OpPushData4 {
+		return nil, false
+	}
+	if !bytes.Equal(s, PayToPubKeyHashScript(instructions[2].data)) { //yalnızca en kısa kodlama şablona uyar
+		return nil, false
+	}
+	return instructions[2].data, true
+}
+
+// PubKeyHash fonksiyonu, P2PKH betiğinin kilitlendiği public key hash'ini döndürür.
+// Betik P2PKH değilse ikinci değer false olur.
+func (s Script) PubKeyHash() ([]byte, bool) {
+	hash, ok := s.payToPubKeyHashData()
+	if !ok || len(hash) != pubKeyHashLength {
+		return nil, false
+	}
+	return hash, true
+}
+
+// PayToScriptHashScript fonksiyonu, P2SH kilitleme betiğini döndürür:
+//
+//	OP_HASH160 <scriptHash> OP_EQUAL
+//
+// Çıktı, hash'i scriptHash olan asıl betiği (redeem script) ve o betiğin koşullarını sağlayan
+// verileri sunan bir girdiyle harcanabilir (bkz. ExecuteScript).
+func PayToScriptHashScript(scriptHash []byte) Script {
+	return NewScriptBuilder().AddOp(OpHash160).AddData(scriptHash).AddOp(OpEqual).Script()
+}
+
+// ScriptHashSigScript fonksiyonu, P2SH çıktısını harcayan kilit açma betiğini döndürür: <data...> <redeemScript>
+func ScriptHashSigScript(data [][]byte, redeemScript Script) Script {
+	builder := NewScriptBuilder()
+	for _, d := range data {
+		builder.AddData(d)
+	}
+	return builder.AddData(redeemScript).Script()
+}
+
+// ScriptHash fonksiyonu, P2SH betiğinin kilitlendiği betik hash'ini döndürür.
+// Betik P2SH değilse ikinci değer false olur.
+func (s Script) ScriptHash() ([]byte, bool) {
+	if len(s) != 3+pubKeyHashLength || s[0] != OpHash160 || s[1] != pubKeyHashLength || s[len(s)-1] != OpEqual {
+		return nil, false
+	}
+	return s[2 : 2+pubKeyHashLength], true
+}
+
+// Hash160 fonksiyonu, betiğin P2SH çıktılarında kullanılan RIPEMD160(SHA256(betik)) hash'ini döndürür.
+func (s Script) Hash160() []byte {
+	return wallet.PublicKeyHash(s)
+}
+
+// MultiSigScript fonksiyonu, pubKeys anahtarlarından en az required tanesinin imzasını isteyen betiği döndürür:
+//
+//	<required> <pubKey1> ... <pubKeyN> <N> OP_CHECKMULTISIG
+//
+// İmzalar kilit açma betiğinde public key'lerle aynı sırada olmalıdır. Betik genellikle doğrudan
+// değil, PayToScriptHashScript ile bir adres üzerinden kullanılır.
+func MultiSigScript(required int, pubKeys [][]byte) (Script, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > MaxMultiSigKeys {
+		return nil, fmt.Errorf("%w: %d keys", ErrBadKeyCount, len(pubKeys))
+	}
+	if required < 1 || required > len(pubKeys) {
+		return nil, fmt.Errorf("%w: %d of %d signatures", ErrBadKeyCount, required, len(pubKeys))
+	}
+
+	builder := NewScriptBuilder().AddInt64(int64(required))
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	script := builder.AddInt64(int64(len(pubKeys))).AddOp(OpCheckMultiSig).Script()
+	if len(script) > MaxScriptElement {
+		return nil, fmt.Errorf("%w: %d bytes", ErrElementTooLarge, len(script)) // P2SH girdisine sığmaz
+	}
+	return script, nil
+}
+
+// MultiSig fonksiyonu, betik MultiSigScript şablonuna uyuyorsa gereken imza sayısını ve public key'leri döndürür.
+func (s Script) MultiSig() (required int, pubKeys [][]byte, ok bool) {
+	instructions, err := s.parse()
+	if err != nil || len(instructions) < 4 || instructions[len(instructions)-1].op != OpCheckMultiSig {
+		return 0, nil, false
+	}
+
+	smallInt := func(in instruction) int {
+		if in.op < OpTrue || in.op > Op16 {
+			return 0
+		}
+		return int(in.op-OpTrue) + 1
+	}
+	required = smallInt(instructions[0])
+	count := smallInt(instructions[len(instructions)-2])
+	if required == 0 || count < required || count != len(instructions)-3 {
+		return 0, nil, false
+	}
+
+	for _, in := range instructions[1 : len(instructions)-2] {
+		if !in.isPush() || in.op >= Op1Negate || len(in.data) == 0 {
+			return 0, nil, false
+		}
+		pubKeys = append(pubKeys, in.data)
+	}
+	return required, pubKeys, true
+}
+
+// MaxNullDataSize, bir veri çıktısına yazılabilecek en fazla bayt sayısıdır.
+const MaxNullDataSize = 80
+
+// NullDataScript fonksiyonu, data verisini taşıyan veri çıktısı betiğini döndürür:
+//
+//	OP_RETURN <data>
+//
+// OP_RETURN betiği her zaman başarısız kıldığından çıktı harcanamaz ve UTXO setine yazılmaz (bkz. IsUnspendable).
+// Veri MaxNullDataSize'dan uzunsa ErrDataTooLarge'ı saran bir hata döner.
+func NullDataScript(data []byte) (Script, error) {
+	if len(data) > MaxNullDataSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrDataTooLarge, len(data), MaxNullDataSize)
+	}
+	return NewScriptBuilder().AddOp(OpReturn).AddData(data).Script(), nil
+}
+
+// NullData fonksiyonu, betik NullDataScript şablonuna uyuyorsa taşıdığı veriyi döndürür.
+func (s Script) NullData() ([]byte, bool) {
+	instructions, err := s.parse()
+	if err != nil || len(instructions) != 2 || instructions[0].op != OpReturn || !instructions[1].isPush() {
+		return nil, false
+	}
+	data := instructions[1].data
+	if script, err := NullDataScript(data); err != nil || !bytes.Equal(s, script) {
+		return nil, false
+	}
+	return data, true
+}
+
+// IsUnspendable fonksiyonu, betiği kilit açma betiği ne olursa olsun hiçbir girdinin harcayamayacağı
+// kesin olan çıktıları ayırır: OP_RETURN ile başlayan ya da yorumlayıcının sınırını aşan betikler.
+// Bu çıktılar UTXO setinde tutulmaz; değerleri yakılmış olur.
+func (s Script) IsUnspendable() bool {
+	return (len(s) > 0 && s[0] == OpReturn) || len(s) > MaxScriptSize
+}
+
+// Class fonksiyonu, betiğin uyduğu standart şablonu döndürür.
+func (s Script) Class() ScriptClass {
+	if _, ok := s.PubKeyHash(); ok {
+		return PubKeyHashClass
+	}
+	if _, ok := s.ScriptHash(); ok {
+		return ScriptHashClass
+	}
+	if _, _, ok := s.MultiSig(); ok {
+		return MultiSigClass
+	}
+	if _, ok := s.AtomicSwap(); ok {
+		return AtomicSwapClass
+	}
+	if _, ok := s.NullData(); ok {
+		return NullDataClass
+	}
+	return NonStandardClass
+}
+
+// AddressScript fonksiyonu, etkin ağın adresine ödeme yapan kilitleme betiğini döndürür: cüzdan
+// adresleri için P2PKH, betik adresleri için P2SH. Adres geçersizse ya da başka bir ağa aitse
+// ErrInvalidAddress'i saran bir hata döner.
+func AddressScript(address string) (Script, error) {
+	version, hash, ok := wallet.DecodeAddress(address)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+
+	if version == wallet.ScriptAddressVersion {
+		return PayToScriptHashScript(hash), nil
+	}
+	return PayToPubKeyHashScript(hash), nil
+}
+
+// ScriptAddress fonksiyonu, redeemScript betiğine ödeme yapan P2SH adresini döndürür.
+func ScriptAddress(redeemScript Script) string {
+	return string(wallet.ScriptAddress(redeemScript.Hash160()))
+}
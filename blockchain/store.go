@@ -0,0 +1,459 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ChainStore, zincir verisinin (bloklar, zincir ucu, indeksler ve UTXO seti) saklandığı arka uçtur.
+// Okumalar View, yazmalar Update içinde yapılır; Update'e verilen fonksiyon hata döndürürse
+// yapılan değişikliklerin hiçbiri kaydedilmez. Diske yazan uygulama NewBadgerStore, bellekte
+// tutan uygulama NewMemoryStore ile oluşturulur.
+type ChainStore interface {
+	View(fn func(txn StoreTxn) error) error
+	Update(fn func(txn StoreTxn) error) error
+
+	// Clear fonksiyonu, verilen bölümün tüm kayıtlarını siler. Yeniden indeksleme ve eski
+	// veritabanlarının taşınması için kullanılır; çok sayıda kayıt birden fazla işlemde silinebilir.
+	Clear(section StoreSection) error
+
+	Close() error
+}
+
+// StoreSection, ChainStore içinde birlikte silinebilen kayıt grubudur.
+type StoreSection int
+
+const (
+	SectionUTXO        StoreSection = iota // harcanmamış çıktılar
+	SectionLegacyUTXO                      // eski "utxo-<txid>" düzenindeki UTXO kayıtları
+	SectionUndo                            // blokların geri alma kayıtları
+	SectionTxIndex                         // işlem indeksi
+	SectionHeightIndex                     // yükseklik indeksi
+	SectionSpendIndex                      // harcama indeksi
+)
+
+// StoreTxn, bir View ya da Update işlemi içinde zincir verisine erişim sağlar.
+// Bulunamayan kayıtlar için dönen hatalar ErrBlockNotFound, ErrTxNotFound, ErrOutputNotFound ya da
+// ErrUndoNotFound'u sarar.
+type StoreTxn interface {
+	// Bloklar ve genesisten itibaren birikmiş işleri
+	Block(hash []byte) (*Block, error)
+	PutBlock(block *Block, work *big.Int) error
+	DeleteBlock(hash []byte) error
+	ChainWork(hash []byte) (*big.Int, error)
+	ForEachBlock(fn func(block *Block) error) error
+
+	// Zincir ucu ve zincire ait diğer değerler (ör. konsensüs adı). Meta, kayıt yoksa nil döner.
+	Tip() ([]byte, error)
+	SetTip(hash []byte) error
+	Meta(key string) ([]byte, error)
+	SetMeta(key string, value []byte) error
+
+	// İşlem indeksi: ana zincirdeki işlemin bloğu ve blok içindeki sırası
+	TxLocation(txID []byte) (TxLocation, error)
+	PutTxLocation(txID []byte, loc TxLocation) error
+	DeleteTxLocation(txID []byte) error
+
+	// Harcama indeksi: ana zincirde bir çıktıyı harcayan işlemin ID'si
+	SpendingTx(txID []byte, out int) ([]byte, error)
+	PutSpendingTx(txID []byte, out int, spender []byte) error
+	DeleteSpendingTx(txID []byte, out int) error
+
+	// Yükseklik indeksi: ana zincirdeki her yüksekliğin blok hash'i
+	BlockHash(height int) ([]byte, error)
+	PutBlockHash(height int, hash []byte) error
+	DeleteBlockHash(height int) error
+	BlockHashes(from, to int) ([][]byte, error)
+
+	// UTXO seti: her harcanmamış çıktı (txid, vout) çifti ile tutulur
+	Output(txID []byte, out int) (TxOutput, error)
+	PutOutput(txID []byte, out int, output TxOutput) error
+	DeleteOutput(txID []byte, out int) error
+	ForEachOutput(fn func(txID []byte, out int, output TxOutput) error) error
+	HasLegacyOutputs() (bool, error)
+
+	// Geri alma kayıtları: bir bloğun UTXO setinden harcadığı çıktılar
+	Undo(blockHash []byte) (UndoRecord, error)
+	PutUndo(blockHash []byte, record UndoRecord) error
+	DeleteUndo(blockHash []byte) error
+}
+
+// Anahtar düzeni, her iki arka uçta da aynıdır ve önceki sürümlerin Badger veritabanlarıyla uyumludur
+// (bkz. migrateBlockKeys):
+//
+//	b-<hash>                blok (bkz. Block.Serialize)
+//	lh                      zincir ucunun hash'i
+//	cw-<hash>               bloğa kadar birikmiş toplam iş (Engine.Work)
+//	tx-<txid>               işlemin ana zincirdeki konumu (TxLocation)
+//	h-<yükseklik>           ana zincirde o yükseklikteki bloğun hash'i (8 bayt, big-endian)
+//	txo-<txid><vout>        harcanmamış çıktı; vout çıktının işlemdeki asıl indeksidir (4 bayt, big-endian)
+//	utxo-<txid>             eski sürümlerde bir işlemin tüm harcanmamış çıktıları (yalnızca taşıma için)
+//	undo-<hash>             bloğun geri alma kaydı (UndoRecord)
+//	sp-<txid><vout>         çıktıyı ana zincirde harcayan işlemin ID'si (vout txo- ile aynı biçimde)
+//
+// Zincire ait diğer değerler (ör. "consensus") adlarıyla saklanır. Her kaydın bir öneki olduğundan hiçbir
+// anahtar başka bir bölümün önekiyle başlayamaz; bloklar eskiden ham hash'leriyle saklanıyordu ve hash'i
+// ör. "h-" ile başlayan bir blok önek taramalarına karışabiliyordu.
+var (
+	blockPrefix       = []byte("b-")
+	lastHashKey       = []byte("lh")
+	chainWorkPrefix   = []byte("cw-")
+	txIndexPrefix     = []byte("tx-")
+	heightIndexPrefix = []byte("h-")
+	utxoPrefix        = []byte("txo-")
+	legacyUTXOPrefix  = []byte("utxo-")
+	undoPrefix        = []byte("undo-")
+	spendIndexPrefix  = []byte("sp-")
+)
+
+// sectionPrefixes, Clear ile silinen bölümlerin anahtar önekleridir.
+var sectionPrefixes = map[StoreSection][]byte{
+	SectionUTXO:        utxoPrefix,
+	SectionLegacyUTXO:  legacyUTXOPrefix,
+	SectionUndo:        undoPrefix,
+	SectionTxIndex:     txIndexPrefix,
+	SectionHeightIndex: heightIndexPrefix,
+	SectionSpendIndex:  spendIndexPrefix,
+}
+
+func prefixedKey(prefix, id []byte) []byte {
+	return append(append([]byte{}, prefix...), id...)
+}
+
+func heightKey(height int) []byte {
+	key := make([]byte, len(heightIndexPrefix)+8)
+	copy(key, heightIndexPrefix)
+	binary.BigEndian.PutUint64(key[len(heightIndexPrefix):], uint64(height))
+	return key
+}
+
+// outpointKey fonksiyonu, bir çıktının (txid, vout) çiftinden UTXO anahtarını oluşturur.
+func outpointKey(txID []byte, out int) []byte {
+	return prefixedOutpoint(utxoPrefix, txID, out)
+}
+
+// spendKey fonksiyonu, bir çıktının (txid, vout) çiftinden harcama indeksi anahtarını oluşturur.
+func spendKey(txID []byte, out int) []byte {
+	return prefixedOutpoint(spendIndexPrefix, txID, out)
+}
+
+func prefixedOutpoint(prefix, txID []byte, out int) []byte {
+	key := make([]byte, len(prefix)+len(txID)+4)
+	n := copy(key, prefix)
+	n += copy(key[n:], txID)
+	binary.BigEndian.PutUint32(key[n:], uint32(out))
+	return key
+}
+
+// parseOutpointKey fonksiyonu, UTXO anahtarından işlem ID'sini ve çıktı indeksini çıkarır.
+func parseOutpointKey(key []byte) ([]byte, int) {
+	k := bytes.TrimPrefix(key, utxoPrefix)
+	txID := append([]byte{}, k[:len(k)-4]...)
+	return txID, int(binary.BigEndian.Uint32(k[len(k)-4:]))
+}
+
+// blockKeysKey, blokların blockPrefix ile saklandığını belirten kayıttır. Anahtar, bölüm öneklerinden
+// biriyle başlamamalıdır.
+const blockKeysKey = "blockkeys"
+
+// migrateBlockKeysBatch, migrateBlockKeys'in tek bir işlemde taşıdığı en fazla blok sayısıdır.
+const migrateBlockKeysBatch = 1000
+
+// migrateBlockKeys fonksiyonu, blokları ham hash'leriyle saklayan eski veritabanlarındaki blok kayıtlarını
+// b-<hash> anahtarlarına taşır. Blok kayıtları, anahtarı bir hash uzunluğunda olan ve değeri o hash'e sahip
+// bir blok olan kayıtlardır; diğer tüm anahtarların öneki bu uzunluğu tutturmaz. Taşıma parçalar halinde
+// yapılır ve yarıda kalırsa bir sonraki açılışta kalan kayıtlarla sürer. Taşıma yapıldıysa true döner.
+func migrateBlockKeys(kv kvBackend) (bool, error) {
+	done := false
+	var keys [][]byte
+
+	err := kv.view(func(txn kvTxn) error {
+		if _, err := txn.get([]byte(blockKeysKey)); err == nil {
+			done = true
+			return nil
+		} else if !errors.Is(err, errKeyNotFound) {
+			return err
+		}
+
+		return txn.seek(nil, nil, func(key, value []byte) (bool, error) {
+			if len(key) != sha256.Size {
+				return true, nil
+			}
+			if block, err := Deserialize(value); err == nil && bytes.Equal(block.Hash, key) {
+				keys = append(keys, key)
+			}
+			return true, nil
+		})
+	})
+	if err != nil || done {
+		return false, err
+	}
+
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > migrateBlockKeysBatch {
+			batch = batch[:migrateBlockKeysBatch]
+		}
+		keys = keys[len(batch):]
+
+		err := kv.update(func(txn kvTxn) error {
+			for _, key := range batch {
+				value, err := txn.get(key)
+				if err != nil {
+					return err
+				}
+				if err := txn.set(prefixedKey(blockPrefix, key), value); err != nil {
+					return err
+				}
+				if err := txn.delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+		done = true
+	}
+
+	err = kv.update(func(txn kvTxn) error {
+		return txn.set([]byte(blockKeysKey), []byte(blockPrefix))
+	})
+	return done, err
+}
+
+// errKeyNotFound, arka uçların olmayan anahtarlar için döndürdüğü hatadır.
+var errKeyNotFound = errors.New("key not found")
+
+// kvTxn, arka uçların sağladığı anahtar-değer işlemidir. get, anahtar yoksa errKeyNotFound döner;
+// seek, prefix ile başlayan anahtarları start'tan itibaren artan sırada fn'e verir ve fn false
+// döndürünce durur.
+type kvTxn interface {
+	get(key []byte) ([]byte, error)
+	set(key, value []byte) error
+	delete(key []byte) error
+	seek(prefix, start []byte, fn func(key, value []byte) (bool, error)) error
+}
+
+// kvBackend, bir anahtar-değer deposudur. deletePrefix, prefix ile başlayan tüm anahtarları siler.
+type kvBackend interface {
+	view(fn func(kvTxn) error) error
+	update(fn func(kvTxn) error) error
+	deletePrefix(prefix []byte) error
+	close() error
+}
+
+// kvStore, ChainStore'u bir anahtar-değer arka ucu üzerinde yukarıdaki anahtar düzeniyle uygular.
+type kvStore struct {
+	kv kvBackend
+}
+
+func (s kvStore) View(fn func(txn StoreTxn) error) error {
+	return s.kv.view(func(txn kvTxn) error { return fn(storeTxn{txn}) })
+}
+
+func (s kvStore) Update(fn func(txn StoreTxn) error) error {
+	return s.kv.update(func(txn kvTxn) error { return fn(storeTxn{txn}) })
+}
+
+func (s kvStore) Clear(section StoreSection) error {
+	prefix, ok := sectionPrefixes[section]
+	if !ok {
+		return fmt.Errorf("unknown store section %d", section)
+	}
+	return s.kv.deletePrefix(prefix)
+}
+
+func (s kvStore) Close() error {
+	return s.kv.close()
+}
+
+// storeTxn, StoreTxn'i bir anahtar-değer işlemi üzerinde uygular.
+type storeTxn struct {
+	kv kvTxn
+}
+
+// lookup fonksiyonu, anahtarın değerini okur; anahtar yoksa notFound'u saran bir hata döner.
+func (t storeTxn) lookup(key []byte, notFound error, format string, args ...interface{}) ([]byte, error) {
+	value, err := t.kv.get(key)
+	if errors.Is(err, errKeyNotFound) {
+		return nil, fmt.Errorf("%w: "+format, append([]interface{}{notFound}, args...)...)
+	}
+	return value, err
+}
+
+func (t storeTxn) Block(hash []byte) (*Block, error) {
+	data, err := t.lookup(prefixedKey(blockPrefix, hash), ErrBlockNotFound, "%x", hash)
+	if err != nil {
+		return nil, err
+	}
+	return Deserialize(data)
+}
+
+func (t storeTxn) PutBlock(block *Block, work *big.Int) error {
+	if err := t.kv.set(prefixedKey(blockPrefix, block.Hash), block.Serialize()); err != nil {
+		return err
+	}
+	return t.kv.set(prefixedKey(chainWorkPrefix, block.Hash), work.Bytes())
+}
+
+func (t storeTxn) DeleteBlock(hash []byte) error {
+	if err := t.kv.delete(prefixedKey(blockPrefix, hash)); err != nil {
+		return err
+	}
+	return t.kv.delete(prefixedKey(chainWorkPrefix, hash))
+}
+
+func (t storeTxn) ChainWork(hash []byte) (*big.Int, error) {
+	data, err := t.lookup(prefixedKey(chainWorkPrefix, hash), ErrBlockNotFound, "chain work of %x", hash)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// ForEachBlock fonksiyonu, ana zincirde olsun olmasın saklanan bütün blokları hash sırasıyla fn'e verir.
+func (t storeTxn) ForEachBlock(fn func(block *Block) error) error {
+	return t.kv.seek(blockPrefix, blockPrefix, func(key, value []byte) (bool, error) {
+		block, err := Deserialize(value)
+		if err != nil {
+			return false, err
+		}
+		return true, fn(block)
+	})
+}
+
+func (t storeTxn) Tip() ([]byte, error) {
+	return t.lookup(lastHashKey, ErrChainNotFound, "no tip")
+}
+
+func (t storeTxn) SetTip(hash []byte) error {
+	return t.kv.set(lastHashKey, hash)
+}
+
+func (t storeTxn) Meta(key string) ([]byte, error) {
+	value, err := t.kv.get([]byte(key))
+	if errors.Is(err, errKeyNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (t storeTxn) SetMeta(key string, value []byte) error {
+	return t.kv.set([]byte(key), value)
+}
+
+func (t storeTxn) TxLocation(txID []byte) (TxLocation, error) {
+	data, err := t.lookup(prefixedKey(txIndexPrefix, txID), ErrTxNotFound, "%x", txID)
+	if err != nil {
+		return TxLocation{}, err
+	}
+	return DeserializeTxLocation(data)
+}
+
+func (t storeTxn) PutTxLocation(txID []byte, loc TxLocation) error {
+	return t.kv.set(prefixedKey(txIndexPrefix, txID), loc.Serialize())
+}
+
+func (t storeTxn) DeleteTxLocation(txID []byte) error {
+	return t.kv.delete(prefixedKey(txIndexPrefix, txID))
+}
+
+func (t storeTxn) SpendingTx(txID []byte, out int) ([]byte, error) {
+	return t.lookup(spendKey(txID, out), ErrTxNotFound, "no transaction spends %x:%d", txID, out)
+}
+
+func (t storeTxn) PutSpendingTx(txID []byte, out int, spender []byte) error {
+	return t.kv.set(spendKey(txID, out), spender)
+}
+
+func (t storeTxn) DeleteSpendingTx(txID []byte, out int) error {
+	return t.kv.delete(spendKey(txID, out))
+}
+
+func (t storeTxn) BlockHash(height int) ([]byte, error) {
+	return t.lookup(heightKey(height), ErrBlockNotFound, "height %d", height)
+}
+
+func (t storeTxn) PutBlockHash(height int, hash []byte) error {
+	return t.kv.set(heightKey(height), hash)
+}
+
+func (t storeTxn) DeleteBlockHash(height int) error {
+	return t.kv.delete(heightKey(height))
+}
+
+func (t storeTxn) BlockHashes(from, to int) ([][]byte, error) {
+	var hashes [][]byte
+
+	if from < 0 {
+		from = 0
+	}
+
+	err := t.kv.seek(heightIndexPrefix, heightKey(from), func(key, value []byte) (bool, error) {
+		if height := int(binary.BigEndian.Uint64(key[len(heightIndexPrefix):])); height > to {
+			return false, nil
+		}
+		hashes = append(hashes, value)
+		return true, nil
+	})
+
+	return hashes, err
+}
+
+func (t storeTxn) Output(txID []byte, out int) (TxOutput, error) {
+	data, err := t.lookup(outpointKey(txID, out), ErrOutputNotFound, "%x:%d", txID, out)
+	if err != nil {
+		return TxOutput{}, err
+	}
+	return DeserializeOutput(data)
+}
+
+func (t storeTxn) PutOutput(txID []byte, out int, output TxOutput) error {
+	return t.kv.set(outpointKey(txID, out), output.Serialize())
+}
+
+func (t storeTxn) DeleteOutput(txID []byte, out int) error {
+	return t.kv.delete(outpointKey(txID, out))
+}
+
+func (t storeTxn) ForEachOutput(fn func(txID []byte, out int, output TxOutput) error) error {
+	return t.kv.seek(utxoPrefix, utxoPrefix, func(key, value []byte) (bool, error) {
+		output, err := DeserializeOutput(value)
+		if err != nil {
+			return false, err
+		}
+		txID, out := parseOutpointKey(key)
+		return true, fn(txID, out, output)
+	})
+}
+
+func (t storeTxn) HasLegacyOutputs() (bool, error) {
+	found := false
+	err := t.kv.seek(legacyUTXOPrefix, legacyUTXOPrefix, func(key, value []byte) (bool, error) {
+		found = true
+		return false, nil
+	})
+	return found, err
+}
+
+func (t storeTxn) Undo(blockHash []byte) (UndoRecord, error) {
+	data, err := t.lookup(prefixedKey(undoPrefix, blockHash), ErrUndoNotFound, "block %x", blockHash)
+	if err != nil {
+		return UndoRecord{}, err
+	}
+	return DeserializeUndoRecord(data)
+}
+
+func (t storeTxn) PutUndo(blockHash []byte, record UndoRecord) error {
+	return t.kv.set(prefixedKey(undoPrefix, blockHash), record.Serialize())
+}
+
+func (t storeTxn) DeleteUndo(blockHash []byte) error {
+	return t.kv.delete(prefixedKey(undoPrefix, blockHash))
+}
@@ -0,0 +1,240 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// testStores fonksiyonu, aynı testin her iki arka uçta da çalışması için boş bir bellek deposu ve
+// geçici dizinde bir Badger deposu döndürür.
+func testStores(t *testing.T) map[string]ChainStore {
+	t.Helper()
+
+	badgerStore, err := NewBadgerStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]ChainStore{"memory": NewMemoryStore(), "badger": badgerStore}
+	t.Cleanup(func() {
+		for _, store := range stores {
+			store.Close()
+		}
+	})
+	return stores
+}
+
+// testBlock fonksiyonu, yalnızca depolama testleri için hash'i seed'den türetilen işlemsiz bir blok oluşturur.
+func testBlock(seed string, height int) *Block {
+	hash := sha256.Sum256([]byte(seed))
+	return &Block{
+		Version:    2,
+		Timestamp:  1700000000 + int64(height),
+		Hash:       hash[:],
+		PrevHash:   []byte{},
+		Height:     height,
+		MerkleRoot: make([]byte, 32),
+	}
+}
+
+// Update'e verilen fonksiyon hata döndürürse yazılan, üzerine yazılan ve silinen kayıtların hiçbiri kalmamalıdır.
+func TestStoreUpdateRollback(t *testing.T) {
+	txID := bytes.Repeat([]byte{0x11}, 32)
+	failed := errors.New("stop")
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := store.Update(func(txn StoreTxn) error {
+				if err := txn.PutOutput(txID, 0, TxOutput{5, []byte{0x01}}); err != nil {
+					return err
+				}
+				return txn.SetTip([]byte("old"))
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = store.Update(func(txn StoreTxn) error {
+				if err := txn.DeleteOutput(txID, 0); err != nil {
+					return err
+				}
+				if err := txn.PutOutput(txID, 1, TxOutput{7, []byte{0x02}}); err != nil {
+					return err
+				}
+				if err := txn.SetTip([]byte("new")); err != nil {
+					return err
+				}
+				return failed
+			})
+			if !errors.Is(err, failed) {
+				t.Fatalf("Update returned %v", err)
+			}
+
+			err = store.View(func(txn StoreTxn) error {
+				if out, err := txn.Output(txID, 0); err != nil || out.Value != 5 {
+					return fmt.Errorf("output 0: %v %v", out, err)
+				}
+				if _, err := txn.Output(txID, 1); !errors.Is(err, ErrOutputNotFound) {
+					return fmt.Errorf("output 1: %v", err)
+				}
+				if tip, err := txn.Tip(); err != nil || string(tip) != "old" {
+					return fmt.Errorf("tip %q: %v", tip, err)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// Yükseklik indeksi ve UTXO seti taramaları, kayıtların yazılma sırasından bağımsız olarak anahtar
+// sırasında dönmelidir; iki arka uç aynı sonucu vermelidir.
+func TestStoreSeekOrder(t *testing.T) {
+	heights := []int{3, 256, 0, 2, 1, 255}
+	outputs := []struct {
+		txID byte
+		out  int
+	}{{0x22, 1}, {0x11, 256}, {0x22, 0}, {0x11, 2}}
+
+	results := make(map[string][]string)
+	for name, store := range testStores(t) {
+		err := store.Update(func(txn StoreTxn) error {
+			for _, height := range heights {
+				if err := txn.PutBlockHash(height, []byte{byte(height)}); err != nil {
+					return err
+				}
+			}
+			for _, o := range outputs {
+				if err := txn.PutOutput(bytes.Repeat([]byte{o.txID}, 32), o.out, TxOutput{o.out, []byte{o.txID}}); err != nil {
+					return err
+				}
+			}
+			// Tarama öneklere bağlı kalmalıdır: bu kayıtlar sonuçlara karışmamalıdır
+			if err := txn.PutBlock(testBlock("block", 1), big.NewInt(1)); err != nil {
+				return err
+			}
+			return txn.PutTxLocation(bytes.Repeat([]byte{0x33}, 32), TxLocation{BlockHash: []byte{0x01}})
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		var got []string
+		err = store.View(func(txn StoreTxn) error {
+			hashes, err := txn.BlockHashes(1, 255)
+			if err != nil {
+				return err
+			}
+			for _, hash := range hashes {
+				got = append(got, fmt.Sprintf("h%x", hash))
+			}
+			return txn.ForEachOutput(func(txID []byte, out int, output TxOutput) error {
+				got = append(got, fmt.Sprintf("%x:%d=%d", txID[:1], out, output.Value))
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		results[name] = got
+	}
+
+	want := []string{"h01", "h02", "h03", "hff", "11:2=2", "11:256=256", "22:0=0", "22:1=1"}
+	for name, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+// Clear yalnızca verilen bölümün kayıtlarını silmelidir.
+func TestStoreClear(t *testing.T) {
+	txID := bytes.Repeat([]byte{0x11}, 32)
+	block := testBlock("block", 1)
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := store.Update(func(txn StoreTxn) error {
+				if err := txn.PutOutput(txID, 0, TxOutput{5, []byte{0x01}}); err != nil {
+					return err
+				}
+				if err := txn.PutBlock(block, big.NewInt(3)); err != nil {
+					return err
+				}
+				return txn.PutBlockHash(1, block.Hash)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Clear(SectionUTXO); err != nil {
+				t.Fatal(err)
+			}
+
+			err = store.View(func(txn StoreTxn) error {
+				if _, err := txn.Output(txID, 0); !errors.Is(err, ErrOutputNotFound) {
+					return fmt.Errorf("output: %v", err)
+				}
+				if _, err := txn.Block(block.Hash); err != nil {
+					return err
+				}
+				if work, err := txn.ChainWork(block.Hash); err != nil || work.Int64() != 3 {
+					return fmt.Errorf("chain work %v: %v", work, err)
+				}
+				_, err := txn.BlockHash(1)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// Ham hash'iyle saklanmış bloklar b-<hash> anahtarlarına taşınmalı, taşıma bir kez yapılmalı ve hash
+// uzunluğunda olup blok olmayan kayıtlara dokunulmamalıdır.
+func TestMigrateBlockKeys(t *testing.T) {
+	block := testBlock("legacy", 0)
+	other := bytes.Repeat([]byte{0x44}, sha256.Size)
+
+	kv := &memoryBackend{data: map[string][]byte{
+		string(block.Hash):  block.Serialize(),
+		string(other):       []byte("not a block"),
+		string(lastHashKey): block.Hash,
+	}}
+
+	migrated, err := migrateBlockKeys(kv)
+	if err != nil || !migrated {
+		t.Fatalf("migrateBlockKeys = %v, %v", migrated, err)
+	}
+	if _, ok := kv.data[string(block.Hash)]; ok {
+		t.Fatal("raw block key was kept")
+	}
+	if _, ok := kv.data[string(other)]; !ok {
+		t.Fatal("non-block record was moved")
+	}
+
+	err = kvStore{kv}.View(func(txn StoreTxn) error {
+		stored, err := txn.Block(block.Hash)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(stored.Hash, block.Hash) {
+			return fmt.Errorf("stored block %x", stored.Hash)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migrated, err := migrateBlockKeys(kv); err != nil || migrated {
+		t.Fatalf("second migrateBlockKeys = %v, %v", migrated, err)
+	}
+}
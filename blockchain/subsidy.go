@@ -0,0 +1,62 @@
+package blockchain
+
+// Blok ödülü (subsidy) yüksekliğe bağlıdır: genesisten itibaren Params.InitialSubsidy ile başlar ve
+// her Params.HalvingInterval blokta bir yarıya iner. Tamsayı bölmesi nedeniyle ödül sonunda sıfıra
+// düşer; böylece üretilebilecek toplam token miktarı (MaxSupply) sınırlıdır.
+
+// maxHalvings, ödülün kesinlikle sıfıra indiği yarılanma sayısıdır (int 64 bitten fazla kaydırılamaz).
+const maxHalvings = 63
+
+// BlockSubsidy fonksiyonu, verilen yükseklikteki bloğun coinbase işlemiyle üretebileceği yeni token
+// miktarını döndürür. HalvingInterval sıfır ya da negatifse ödül hiç yarılanmaz.
+func (p Params) BlockSubsidy(height int) int {
+	if height < 0 || p.InitialSubsidy <= 0 {
+		return 0
+	}
+	if p.HalvingInterval <= 0 {
+		return p.InitialSubsidy
+	}
+
+	halvings := height / p.HalvingInterval
+	if halvings >= maxHalvings {
+		return 0
+	}
+	return p.InitialSubsidy >> uint(halvings)
+}
+
+// IssuedSupply fonksiyonu, genesisten verilen yüksekliğe kadar (dahil) ödül planına göre
+// üretilebilecek toplam token miktarını döndürür.
+func (p Params) IssuedSupply(height int) int {
+	supply := 0
+
+	for start := 0; start <= height; {
+		subsidy := p.BlockSubsidy(start)
+		if subsidy == 0 {
+			break
+		}
+
+		end := height
+		if p.HalvingInterval > 0 {
+			end = (start/p.HalvingInterval+1)*p.HalvingInterval - 1 // bu yarılanma döneminin son bloğu
+			if end > height {
+				end = height
+			}
+		}
+		supply += subsidy * (end - start + 1)
+		start = end + 1
+	}
+
+	return supply
+}
+
+// MaxSupply fonksiyonu, ödül planıyla üretilebilecek toplam token miktarını döndürür.
+// Ödül hiç yarılanmıyorsa arz sınırsızdır ve -1 döner.
+func (p Params) MaxSupply() int {
+	if p.HalvingInterval <= 0 {
+		if p.InitialSubsidy <= 0 {
+			return 0
+		}
+		return -1
+	}
+	return p.IssuedSupply(maxHalvings*p.HalvingInterval - 1)
+}
@@ -0,0 +1,26 @@
+package blockchain
+
+import (
+	"sort"
+)
+
+// CalcPastMedianTime fonksiyonu, block dahil son Params.MedianTimeBlocks bloğun zaman damgalarının
+// medyanını (median time past) döndürür. Bir bloğun zaman damgası, ebeveyninin bu medyanından büyük
+// olmalıdır. Zincirin başında daha az blok varsa mevcut blokların medyanı alınır.
+func (chain *BlockChain) CalcPastMedianTime(block *Block) (int64, error) {
+	timestamps := []int64{block.Timestamp}
+
+	current := block
+	for len(timestamps) < chain.Params.MedianTimeBlocks && len(current.PrevHash) != 0 {
+		parent, err := chain.GetBlock(current.PrevHash)
+		if err != nil {
+			return 0, err
+		}
+		current = &parent
+		timestamps = append(timestamps, current.Timestamp)
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return timestamps[len(timestamps)/2], nil
+}
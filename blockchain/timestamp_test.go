@@ -0,0 +1,38 @@
+package blockchain
+
+import (
+	"testing"
+)
+
+// Medyan, zincirin parametrelerindeki MedianTimeBlocks kadar bloğun (block dahil) zaman damgalarından
+// alınır; zincirin başında mevcut blokların medyanı kullanılır.
+func TestCalcPastMedianTime(t *testing.T) {
+	chain, tip := newDifficultyChain(t, RegTestParams.InitialBits(), []int64{100, 500, 200, 400, 300, 900})
+
+	tests := []struct {
+		blocks int
+		want   int64
+	}{
+		{1, 900},
+		{3, 400},
+		{5, 400},
+		{6, 400},
+		{11, 400},
+	}
+
+	for _, test := range tests {
+		chain.Params.MedianTimeBlocks = test.blocks
+		if got, err := chain.CalcPastMedianTime(tip); err != nil || got != test.want {
+			t.Errorf("MedianTimeBlocks %d: CalcPastMedianTime = %d, %v, want %d", test.blocks, got, err, test.want)
+		}
+	}
+
+	for _, params := range []Params{
+		func(p Params) Params { p.MedianTimeBlocks = 0; return p }(RegTestParams),
+		func(p Params) Params { p.MaxFutureBlockTime = -1; return p }(RegTestParams),
+	} {
+		if err := params.Validate(); err == nil {
+			t.Errorf("Validate accepted MedianTimeBlocks %d, MaxFutureBlockTime %d", params.MedianTimeBlocks, params.MaxFutureBlockTime)
+		}
+	}
+}
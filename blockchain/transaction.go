@@ -20,50 +20,82 @@ func init() {
 	gob.Register(elliptic.P256())
 }
 
+// MaxMoney, bir çıktının ya da işlemin taşıyabileceği en yüksek toplam değerdir.
+const MaxMoney = 21000000
+
+// TxVersion, yeni oluşturulan işlemlerin sürümüdür. Sürümü 0 olan işlemler kanonik kodlamadan önce
+// oluşturulmuştur; ID'leri ve merkle yaprakları eskisi gibi gob kodlamasından hesaplanır.
+// Sürüm 2 ile girdi ve çıktılar betik taşır (bkz. script.go); sürüm 1 işlemler P2PKH betiklerine çevrilerek okunur.
+// Sürüm 3 ile işlem bir kilit zamanı, girdiler ise göreli kilit taşıyan bir sıra numarası taşır (bkz. locktime.go).
+const TxVersion = 3
+
 type Transaction struct {
-	ID      []byte     //transectıon hası
-	Inputs  []TxInput  //bu transectıondakı ınputlar
-	Outputs []TxOutput //bu transectıondakı outputlar
+	Version  int32      //islemin kodlama surumu, bkz. TxVersion
+	ID       []byte     //transectıon hası
+	Inputs   []TxInput  //bu transectıondakı ınputlar
+	Outputs  []TxOutput //bu transectıondakı outputlar
+	LockTime uint32     //islemin kazılabilecegi en erken blok yuksekligi ya da zamanı, 0 ise kilit yoktur
 }
 
-// CoinbaseTx fonksiyonu, bir coinbase transaction oluşturur.
-func CoinbaseTx(to, data string) *Transaction {
+// CoinbaseTx fonksiyonu, to adresine reward kadar token üreten bir coinbase transaction oluşturur.
+// reward genellikle zincirin Engine.Reward kuralından alınır. Adres geçersizse ErrInvalidAddress döner.
+func CoinbaseTx(to, data string, reward int) (*Transaction, error) {
+	if !wallet.ValidateAddress(to) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, to)
+	}
+
 	if data == "" { //data boş ise gir
 		randData := make([]byte, 24)  //data 24 byte'lık bir diziye dönüştür
 		_, err := rand.Read(randData) //rastgele sayı uretıcısı ile diziye dönüştür (diziyi doldur)
 		if err != nil {
-			log.Panic(err)
+			return nil, err
 		}
 		data = fmt.Sprintf("%x", randData) // diziyi stringe doğru dönüştür
 
 	}
 
-	txin := TxInput{[]byte{}, -1, nil, []byte(data)} //hıcbır cıktıya referabs vermez ,cıkıs endexi -1 aynı referans yok , sadce data mesajı vardır
-	txout := NewTXOutput(20, to)                     //100 tokeni to ya gonderırı
+	txin := TxInput{[]byte{}, -1, NewScriptBuilder().AddData([]byte(data)).Script(), SequenceFinal} //hıcbır cıktıya referabs vermez ,cıkıs endexi -1 aynı referans yok , sadce data mesajı vardır
+	txout := NewTXOutput(reward, to)                                                                //odul kadar tokeni to ya gonderırı
 
-	tx := Transaction{nil, []TxInput{txin}, []TxOutput{*txout}} //transectıonı olustururuz
-	tx.ID = tx.Hash()                                           //Transectıon hashini olustururuz                                           //Transectıon Id sını olustururuz
-	return &tx
+	tx := Transaction{TxVersion, nil, []TxInput{txin}, []TxOutput{*txout}, 0} //transectıonı olustururuz
+	tx.ID = tx.Hash()                                                         //Transectıon hashini olustururuz                                           //Transectıon Id sını olustururuz
+	return &tx, nil
 }
 
 // NewTransaction, belirtilen bir adresten başka bir adrese belirtilen miktar token transferi yapacak yeni bir işlem oluşturur.
-func NewTransaction(w *wallet.Wallet, to string, amount int, UTXO *UTXOSet) *Transaction {
+// fee, bloğu kazan madenciye bırakılan ücrettir; girdilerden amount ve fee çıktıktan sonra kalan para üstü gönderene döner.
+// lockTime sıfır değilse işlem bu blok yüksekliğinden ya da zamandan önce kazılamaz (bkz. Transaction.IsFinal).
+// Geçersiz girdiler için ErrInvalidAddress, ErrInvalidAmount ya da ErrInsufficientFunds'ı saran bir hata döner.
+func NewTransaction(w *wallet.Wallet, to string, amount, fee int, lockTime uint32, UTXO *UTXOSet) (*Transaction, error) {
 	var inputs []TxInput
 	var outputs []TxOutput
 
+	if !wallet.ValidateAddress(to) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, to)
+	}
+
+	if amount <= 0 || fee < 0 || amount > MaxMoney || fee > MaxMoney {
+		return nil, fmt.Errorf("%w: amount %d, fee %d", ErrInvalidAmount, amount, fee)
+	}
+
 	pubKeyHash := wallet.PublicKeyHash(w.PublicKey)
-	acc, validOutputs := UTXO.FindSpendableOutputs(pubKeyHash, amount)
+	acc, validOutputs, err := UTXO.FindSpendableOutputs(pubKeyHash, amount+fee)
+	if err != nil {
+		return nil, err
+	}
 
-	if acc < amount {
-		log.Panic("Error: not enough funds")
+	if acc < amount+fee {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrInsufficientFunds, acc, amount+fee)
 	}
 
 	for txid, outs := range validOutputs {
 		txID, err := hex.DecodeString(txid)
-		Handle(err)
+		if err != nil {
+			return nil, err
+		}
 
 		for _, out := range outs {
-			input := TxInput{txID, out, nil, w.PublicKey}
+			input := TxInput{txID, out, nil, SequenceFinal} //kilit açma betiği imzalanırken yazılır
 			inputs = append(inputs, input)
 		}
 	}
@@ -72,23 +104,32 @@ func NewTransaction(w *wallet.Wallet, to string, amount int, UTXO *UTXOSet) *Tra
 
 	outputs = append(outputs, *NewTXOutput(amount, to))
 
-	if acc > amount {
-		outputs = append(outputs, *NewTXOutput(acc-amount, from))
+	if change := acc - amount - fee; change > 0 {
+		outputs = append(outputs, *NewTXOutput(change, from))
 	}
 
-	tx := Transaction{nil, inputs, outputs}
-	tx.ID = tx.Hash()
-	UTXO.Blockchain.SignTransaction(&tx, w.PrivateKey)
+	tx := Transaction{TxVersion, nil, inputs, outputs, lockTime}
+	if err := UTXO.Blockchain.SignTransaction(&tx, w.PrivateKey); err != nil {
+		return nil, err
+	}
+	tx.ID = tx.Hash() //ID imzalar dahil edilerek hesaplanır, blok doğrulaması ID'yi bu şekilde kontrol eder
 
-	return &tx
+	return &tx, nil
 }
-func DeserializeTransaction(data []byte) Transaction {
-	var transaction Transaction
 
+// DeserializeTransaction fonksiyonu, işlemi kanonik ya da (sürümü 0 olan işlemler için) gob kodlamasından çözer.
+// Çözülemeyen veri için ErrMalformedEncoding'i saran bir hata döner.
+func DeserializeTransaction(data []byte) (Transaction, error) {
+	if !isGob(data) {
+		return DecodeTransaction(data)
+	}
+
+	var transaction gobTransaction
 	decoder := gob.NewDecoder(bytes.NewReader(data))
-	err := decoder.Decode(&transaction)
-	Handle(err)
-	return transaction
+	if err := decoder.Decode(&transaction); err != nil {
+		return Transaction{}, fmt.Errorf("%w: transaction: %v", ErrMalformedEncoding, err)
+	}
+	return transaction.transaction(), nil
 }
 
 /*
@@ -104,11 +145,46 @@ func (tx *Transaction) IsCoinbase() bool {
 }
 
 // Serialize fonksiyonu, bir Transaction yapısını serileştirir (encode eder) ve byte dizisi olarak döndürür.
+// Sürümlü işlemler kanonik olarak kodlanır (bkz. encoding.go); sürümü 0 olan eski işlemler gob ile kodlanır.
 func (tx Transaction) Serialize() []byte {
+	if tx.Version >= 1 {
+		var e encoder
+		tx.encode(&e)
+		return e.bytes()
+	}
+
+	// gob tip tanımını (tip ve alan adlarıyla) akışa da yazdığından eski ID'lerin değişmemesi için
+	// Version alanı ve betikleri olmayan eski yapılar aynı adlarla kodlanır
+	type TxInput struct {
+		ID        []byte
+		Out       int
+		Signature []byte
+		PubKey    []byte
+	}
+	type TxOutput struct {
+		Value     int
+		PublicKey []byte
+	}
+	type Transaction struct {
+		ID      []byte
+		Inputs  []TxInput
+		Outputs []TxOutput
+	}
+
+	legacy := Transaction{ID: tx.ID}
+	for _, in := range tx.Inputs {
+		signature, pubKey, _ := legacyInputFields(in.ScriptSig)
+		legacy.Inputs = append(legacy.Inputs, TxInput{in.ID, in.Out, signature, pubKey})
+	}
+	for _, out := range tx.Outputs {
+		hash, _ := legacyOutputHash(out.ScriptPubKey)
+		legacy.Outputs = append(legacy.Outputs, TxOutput{out.Value, hash})
+	}
+
 	var encoded bytes.Buffer        // Yeni bir bytes.Buffer oluşturulur
 	enc := gob.NewEncoder(&encoded) // gob (Go's binary serialization format) ile encode edici oluşturulur
 
-	err := enc.Encode(tx) // Transaction yapısını encode eder
+	err := enc.Encode(legacy) // Transaction yapısını encode eder
 	if err != nil {
 		log.Panic(err) // Hata durumunda hata mesajı gösterir ve işlemi sonlandırır
 	}
@@ -128,90 +204,199 @@ func (tx *Transaction) Hash() []byte {
 
 // Sign fonksiyonu, bir Transaction yapısını imzalar.
 // İmzalamak için verilen private anahtar (privKey) kullanılır ve işlemi daha önce yapılmış olan işlemlerle (prevTXs) ilişkilendirir.
-func (tx *Transaction) Sign(privKey ecdsa.PrivateKey, prevTXs map[string]Transaction) {
+// Anahtarın harcayabildiği her P2PKH çıktısı için girdiye <imza> <public key> kilit açma betiği yazılır;
+// çoklu imzalı P2SH girdilerine ise imza diğer imzalarla birlikte eklenir (bkz. signMultiSigInput).
+// Bu sayede işlem, birden fazla cüzdan tarafından sırayla kısmen imzalanabilir. Anahtarın imzalayamadığı
+// girdiler değiştirilmez.
+// Bir girdinin harcadığı çıktı prevTXs içinde yoksa ErrMissingInput'u, anahtar hiçbir girdiyi
+// imzalayamıyorsa ErrNonStandardScript'i saran bir hata döner.
+func (tx *Transaction) Sign(privKey ecdsa.PrivateKey, prevTXs map[string]Transaction) error {
 	if tx.IsCoinbase() { // Eğer işlem bir coinbase işlemi ise (ödül işlemi ise)
-		return // İşlem yapma, çünkü coinbase işlemleri imzalanmaz
+		return nil // İşlem yapma, çünkü coinbase işlemleri imzalanmaz
 	}
 
 	for _, in := range tx.Inputs {
 		// İşlemdeki her girdi için önceki işlem kontrolü yapılır
-		if prevTXs[hex.EncodeToString(in.ID)].ID == nil {
-			log.Panic("ERROR: Previous transaction is not correct") // Önceki işlem doğruluğu sağlanmazsa hata ver ve işlemi sonlandır
+		if prevTX := prevTXs[hex.EncodeToString(in.ID)]; prevTX.ID == nil || in.Out < 0 || in.Out >= len(prevTX.Outputs) {
+			return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out) // Önceki işlem doğruluğu sağlanmazsa hata döner
 		}
 	}
 
-	// İşlem yapısının bir kopyası oluşturulur ve gerekli alanlar temizlenir
-	txCopy := tx.TrimmedCopy()
+	pubKey := make([]byte, 64) // public key sabit uzunlukta (X||Y, 32+32 bayt) yazılır
+	privKey.PublicKey.X.FillBytes(pubKey[:32])
+	privKey.PublicKey.Y.FillBytes(pubKey[32:])
+	pubKeyHash := wallet.PublicKeyHash(pubKey)
 
 	// İşlemdeki her girdi için imzalama işlemi yapılır
-	for inId, in := range txCopy.Inputs {
-		prevTX := prevTXs[hex.EncodeToString(in.ID)]                  // Girdinin önceki işlem verisini alır
-		txCopy.Inputs[inId].Signature = nil                           // İmza alanı temizlenir
-		txCopy.Inputs[inId].PubKey = prevTX.Outputs[in.Out].PublicKey // Girdiye ait PublicKey ayarlanır
-		txCopy.ID = txCopy.Hash()                                     // İşlemin hash değeri hesaplanır
-		txCopy.Inputs[inId].PubKey = nil                              // PublicKey alanı temizlenir (güvenlik amacıyla)
+	signable := 0
+	for inId, in := range tx.Inputs {
+		prevOut := prevTXs[hex.EncodeToString(in.ID)].Outputs[in.Out] // Girdinin harcadığı çıktıyı alır
+
+		switch prevOut.ScriptPubKey.Class() {
+		case PubKeyHashClass:
+			if !prevOut.IsLockedWithKey(pubKeyHash) {
+				continue // çıktı başka bir anahtara ait
+			}
+			signature, err := tx.signInput(privKey, inId, prevOut.ScriptPubKey)
+			if err != nil {
+				return err
+			}
+			tx.Inputs[inId].ScriptSig = PubKeyHashSigScript(signature, pubKey) // İşlemdeki girdiye kilit açma betiğini ekler
+			signable++
+
+		case ScriptHashClass:
+			ok, err := tx.signMultiSigInput(privKey, pubKey, inId, prevOut.ScriptPubKey)
+			if err != nil {
+				return err
+			}
+			if ok {
+				signable++
+			}
+		}
+	}
 
-		// İşlemi imzalar
-		r, s, err := ecdsa.Sign(rand.Reader, &privKey, txCopy.ID) // ECDSA algoritması kullanarak işlemi imzalar
-		Handle(err)                                               // Hata durumunda işlemi ele alır
-		signature := append(r.Bytes(), s.Bytes()...)              // İmza değerleri birleştirilir
+	if signable == 0 {
+		return fmt.Errorf("%w: key %x cannot sign any input of %x", ErrNonStandardScript, pubKeyHash, tx.ID)
+	}
+	return nil
+}
 
-		tx.Inputs[inId].Signature = signature // İşlemdeki girdiye imzayı ekler
+// signInput fonksiyonu, inIdx numaralı girdinin scriptCode betiğiyle kilitli çıktıyı harcayan imzasını döndürür.
+func (tx *Transaction) signInput(privKey ecdsa.PrivateKey, inIdx int, scriptCode Script) ([]byte, error) {
+	digest, err := tx.SignatureHash(inIdx, scriptCode) // İşlemin imzalanacak hash değeri hesaplanır
+	if err != nil {
+		return nil, err
 	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, &privKey, digest) // ECDSA algoritması kullanarak işlemi imzalar
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 64) // İmza değerleri sabit uzunlukta (r||s, 32+32 bayt) birleştirilir
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature, nil
 }
 
-// Verify fonksiyonu, bir Transaction yapısının geçerliliğini doğrular.
-// Geçerlilik kontrolü için verilen önceki işlemler haritası (prevTXs) kullanılır.
-func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
+// SignatureHash fonksiyonu, inIdx numaralı girdinin imzasının kapsadığı hash'i döndürür. Hash, tüm kilit
+// açma betikleri silinmiş ve bu girdiye harcanan çıktının betiği (scriptCode) yazılmış kopyanın hash'idir;
+// böylece imza işlemin tüm girdi ve çıktılarını kapsar.
+// Betiklerden önceki (sürümü 2'den küçük) işlemlerde girdiye çıktının public key hash'i yazılırdı;
+// bu işlemler yalnızca P2PKH çıktılarını harcayabilir.
+func (tx *Transaction) SignatureHash(inIdx int, scriptCode Script) ([]byte, error) {
+	if inIdx < 0 || inIdx >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input %d out of range", inIdx)
+	}
+
+	txCopy := tx.TrimmedCopy() // İşlem yapısının bir kopyası oluşturulur ve kilit açma betikleri temizlenir
+
+	if tx.Version >= scriptTxVersion {
+		txCopy.Inputs[inIdx].ScriptSig = scriptCode // Girdiye harcadığı çıktının betiği yazılır
+	} else {
+		pubKeyHash, ok := legacyOutputHash(scriptCode)
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d transaction spends a %s output", ErrBadTxVersion, tx.Version, scriptCode.Class())
+		}
+		txCopy.Inputs[inIdx].ScriptSig = legacyInputScript(nil, pubKeyHash) // Girdiye ait PublicKey alanı ayarlanır
+	}
+
+	return txCopy.Hash(), nil // İşlemin hash değeri hesaplanır
+}
+
+// txSignatureChecker, betik yorumlayıcısının imza işlemlerini bir işlemin girdisine göre doğrular.
+type txSignatureChecker struct {
+	tx    *Transaction
+	inIdx int
+}
+
+// CheckSignature fonksiyonu, imzanın (r||s) public key (X||Y) ile girdinin SignatureHash'ine ait olup olmadığını döndürür.
+func (c txSignatureChecker) CheckSignature(signature, pubKey []byte, scriptCode Script) bool {
+	digest, err := c.tx.SignatureHash(c.inIdx, scriptCode)
+	if err != nil {
+		return false
+	}
+
+	// İmza ve PublicKey'i parçalara ayırır
+	r := big.Int{}
+	s := big.Int{}
+	sigLen := len(signature)
+	r.SetBytes(signature[:(sigLen / 2)]) // İmzanın ilk yarısı r değeri olarak ayarlanır
+	s.SetBytes(signature[(sigLen / 2):]) // İmzanın ikinci yarısı s değeri olarak ayarlanır
+
+	x := big.Int{}
+	y := big.Int{}
+	keyLen := len(pubKey)
+	x.SetBytes(pubKey[:(keyLen / 2)]) // PublicKey'in ilk yarısı x değeri olarak ayarlanır
+	y.SetBytes(pubKey[(keyLen / 2):]) // PublicKey'in ikinci yarısı y değeri olarak ayarlanır
+
+	curve := elliptic.P256() // ECDSA P256 eğrisi kullanılır
+	if !curve.IsOnCurve(&x, &y) {
+		return false
+	}
+	rawPubKey := ecdsa.PublicKey{Curve: curve, X: &x, Y: &y} // Raw public key oluşturulur
+
+	// ECDSA algoritması kullanarak imza doğrulaması yapılır
+	return ecdsa.Verify(&rawPubKey, digest, &r, &s)
+}
+
+// CheckLockTime fonksiyonu, işlemin kilit zamanının lockTime ile aynı türden (yükseklik ya da zaman) ve
+// en az lockTime olup olmadığını döndürür. İşlem kilit zamanından önce kazılamadığından, bu kontrolü geçen
+// bir girdinin harcadığı çıktı lockTime'dan önce harcanamaz.
+func (c txSignatureChecker) CheckLockTime(lockTime int64) bool {
+	txLockTime := int64(c.tx.LockTime)
+	if (lockTime < LockTimeThreshold) != (txLockTime < LockTimeThreshold) {
+		return false
+	}
+	return lockTime <= txLockTime
+}
+
+// CheckSequence fonksiyonu, girdinin göreli kilidinin sequence ile aynı türden (blok sayısı ya da süre) ve
+// en az sequence kadar olup olmadığını döndürür. Göreli kilitler sürüm 3'ten önceki işlemlerde yoktur.
+func (c txSignatureChecker) CheckSequence(sequence int64) bool {
+	txSequence := c.tx.Inputs[c.inIdx].Sequence
+	if c.tx.Version < lockTimeTxVersion || txSequence&SequenceLockTimeDisabled != 0 {
+		return false
+	}
+	lock := uint32(sequence)
+	if lock&SequenceLockTimeIsSeconds != txSequence&SequenceLockTimeIsSeconds {
+		return false
+	}
+	return lock&SequenceLockTimeMask <= txSequence&SequenceLockTimeMask
+}
+
+// VerifyInputs fonksiyonu, her girdinin kilit açma betiğini harcadığı çıktının kilitleme betiğiyle birlikte
+// çalıştırır. Geçerlilik kontrolü için verilen önceki işlemler haritası (prevTXs) kullanılır.
+// Önceki işlemi bulunamayan girdi için ErrMissingInput'u, betiği başarısız olan ilk girdi için
+// ErrBadSignature'ı saran bir hata döner.
+func (tx *Transaction) VerifyInputs(prevTXs map[string]Transaction) error {
 	if tx.IsCoinbase() { // Eğer işlem bir coinbase işlemi ise
-		return true // Coinbase işlemleri doğrudur (her zaman geçerli)
+		return nil // Coinbase işlemleri doğrudur (her zaman geçerli)
 	}
 
 	// İşlemdeki her girdi için önceki işlem doğruluğu kontrol edilir
 	for _, in := range tx.Inputs {
-		if prevTXs[hex.EncodeToString(in.ID)].ID == nil {
-			log.Panic("Previous transaction not correct") // Önceki işlem doğruluğu sağlanmazsa hata verir ve işlemi sonlandırır
+		if prevTX := prevTXs[hex.EncodeToString(in.ID)]; prevTX.ID == nil || in.Out < 0 || in.Out >= len(prevTX.Outputs) {
+			return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out) // Önceki işlem bulunamayan girdiler doğrulanamaz
 		}
 	}
 
-	// İşlem yapısının bir kopyası oluşturulur ve gerekli alanlar temizlenir
-	txCopy := tx.TrimmedCopy()
-
-	// ECDSA P256 eğrisi kullanılır
-	curve := elliptic.P256()
-
-	// Her girdi için imza doğrulaması yapılır
+	// Her girdi için betikler çalıştırılır
 	for inId, in := range tx.Inputs {
-		prevTx := prevTXs[hex.EncodeToString(in.ID)]                  // Girdinin önceki işlem verisini alır
-		txCopy.Inputs[inId].Signature = nil                           // İmza alanı temizlenir
-		txCopy.Inputs[inId].PubKey = prevTx.Outputs[in.Out].PublicKey // Girdiye ait PublicKey ayarlanır
-		txCopy.ID = txCopy.Hash()                                     // İşlemin hash değeri hesaplanır
-		txCopy.Inputs[inId].PubKey = nil                              // PublicKey alanı temizlenir (güvenlik amacıyla)
-
-		// İmza ve PublicKey'i parçalara ayırır
-		r := big.Int{}
-		s := big.Int{}
-		sigLen := len(in.Signature)
-		r.SetBytes(in.Signature[:(sigLen / 2)]) // İmzanın ilk yarısı r değeri olarak ayarlanır
-		s.SetBytes(in.Signature[(sigLen / 2):]) // İmzanın ikinci yarısı s değeri olarak ayarlanır
-
-		x := big.Int{}
-		y := big.Int{}
-		keyLen := len(in.PubKey)
-		x.SetBytes(in.PubKey[:(keyLen / 2)]) // PublicKey'in ilk yarısı x değeri olarak ayarlanır
-		y.SetBytes(in.PubKey[(keyLen / 2):]) // PublicKey'in ikinci yarısı y değeri olarak ayarlanır
-
-		rawPubKey := ecdsa.PublicKey{curve, &x, &y} // Raw public key oluşturulur
-		// ECDSA algoritması kullanarak imza doğrulaması yapılır
-		if ecdsa.Verify(&rawPubKey, txCopy.ID, &r, &s) == false {
-			return false // İmza doğrulanamazsa false döner
+		prevOut := prevTXs[hex.EncodeToString(in.ID)].Outputs[in.Out] // Girdinin harcadığı çıktıyı alır
+		if err := ExecuteScript(in.ScriptSig, prevOut.ScriptPubKey, txSignatureChecker{tx, inId}); err != nil {
+			return fmt.Errorf("%w: %x input %d: %v", ErrBadSignature, tx.ID, inId, err)
 		}
 	}
 
-	return true // İşlem geçerli ise true döner
+	return nil // İşlem geçerli ise nil döner
+}
+
+// Verify fonksiyonu, bir Transaction yapısının girdilerinin geçerli olup olmadığını döndürür, bkz. VerifyInputs.
+func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
+	return tx.VerifyInputs(prevTXs) == nil
 }
 
-// TrimmedCopy fonksiyonu, Transaction yapısının bir kopyasını oluşturur ve girdileri ve çıktıları temizler.
+// TrimmedCopy fonksiyonu, Transaction yapısının kilit açma betikleri silinmiş bir kopyasını oluşturur.
 // Temizlenmiş kopya, işlemi imzalamak veya doğrulamak için kullanılırken orijinal Transaction yapısını değiştirmez.
 func (tx *Transaction) TrimmedCopy() Transaction {
 	var inputs []TxInput   // Boş bir TxInput (girdi) dizisi oluşturulur
@@ -219,16 +404,16 @@ func (tx *Transaction) TrimmedCopy() Transaction {
 
 	// Orijinal işlemin girdilerini temizlenmiş kopyaya ekler
 	for _, in := range tx.Inputs {
-		inputs = append(inputs, TxInput{in.ID, in.Out, nil, nil}) // Girdinin sadece ID ve Out değerlerini kopyaya ekler
+		inputs = append(inputs, TxInput{in.ID, in.Out, nil, in.Sequence}) // Girdinin kilit açma betiği dışındaki alanlarını kopyaya ekler
 	}
 
 	// Orijinal işlemin çıktılarını temizlenmiş kopyaya ekler
 	for _, out := range tx.Outputs {
-		outputs = append(outputs, TxOutput{out.Value, out.PublicKey}) // Çıktının sadece Value ve PublicKey değerlerini kopyaya ekler
+		outputs = append(outputs, TxOutput{out.Value, out.ScriptPubKey}) // Çıktının Value ve ScriptPubKey değerlerini kopyaya ekler
 	}
 
 	// Temizlenmiş kopya Transaction yapısını oluşturur
-	txCopy := Transaction{tx.ID, inputs, outputs}
+	txCopy := Transaction{tx.Version, tx.ID, inputs, outputs, tx.LockTime}
 
 	return txCopy // Oluşturulan temizlenmiş kopyayı döndürür
 }
@@ -244,14 +429,20 @@ func (tx Transaction) String() string {
 
 		lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     TXID:     %x\033[0m", input.ID))
 		lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     Out:      %d\033[0m", input.Out))
-		lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     Signature:%x\033[0m", input.Signature))
-		lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     PubKey:   %x\033[0m", input.PubKey))
+		lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     Script:   %s\033[0m", input.ScriptSig))
+		if input.Sequence != SequenceFinal {
+			lines = append(lines, fmt.Sprintf("\033[97m║\033[33m  ║     Sequence: %#08x\033[0m", input.Sequence))
+		}
 	}
 
 	for i, output := range tx.Outputs {
 		lines = append(lines, fmt.Sprintf("\033[97m║\033[34m  ║   Output %d:\033[0m", i))
 		lines = append(lines, fmt.Sprintf("\033[97m║\033[36m  ║     Value:  %d\033[0m", output.Value))
-		lines = append(lines, fmt.Sprintf("\033[97m║\033[36m  ║     Script: %x\033[0m", output.PublicKey))
+		lines = append(lines, fmt.Sprintf("\033[97m║\033[36m  ║     Script: %s\033[0m", output.ScriptPubKey))
+	}
+
+	if tx.LockTime != 0 {
+		lines = append(lines, fmt.Sprintf("\033[97m║\033[35m  ║   LockTime: %d\033[0m", tx.LockTime))
 	}
 
 	lines = append(lines, fmt.Sprintf("\033[97m║\033[35m  ╚═══════════════════════════════════════════════════════════════════════════════════\033[0m"))
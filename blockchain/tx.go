@@ -3,50 +3,60 @@ package blockchain
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
+
 	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
 )
 
 type TxOutput struct { //transectıon cıktıları
-	Value     int    //token degeri
-	PublicKey []byte // public key hash
+	Value        int    //token degeri
+	ScriptPubKey Script //kilitleme betiği, çıktıyı harcamak için saglanması gereken kosul
 }
 
 type TxInput struct { //transectıon girdileri
 	ID        []byte //cıkısı referans eder
 	Out       int    //cıkıs endexı  referans eder
-	Signature []byte // imza
-	PubKey    []byte // public key
+	ScriptSig Script //kilit açma betiği (P2PKH icin imza ve public key), coinbase'de serbest veri
+	Sequence  uint32 //goreli kilit, bkz. locktime.go; SequenceFinal ise kilit yoktur
 }
 
 type TxOutputs struct {
 	Outputs []TxOutput
 }
 
-// UsesKey fonksiyonu, TxInput yapısının bir public key hash kodunu kullanıp kullanmadığını kontrol eder.
+// UsesKey fonksiyonu, girdinin kilit açma betiğindeki public key'in hash'inin pubKeyHash olup olmadığını kontrol eder.
 func (in *TxInput) UsesKey(pubKeyHash []byte) bool {
-	// TxInput içindeki PubKey bilgisinden elde edilen public key hash'i alır.
-	lockingHash := wallet.PublicKeyHash(in.PubKey)
+	// P2PKH kilit açma betiği <imza> <public key> verilerinden oluşur
+	data, err := in.ScriptSig.PushedData()
+	if err != nil || len(data) != 2 {
+		return false
+	}
+	lockingHash := wallet.PublicKeyHash(data[1])
 
 	// Elde edilen public key hash'i, verilen pubKeyHash ile karşılaştırır ve eşit olup olmadığını kontrol eder.
 	return bytes.Compare(lockingHash, pubKeyHash) == 0
 }
 
-// Lock fonksiyonu, TxOutput yapısını belirtilen bir adresle kilitleyerek (lock) TxOutput'un PublicKey alanını ayarlar.
+// Lock fonksiyonu, TxOutput yapısını belirtilen bir adrese ödeme yapan betikle kilitler:
+// cüzdan adresleri için P2PKH, betik adresleri için P2SH. Adres önceden doğrulanmış olmalıdır.
 func (out *TxOutput) Lock(address []byte) {
-	// Verilen adresi Base58Decode fonksiyonu ile byte dizisine dönüştürür.
-	pubKeyHash := wallet.Base58Decode(address)
-
-	// Dönüştürülen byte dizisinden, versiyon (version) ve checksum kodunu çıkarır.
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]
-
-	// Elde edilen byte dizisini TxOutput'un PublicKey alanına atar, bu alanı public key hash kodu olarak ayarlar.
-	out.PublicKey = pubKeyHash
+	// Verilen adresten sürüm baytını ve hash'i alır.
+	version, hash, _ := wallet.DecodeAddress(string(address))
+
+	if version == wallet.ScriptAddressVersion {
+		// Çıktı, betik hash'ine ödeme yapan standart betikle kilitlenir.
+		out.ScriptPubKey = PayToScriptHashScript(hash)
+		return
+	}
+	// Çıktı, public key hash'ine ödeme yapan standart betikle kilitlenir.
+	out.ScriptPubKey = PayToPubKeyHashScript(hash)
 }
 
-// IsLockedWithKey fonksiyonu, TxOutput yapısının belirtilen bir public key hash koduyla kilidinin kontrol edilmesini sağlar.
+// IsLockedWithKey fonksiyonu, çıktının pubKeyHash'e ödeme yapan bir P2PKH betiğiyle kilitlenip kilitlenmediğini kontrol eder.
 func (out *TxOutput) IsLockedWithKey(pubKeyHash []byte) bool {
-	// TxOutput'un PublicKey alanını, verilen pubKeyHash ile karşılaştırır ve eşit olup olmadığını kontrol eder.
-	return bytes.Compare(out.PublicKey, pubKeyHash) == 0
+	// Kilitleme betiğinin P2PKH hash'ini, verilen pubKeyHash ile karşılaştırır ve eşit olup olmadığını kontrol eder.
+	hash, ok := out.ScriptPubKey.PubKeyHash()
+	return ok && bytes.Compare(hash, pubKeyHash) == 0
 }
 
 // NewTXOutput fonksiyonu, bir token değeri (value) ve bir adresi (address) alarak yeni bir TxOutput yapısı oluşturur.
@@ -54,29 +64,166 @@ func NewTXOutput(value int, address string) *TxOutput {
 	// Yeni bir TxOutput yapısı oluşturulur.
 	txo := &TxOutput{value, nil}
 
-	// Verilen adresi byte dizisine dönüştürerek ve bu adresle TxOutput'u kilitleyerek (lock) PublicKey alanını ayarlar.
+	// Verilen adresle TxOutput'u kilitleyerek (lock) ScriptPubKey alanını ayarlar.
 	txo.Lock([]byte(address))
 
 	// Hazırlanan TxOutput yapısını döndürür.
 	return txo
 }
 
-// Serialize fonksiyonu, TxOutput yapısını byte dizisine dönüştürür.
+// Betiklerden (TxVersion 2) önce girdiler imzayı ve public key'i Signature ile PubKey alanlarında,
+// çıktılar ise alıcının public key hash'ini PublicKey alanında tutuyordu. Eski işlemler okunurken bu
+// alanlar standart P2PKH betiklerine çevrilir; ID'leri ve imzaları hesaplanırken aynı alanlara kayıpsız
+// geri çevrilir (bkz. legacyInputFields ve legacyOutputHash).
+
+// legacyInputScript fonksiyonu, eski bir girdinin alanlarını kilit açma betiğine çevirir: <signature> <pubKey>
+func legacyInputScript(signature, pubKey []byte) Script {
+	return PubKeyHashSigScript(signature, pubKey)
+}
+
+// legacyInputFields fonksiyonu, legacyInputScript ile oluşturulmuş bir betikten eski girdi alanlarını geri alır.
+// Betik iki veri eklemesinden oluşmuyorsa ikinci değer false olur; eksik veriler boş kabul edilir.
+func legacyInputFields(script Script) (signature, pubKey []byte, ok bool) {
+	data, err := script.PushedData()
+	if err != nil || len(data) > 2 {
+		return nil, nil, false
+	}
+	if len(data) > 0 {
+		signature = data[0]
+	}
+	if len(data) > 1 {
+		pubKey = data[1]
+	}
+	return signature, pubKey, true
+}
+
+// legacyOutputHash fonksiyonu, eski bir çıktının PublicKey alanını P2PKH betiğinden geri alır.
+func legacyOutputHash(script Script) ([]byte, bool) {
+	return script.payToPubKeyHashData()
+}
+
+// isLegacyEncodable fonksiyonu, işlemin girdi ve çıktılarının eski (TxVersion 2 öncesi) alanlarla
+// kayıpsız yazılıp yazılamayacağını döndürür.
+func (tx *Transaction) isLegacyEncodable() bool {
+	for _, in := range tx.Inputs {
+		if _, _, ok := legacyInputFields(in.ScriptSig); !ok {
+			return false
+		}
+	}
+	for _, out := range tx.Outputs {
+		if _, ok := legacyOutputHash(out.ScriptPubKey); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// gobInput, gob ile yazılmış girdileri okumak için kullanılır; eski ve yeni alanların ikisini de tanır.
+type gobInput struct {
+	ID        []byte
+	Out       int
+	Signature []byte
+	PubKey    []byte
+	ScriptSig Script
+}
+
+func (in gobInput) input() TxInput {
+	if in.ScriptSig == nil {
+		return TxInput{in.ID, in.Out, legacyInputScript(in.Signature, in.PubKey), SequenceFinal}
+	}
+	return TxInput{in.ID, in.Out, in.ScriptSig, SequenceFinal}
+}
+
+// gobOutput, gob ile yazılmış çıktıları (eski UTXO kayıtları, geri alma kayıtları) okumak için kullanılır.
+type gobOutput struct {
+	Value        int
+	PublicKey    []byte
+	ScriptPubKey Script
+}
+
+func (out gobOutput) output() TxOutput {
+	if out.ScriptPubKey == nil && out.PublicKey != nil {
+		return TxOutput{out.Value, PayToPubKeyHashScript(out.PublicKey)}
+	}
+	return TxOutput{out.Value, out.ScriptPubKey}
+}
+
+// gobTransaction, kanonik kodlamadan önce gob ile yazılmış işlemleri okumak için kullanılır.
+type gobTransaction struct {
+	ID      []byte
+	Inputs  []gobInput
+	Outputs []gobOutput
+}
+
+func (tx gobTransaction) transaction() Transaction {
+	transaction := Transaction{ID: tx.ID} // gob ile yazılan işlemlerin sürümü 0'dır
+	for _, in := range tx.Inputs {
+		transaction.Inputs = append(transaction.Inputs, in.input())
+	}
+	for _, out := range tx.Outputs {
+		transaction.Outputs = append(transaction.Outputs, out.output())
+	}
+	return transaction
+}
+
+// Serialize fonksiyonu, tek bir TxOutput yapısını kanonik olarak byte dizisine dönüştürür.
+func (out TxOutput) Serialize() []byte {
+	var e encoder
+	out.encode(&e, TxVersion)
+	return e.bytes()
+}
+
+// DeserializeOutput fonksiyonu, byte dizisini tek bir TxOutput yapısına dönüştürür.
+// Kanonik kodlamadan önce gob ile yazılmış UTXO kayıtları da okunur.
+func DeserializeOutput(data []byte) (TxOutput, error) {
+	var output TxOutput
+
+	if isGob(data) {
+		var legacy gobOutput
+		decode := gob.NewDecoder(bytes.NewReader(data))
+		if err := decode.Decode(&legacy); err != nil {
+			return output, fmt.Errorf("%w: output: %v", ErrMalformedEncoding, err)
+		}
+		return legacy.output(), nil
+	}
+
+	d := newDecoder(data)
+	output = decodeOutput(d, TxVersion)
+
+	return output, d.finish()
+}
+
+// Serialize fonksiyonu, TxOutputs yapısını kanonik olarak byte dizisine dönüştürür: varint(çıktı sayısı) || çıktılar.
 func (outs TxOutputs) Serialize() []byte {
-	var buffer bytes.Buffer // byte dizisi oluşturulur
-	encode := gob.NewEncoder(&buffer)
-	err := encode.Encode(outs)
-	Handle(err)
-	return buffer.Bytes()
+	var e encoder
+	e.varint(uint64(len(outs.Outputs)))
+	for _, out := range outs.Outputs {
+		out.encode(&e, TxVersion)
+	}
+	return e.bytes()
 }
 
-// DeserializeOutputs fonksiyonu, byte dizisini TxOutput yapısına dönüştürür.
-func DeserializeOutputs(data []byte) TxOutputs {
+// DeserializeOutputs fonksiyonu, byte dizisini TxOutput yapısına dönüştürür.
+// Eski sürümlerin gob ile yazdığı "utxo-" kayıtları da okunur.
+func DeserializeOutputs(data []byte) (TxOutputs, error) {
 	var outputs TxOutputs // TxOutput yapısı oluşturulur
 
-	decode := gob.NewDecoder(bytes.NewReader(data))
-	err := decode.Decode(&outputs) // byte dizisini TxOutput yapısına dönüştürür
-	Handle(err)
-
-	return outputs
+	if isGob(data) {
+		var legacy struct{ Outputs []gobOutput }
+		decode := gob.NewDecoder(bytes.NewReader(data))
+		if err := decode.Decode(&legacy); err != nil { // byte dizisini TxOutput yapısına dönüştürür
+			return outputs, fmt.Errorf("%w: outputs: %v", ErrMalformedEncoding, err)
+		}
+		for _, out := range legacy.Outputs {
+			outputs.Outputs = append(outputs.Outputs, out.output())
+		}
+		return outputs, nil
+	}
+
+	d := newDecoder(data)
+	for i, n := 0, d.count(8+1); i < n && d.err == nil; i++ {
+		outputs.Outputs = append(outputs.Outputs, decodeOutput(d, TxVersion))
+	}
+
+	return outputs, d.finish()
 }
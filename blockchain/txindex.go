@@ -0,0 +1,158 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+)
+
+// Ana zincirdeki her işlemin hangi blokta ve blok içinde hangi sırada olduğu işlem indeksinde,
+// harcanan her çıktının hangi işlem tarafından harcandığı harcama indeksinde tutulur (bkz. store.go).
+// İndeksler bloklar zincire bağlanırken yazılır, zincirden çıkarılırken silinir.
+
+// TxLocation, bir işlemin ana zincirdeki konumunu tutar.
+type TxLocation struct {
+	BlockHash []byte // işlemi içeren bloğun hash'i
+	Index     int    // işlemin blok içindeki sırası
+}
+
+// Serialize fonksiyonu, TxLocation yapısını byte dizisine dönüştürür.
+func (loc TxLocation) Serialize() []byte {
+	var buffer bytes.Buffer
+	encode := gob.NewEncoder(&buffer)
+	if err := encode.Encode(loc); err != nil {
+		log.Panic(err) // sabit bir yapının kodlanması yalnızca programlama hatasında başarısız olur
+	}
+	return buffer.Bytes()
+}
+
+// DeserializeTxLocation fonksiyonu, byte dizisini TxLocation yapısına dönüştürür.
+func DeserializeTxLocation(data []byte) (TxLocation, error) {
+	var loc TxLocation
+
+	decode := gob.NewDecoder(bytes.NewReader(data))
+	if err := decode.Decode(&loc); err != nil {
+		return loc, fmt.Errorf("%w: tx location: %v", ErrMalformedEncoding, err)
+	}
+
+	return loc, nil
+}
+
+// indexTransactions fonksiyonu, bloğun işlemlerini işlem indeksine, harcadıkları çıktıları harcama indeksine ekler.
+func indexTransactions(txn StoreTxn, block *Block) error {
+	for i, tx := range block.Transactions {
+		if err := txn.PutTxLocation(tx.ID, TxLocation{block.Hash, i}); err != nil {
+			return err
+		}
+		if tx.IsCoinbase() {
+			continue
+		}
+		for _, in := range tx.Inputs {
+			if err := txn.PutSpendingTx(in.ID, in.Out, tx.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unindexTransactions fonksiyonu, bloğun işlemlerini işlem indeksinden, harcadıkları çıktıları harcama indeksinden siler.
+func unindexTransactions(txn StoreTxn, block *Block) error {
+	for _, tx := range block.Transactions {
+		if err := txn.DeleteTxLocation(tx.ID); err != nil {
+			return err
+		}
+		if tx.IsCoinbase() {
+			continue
+		}
+		for _, in := range tx.Inputs {
+			if err := txn.DeleteSpendingTx(in.ID, in.Out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findTxLocation fonksiyonu, işlem indeksinden işlemin konumunu okur.
+func (chain *BlockChain) findTxLocation(ID []byte) (TxLocation, error) {
+	var loc TxLocation
+
+	err := chain.Store.View(func(txn StoreTxn) error {
+		var err error
+		loc, err = txn.TxLocation(ID)
+		return err
+	})
+
+	return loc, err
+}
+
+// findSpendingTxID fonksiyonu, harcama indeksinden ID işleminin out numaralı çıktısını harcayan işlemin ID'sini okur.
+func (chain *BlockChain) findSpendingTxID(ID []byte, out int) ([]byte, error) {
+	var spender []byte
+
+	err := chain.Store.View(func(txn StoreTxn) error {
+		var err error
+		spender, err = txn.SpendingTx(ID, out)
+		return err
+	})
+
+	return spender, err
+}
+
+// spendIndexKey, harcama indeksinin tutulduğunu belirten kayıttır. Anahtar, store.go içindeki bölüm
+// öneklerinden biriyle başlamamalıdır.
+const spendIndexKey = "spendindex"
+
+// MigrateSpendIndex fonksiyonu, harcama indeksi tutulmadan önce oluşturulmuş veritabanlarında indeksi
+// ReindexTransactions ile ana zincirden oluşturur. İndeks zaten tutuluyorsa hiçbir şey yapmaz ve false döner.
+func (chain *BlockChain) MigrateSpendIndex() (bool, error) {
+	var value []byte
+	err := chain.Store.View(func(txn StoreTxn) error {
+		var err error
+		value, err = txn.Meta(spendIndexKey)
+		return err
+	})
+	if err != nil || value != nil {
+		return false, err
+	}
+
+	_, err = chain.ReindexTransactions()
+	return err == nil, err
+}
+
+// ReindexTransactions fonksiyonu, işlem ve harcama indekslerini silip ana zincirden yeniden oluşturur.
+// İndeks tutulmadan önce oluşturulmuş veritabanlarını doldurmak için kullanılır.
+// İndekslenen işlem sayısını döndürür.
+func (chain *BlockChain) ReindexTransactions() (int, error) {
+	for _, section := range []StoreSection{SectionTxIndex, SectionSpendIndex} {
+		if err := chain.Store.Clear(section); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	iter := chain.Iterator()
+
+	for {
+		block, err := iter.Next()
+		if err != nil {
+			return count, err
+		}
+
+		err = chain.Store.Update(func(txn StoreTxn) error {
+			return indexTransactions(txn, block)
+		})
+		if err != nil {
+			return count, err
+		}
+		count += len(block.Transactions)
+
+		if len(block.PrevHash) == 0 {
+			return count, chain.Store.Update(func(txn StoreTxn) error {
+				return txn.SetMeta(spendIndexKey, []byte{1})
+			})
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// ReindexTransactions işlem ve harcama indekslerini yalnızca ana zincirin işlemleriyle yeniden oluşturur:
+// silinmiş ya da bozulmuş kayıtlar düzelir, yan daldaki işlemler indekse girmez.
+func TestReindexTransactions(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay, err := NewTransaction(w, string(to.Address()), 7, 1, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := addForkBlock(t, chain, &genesis, w, "a1", pay)
+	a2 := addForkBlock(t, chain, a1, w, "a2")
+	sideSpend, err := NewTransaction(to, string(w.Address()), 3, 0, 0, u) // pay'in 0. çıktısını harcar
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := addForkBlock(t, chain, a1, to, "side", sideSpend) // eşit iş, zincir ucu a2'de kalır
+
+	mainTXs := []*Transaction{genesis.Transactions[0], a1.Transactions[0], pay, a2.Transactions[0]}
+
+	// Kayıtlardan biri silinir, biri yanlış bloğu gösterecek şekilde bozulur
+	err = chain.Store.Update(func(txn StoreTxn) error {
+		if err := txn.DeleteTxLocation(pay.ID); err != nil {
+			return err
+		}
+		if err := txn.DeleteSpendingTx(pay.Inputs[0].ID, pay.Inputs[0].Out); err != nil {
+			return err
+		}
+		return txn.PutTxLocation(a2.Transactions[0].ID, TxLocation{a1.Hash, 1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.FindTransaction(pay.ID); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindTransaction of the deleted entry = %v, want ErrTxNotFound", err)
+	}
+	if _, err := chain.FindTransaction(a2.Transactions[0].ID); !errors.Is(err, ErrTxIndexCorrupt) {
+		t.Fatalf("FindTransaction of the corrupt entry = %v, want ErrTxIndexCorrupt", err)
+	}
+
+	count, err := chain.ReindexTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(mainTXs) {
+		t.Fatalf("ReindexTransactions indexed %d transactions, want %d", count, len(mainTXs))
+	}
+
+	for _, tx := range mainTXs {
+		found, err := chain.FindTransaction(tx.ID)
+		if err != nil {
+			t.Fatalf("FindTransaction(%x): %v", tx.ID, err)
+		}
+		if !bytes.Equal(found.ID, tx.ID) {
+			t.Fatalf("FindTransaction(%x) returned %x", tx.ID, found.ID)
+		}
+	}
+	if _, err := chain.FindTransaction(side.Transactions[0].ID); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindTransaction of a side branch transaction = %v, want ErrTxNotFound", err)
+	}
+
+	spending, err := chain.FindSpendingTransaction(pay.Inputs[0].ID, pay.Inputs[0].Out)
+	if err != nil || !bytes.Equal(spending.ID, pay.ID) {
+		t.Fatalf("FindSpendingTransaction of the genesis output = %x, %v, want %x", spending.ID, err, pay.ID)
+	}
+	if _, err := chain.FindSpendingTransaction(pay.ID, 0); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("FindSpendingTransaction of an output spent on a side branch = %v, want ErrTxNotFound", err)
+	}
+
+	// Harcama indeksi olmayan eski bir veritabanı açılışta bir kez yeniden indekslenir
+	if migrated, err := chain.MigrateSpendIndex(); err != nil || migrated {
+		t.Fatalf("MigrateSpendIndex on an indexed chain = %v, %v", migrated, err)
+	}
+	if err := chain.Store.Clear(SectionSpendIndex); err != nil {
+		t.Fatal(err)
+	}
+	delete(chain.Store.(kvStore).kv.(*memoryBackend).data, spendIndexKey)
+	if migrated, err := chain.MigrateSpendIndex(); err != nil || !migrated {
+		t.Fatalf("MigrateSpendIndex = %v, %v", migrated, err)
+	}
+	if spending, err := chain.FindSpendingTransaction(pay.Inputs[0].ID, pay.Inputs[0].Out); err != nil || !bytes.Equal(spending.ID, pay.ID) {
+		t.Fatalf("FindSpendingTransaction after the migration = %x, %v, want %x", spending.ID, err, pay.ID)
+	}
+}
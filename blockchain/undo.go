@@ -0,0 +1,103 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"log"
+)
+
+// Her bloğun UTXO setine uygulanmasıyla harcanan çıktılar bloğun geri alma kaydında saklanır.
+// UTXOSet.Revert bu kayıtları kullanarak bloğu zincirin geri kalanını taramadan geri alır.
+
+// SpentOutput, bir blok tarafından harcanan tek bir çıktıyı ve harcanmadan önceki konumunu tutar.
+type SpentOutput struct {
+	TxID   []byte   // çıktıyı üreten işlemin ID'si
+	Out    int      // çıktının işlemdeki asıl indeksi
+	Output TxOutput // harcanan çıktının kendisi
+}
+
+// UndoRecord, bir bloğun harcadığı tüm çıktıları harcanma sırasıyla tutar.
+type UndoRecord struct {
+	Spent []SpentOutput
+}
+
+// Serialize fonksiyonu, UndoRecord yapısını byte dizisine dönüştürür.
+func (r UndoRecord) Serialize() []byte {
+	var buffer bytes.Buffer
+	encode := gob.NewEncoder(&buffer)
+	if err := encode.Encode(r); err != nil {
+		log.Panic(err) // sabit bir yapının kodlanması yalnızca programlama hatasında başarısız olur
+	}
+	return buffer.Bytes()
+}
+
+// DeserializeUndoRecord fonksiyonu, byte dizisini UndoRecord yapısına dönüştürür.
+// Betiklerden önce yazılmış kayıtlardaki çıktılar P2PKH betiklerine çevrilir.
+func DeserializeUndoRecord(data []byte) (UndoRecord, error) {
+	var record UndoRecord
+	var stored struct {
+		Spent []struct {
+			TxID   []byte
+			Out    int
+			Output gobOutput
+		}
+	}
+
+	decode := gob.NewDecoder(bytes.NewReader(data))
+	if err := decode.Decode(&stored); err != nil {
+		return record, fmt.Errorf("%w: undo record: %v", ErrMalformedEncoding, err)
+	}
+
+	for _, spent := range stored.Spent {
+		record.Spent = append(record.Spent, SpentOutput{spent.TxID, spent.Out, spent.Output.output()})
+	}
+	return record, nil
+}
+
+// rebuildUndoRecords fonksiyonu, ana zincirdeki tüm bloklar için geri alma kayıtlarını zincirden
+// yeniden oluşturur. Harcanan her çıktı, onu üreten işlemden asıl indeksi ile alınır.
+func (u UTXOSet) rebuildUndoRecords() error {
+	var blocks []*Block
+	txs := make(map[string]*Transaction)
+
+	iter := u.Blockchain.Iterator()
+	for {
+		block, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+		for _, tx := range block.Transactions {
+			txs[hex.EncodeToString(tx.ID)] = tx
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	return u.Blockchain.Store.Update(func(txn StoreTxn) error {
+		for _, block := range blocks {
+			undo := UndoRecord{}
+			for _, tx := range block.Transactions {
+				if tx.IsCoinbase() {
+					continue
+				}
+				for _, in := range tx.Inputs {
+					prevTX, ok := txs[hex.EncodeToString(in.ID)]
+					if !ok || in.Out < 0 || in.Out >= len(prevTX.Outputs) {
+						continue
+					}
+					undo.Spent = append(undo.Spent, SpentOutput{in.ID, in.Out, prevTX.Outputs[in.Out]})
+				}
+			}
+
+			if err := txn.PutUndo(block.Hash, undo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
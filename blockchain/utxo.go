@@ -3,317 +3,349 @@ package blockchain
 import (
 	"bytes"
 	"encoding/hex"
-	"github.com/dgraph-io/badger"
-	"log"
-)
-
-var (
-	utxoPrefix   = []byte("utxo-")
-	prefixLength = len(utxoPrefix)
+	"errors"
+	"fmt"
 )
 
+// UTXO seti her harcanmamış çıktıyı (txid, vout) çifti ile ayrı bir kayıtta tutar (bkz. store.go).
+// vout, çıktının işlemdeki asıl indeksidir; böylece bir işlemin bazı çıktıları harcandığında
+// diğerlerinin indeksleri kaymaz. Harcanamaz çıktılar (bkz. Script.IsUnspendable) sete hiç yazılmaz.
+// Eski sürümlerde bir işlemin tüm çıktıları "utxo-<txid>" anahtarında liste olarak tutuluyordu.
 type UTXOSet struct {
 	Blockchain *BlockChain
 }
 
 // FindSpendableOutputs, belirtilen bir adrese gönderilmiş ve henüz harcanmamış çıktıları (UTXO'ları) bulmak için kullanılır.
 // Ayrıca, bu çıktılar aracılığıyla belirli bir miktar token transfer edilebilecek çıktıları belirler.
-func (u UTXOSet) FindSpendableOutputs(pubKeyHash []byte, amount int) (int, map[string][]int) {
+func (u UTXOSet) FindSpendableOutputs(pubKeyHash []byte, amount int) (int, map[string][]int, error) {
+	// Çıkışın bu anahtarla kilidini kontrol ediyoruz
+	return u.findSpendable(func(out TxOutput) bool { return out.IsLockedWithKey(pubKeyHash) }, amount)
+}
+
+// FindSpendableScriptOutputs fonksiyonu, FindSpendableOutputs gibi çalışır; kilitleme betiği script'e
+// birebir eşit olan çıktılardan amount kadarını karşılayanları bulur.
+func (u UTXOSet) FindSpendableScriptOutputs(script Script, amount int) (int, map[string][]int, error) {
+	return u.findSpendable(func(out TxOutput) bool { return bytes.Equal(out.ScriptPubKey, script) }, amount)
+}
+
+// findSpendable fonksiyonu, match'e uyan çıktıları toplamları amount'a ulaşana kadar toplar.
+func (u UTXOSet) findSpendable(match func(TxOutput) bool, amount int) (int, map[string][]int, error) {
 	// Kullanılmamış çıkışları saklamak için bir harita oluşturuyoruz
 	unspentOuts := make(map[string][]int)
 	// Toplam biriktirilen miktarı izlemek için bir değişken tanımlıyoruz
 	accumulated := 0
-	// Veritabanına erişim için Blockchain'in veritabanı bağlantısını alıyoruz
-	db := u.Blockchain.Database
-
-	// Veritabanında sorgu yapmak için bir View işlevi başlatıyoruz
-	err := db.View(func(txn *badger.Txn) error {
-		// Badger için varsayılan Iterator seçeneklerini alıyoruz
-		opts := badger.DefaultIteratorOptions
-
-		// İteratör oluşturuyoruz ve işlem bittikten sonra kapatmayı unutmuyoruz
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		// UTXO önekine göre arama yapmaya başlıyoruz
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			// İteratörden bir öğe alıyoruz
-			item := it.Item()
-			k := item.Key()
-			v, err := item.ValueCopy(nil)
-			Handle(err)                         // Hata durumunu yönetim işlevi ile ele alıyoruz
-			k = bytes.TrimPrefix(k, utxoPrefix) // Önekten kurtuluyoruz
-			txID := hex.EncodeToString(k)       // Transaction ID'yi hex formatına dönüştürüyoruz
-			outs := DeserializeOutputs(v)       // Çıkışları Deserialize ediyoruz
-
-			// Çıkışları döngüye alarak kontrol ediyoruz
-			for outIdx, out := range outs.Outputs {
-				// Çıkışın bu anahtarla kilidini kontrol ediyoruz ve istenen miktardan azsa ekliyoruz
-				if out.IsLockedWithKey(pubKeyHash) && accumulated < amount {
-					accumulated += out.Value
-					unspentOuts[txID] = append(unspentOuts[txID], outIdx)
-				}
+
+	// Zincirin store'unda sorgu yapmak için bir View işlevi başlatıyoruz
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		// UTXO setindeki her çıktıyı işlem ID'si ve çıktı indeksi ile dolaşıyoruz
+		return txn.ForEachOutput(func(id []byte, outIdx int, out TxOutput) error {
+			txID := hex.EncodeToString(id) // Transaction ID'yi hex formatına dönüştürüyoruz
+
+			// Çıkış koşula uyuyorsa ve istenen miktardan azsa ekliyoruz
+			if match(out) && accumulated < amount {
+				accumulated += out.Value
+				unspentOuts[txID] = append(unspentOuts[txID], outIdx)
 			}
-		}
-		return nil
+			return nil
+		})
 	})
-	Handle(err)                     // Hata durumunu yönetim işlevi ile ele alıyoruz
-	return accumulated, unspentOuts // Biriktirilen miktarı ve kullanılmamış çıkışları döndürüyoruz
+	if err != nil {
+		return 0, nil, err
+	}
+	return accumulated, unspentOuts, nil // Biriktirilen miktarı ve kullanılmamış çıkışları döndürüyoruz
 }
 
 // FindUTXO fonksiyonu, bir kripto para biriminin UTXO (Kullanılmamış İşlem Çıkışları) üzerinde, belirli bir anahtara (pubKeyHash) ait olan kullanılmamış çıkışları bulur.
-func (u UTXOSet) FindUTXO(pubKeyHash []byte) []TxOutput {
+func (u UTXOSet) FindUTXO(pubKeyHash []byte) ([]TxOutput, error) {
 	// Kullanılmamış işlem çıkışlarını tutacak bir slice oluşturuyoruz
 	var UTXOs []TxOutput
 
-	// Veritabanı bağlantısı için Blockchain'den veritabanı erişimini alıyoruz
-	db := u.Blockchain.Database
-
-	// Veritabanında sorgu yapmak için bir View işlevi başlatıyoruz
-	err := db.View(func(txn *badger.Txn) error {
-		// Badger için varsayılan Iterator seçeneklerini alıyoruz
-		opts := badger.DefaultIteratorOptions
-
-		// İteratör oluşturuyoruz ve işlem bittikten sonra kapatmayı unutmuyoruz
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		// UTXO önekine göre arama yapmaya başlıyoruz
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			// İteratörden bir öğe alıyoruz
-			item := it.Item()
-			v, err := item.ValueCopy(nil)
-			Handle(err)                   // Hata durumunu yönetim işlevi ile ele alıyoruz
-			outs := DeserializeOutputs(v) // Çıkışları Deserialize ediyoruz
-
-			// Çıkışları döngüye alarak kontrol ediyoruz
-			for _, out := range outs.Outputs {
-				// Çıkışın bu anahtarla kilidini kontrol ediyoruz
-				if out.IsLockedWithKey(pubKeyHash) {
-					UTXOs = append(UTXOs, out) // UTXOs slice'ına uygun çıkışı ekliyoruz
-				}
+	// Zincirin store'unda sorgu yapmak için bir View işlevi başlatıyoruz
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		return txn.ForEachOutput(func(_ []byte, _ int, out TxOutput) error {
+			// Çıkışın bu anahtarla kilidini kontrol ediyoruz
+			if out.IsLockedWithKey(pubKeyHash) {
+				UTXOs = append(UTXOs, out) // UTXOs slice'ına uygun çıkışı ekliyoruz
 			}
-		}
-
-		return nil
+			return nil
+		})
 	})
-	Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
+	if err != nil {
+		return nil, err
+	}
 
-	return UTXOs // Bulunan tüm uygun (locked with key) UTXO'ları döndürüyoruz
+	return UTXOs, nil // Bulunan tüm uygun (locked with key) UTXO'ları döndürüyoruz
 }
 
-// CountTransactions fonksiyonu, bir kripto para biriminin UTXO (Kullanılmamış İşlem Çıkışları) içindeki islemlerin sayısını döndürür.
-func (u UTXOSet) CountTransactions() int {
-	// Veritabanı bağlantısı için Blockchain'den veritabanı erişimini alıyoruz
-	db := u.Blockchain.Database
-
-	// İşlem sayısını tutacak bir sayaç başlatıyoruz
-	counter := 0
+// FindScriptUTXO fonksiyonu, kilitleme betiği script'e birebir eşit olan harcanmamış çıktıları bulur.
+// Bir adresin bakiyesi, AddressScript ile alınan betiğin çıktılarının toplamıdır.
+func (u UTXOSet) FindScriptUTXO(script Script) ([]TxOutput, error) {
+	var UTXOs []TxOutput
 
-	// Veritabanında sorgu yapmak için bir View işlevi başlatıyoruz
-	err := db.View(func(txn *badger.Txn) error {
-		// Badger için varsayılan Iterator seçeneklerini alıyoruz
-		opts := badger.DefaultIteratorOptions
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		return txn.ForEachOutput(func(_ []byte, _ int, out TxOutput) error {
+			if bytes.Equal(out.ScriptPubKey, script) {
+				UTXOs = append(UTXOs, out)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// İteratör oluşturuyoruz ve işlem bittikten sonra kapatmayı unutmuyoruz
-		it := txn.NewIterator(opts)
-		defer it.Close()
+	return UTXOs, nil
+}
 
-		// UTXO önekine göre arama yaparak işlem sayısını artırıyoruz
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			counter++
-		}
+// TotalValue fonksiyonu, UTXO setindeki tüm harcanmamış çıktıların toplam değerini döndürür.
+// İşlem ücretleri yalnızca el değiştirdiği için bu değer, zincirde şimdiye kadar üretilmiş token miktarından
+// harcanamaz çıktılarla yakılan miktarın çıkarılmasıyla elde edilene eşittir.
+func (u UTXOSet) TotalValue() (int, error) {
+	total := 0
 
-		return nil
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		return txn.ForEachOutput(func(_ []byte, _ int, out TxOutput) error {
+			total += out.Value
+			return nil
+		})
 	})
 
-	Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
+	return total, err
+}
+
+// CountTransactions fonksiyonu, bir kripto para biriminin UTXO (Kullanılmamış İşlem Çıkışları) içindeki islemlerin sayısını döndürür.
+func (u UTXOSet) CountTransactions() (int, error) {
+	// Harcanmamış çıktısı bulunan işlemleri tutacak bir küme oluşturuyoruz
+	txIDs := make(map[string]bool)
+
+	// Zincirin store'unda sorgu yapmak için bir View işlevi başlatıyoruz
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		// Aynı işlemin birden fazla çıktısı olabileceği için işlem ID'lerini sayıyoruz
+		return txn.ForEachOutput(func(txID []byte, _ int, _ TxOutput) error {
+			txIDs[hex.EncodeToString(txID)] = true
+			return nil
+		})
+	})
 
-	return counter // Toplam işlem sayısını döndürüyoruz
+	return len(txIDs), err // Toplam işlem sayısını döndürüyoruz
 }
 
 // Reindex fonksiyonu, UTXO (Kullanılmamış İşlem Çıkışları) haritasını yeniden doldurur. TXO setini yeniden indekslemek için kullanılır.
-func (u UTXOSet) Reindex() {
-	// Veritabanı bağlantısı için Blockchain'den veritabanı erişimini alıyoruz
-	db := u.Blockchain.Database
-
-	// UTXO setini yeniden indekslemek için önce mevcut önekle başlayan tüm verileri sileriz
-	u.DeleteByPrefix(utxoPrefix)
+func (u UTXOSet) Reindex() error {
+	// UTXO setini yeniden indekslemek için önce mevcut tüm çıktıları sileriz
+	if err := u.Blockchain.Store.Clear(SectionUTXO); err != nil {
+		return err
+	}
 
 	// Blockchain üzerindeki tüm UTXO'ları yeniden alıyoruz
-	UTXO := u.Blockchain.FindUTXO()
+	UTXO, err := u.Blockchain.FindUTXO()
+	if err != nil {
+		return err
+	}
 
-	// Veritabanında güncelleme işlemi başlatıyoruz
-	err := db.Update(func(txn *badger.Txn) error {
+	// Store'da güncelleme işlemi başlatıyoruz
+	return u.Blockchain.Store.Update(func(txn StoreTxn) error {
 		// Her bir transaction ID ve çıkışlar için UTXO haritasını döngüye alıyoruz
 		for txID, outs := range UTXO {
 			// Transaction ID'yi hex formatına dönüştürüyoruz
-			key, err := hex.DecodeString(txID)
+			id, err := hex.DecodeString(txID)
 			if err != nil {
 				return err
 			}
-			// UTXO öneki ile birleştirerek anahtarı oluşturuyoruz
-			key = append(utxoPrefix, key...)
 
-			// Anahtar ve değer (serialize edilmiş çıkışlar) çiftini veritabanına ekliyoruz
-			err = txn.Set(key, outs.Serialize())
-			Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
+			for outIdx, out := range outs {
+				// Her çıktıyı kendi (txid, vout) çifti ile ekliyoruz, harcanamaz çıktılar FindUTXO'da elenir
+				if err := txn.PutOutput(id, outIdx, out); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
 	})
-	Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
 }
 
-func (u *UTXOSet) Update(block *Block) {
-	// Veritabanı bağlantısı için Blockchain'den veritabanı erişimini alıyoruz
-	db := u.Blockchain.Database
-
-	// Veritabanında güncelleme işlemi başlatıyoruz
-	err := db.Update(func(txn *badger.Txn) error {
-		// Blok içindeki her bir işlemi döngüye alıyoruz
-		for _, tx := range block.Transactions {
-			// Coinbase işlemi değilse devam ediyoruz
-			if !tx.IsCoinbase() {
-				// İşlemdeki her bir girdiyi döngüye alıyoruz
-				for _, in := range tx.Inputs {
-					// Güncellenmiş çıkışları tutacak bir TxOutputs yapısı oluşturuyoruz
-					updatedOuts := TxOutputs{}
-					// Girdinin ID'sini alıp UTXO önekle birleştirerek anahtar oluşturuyoruz
-					inID := append(utxoPrefix, in.ID...)
-					// Veritabanından ilgili girdiye ait veriyi alıyoruz
-					item, err := txn.Get(inID)
-					Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
-					v, err := item.ValueCopy(nil)
-					Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
-
-					// Deserialize edilmiş çıkışları elde ediyoruz
-					outs := DeserializeOutputs(v)
-
-					// Çıkışları döngüye alarak güncellenmiş çıkışları oluşturuyoruz
-					for outIdx, out := range outs.Outputs {
-						if outIdx != in.Out {
-							updatedOuts.Outputs = append(updatedOuts.Outputs, out)
-						}
-					}
-
-					// Güncellenmiş çıkış listesi boşsa ilgili girdiyi veritabanından siliyoruz
-					if len(updatedOuts.Outputs) == 0 {
-						if err := txn.Delete(inID); err != nil {
-							log.Panic(err)
-						}
-					} else {
-						// Güncellenmiş çıkış listesi boş değilse veritabanına yeni değeri kaydediyoruz
-						if err := txn.Set(inID, updatedOuts.Serialize()); err != nil {
-							log.Panic(err)
-						}
-					}
+// Update fonksiyonu, bloğun işlemlerini UTXO setine uygular. Harcanan çıktılar bloğun geri alma
+// (undo) kaydına yazılır, böylece blok daha sonra Revert ile geri alınabilir.
+// Harcanan bir çıktı UTXO setinde yoksa ErrMissingInput'u saran bir hata döner ve set değişmez.
+func (u *UTXOSet) Update(block *Block) error {
+	// Store'da güncelleme işlemi başlatıyoruz
+	return u.Blockchain.Store.Update(func(txn StoreTxn) error {
+		return updateUTXO(txn, block)
+	})
+}
+
+// updateUTXO fonksiyonu, Update'in yaptığı değişiklikleri verilen veritabanı işlemi içinde yapar;
+// blok bağlanırken indeksler ve zincir ucu da aynı işlem içinde güncellenir.
+func updateUTXO(txn StoreTxn, block *Block) error {
+	// Blokta harcanan çıktıları geri alma kaydında topluyoruz
+	undo := UndoRecord{}
+
+	// Blok içindeki her bir işlemi döngüye alıyoruz
+	for _, tx := range block.Transactions {
+		// Coinbase işlemi değilse devam ediyoruz
+		if !tx.IsCoinbase() {
+			// İşlemdeki her bir girdiyi döngüye alıyoruz
+			for _, in := range tx.Inputs {
+				// Girdinin harcadığı çıktıyı alıyoruz
+				out, err := txn.Output(in.ID, in.Out)
+				if errors.Is(err, ErrOutputNotFound) {
+					return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+				}
+				if err != nil {
+					return err
 				}
-			}
 
-			// Yeni çıkışları tutacak bir TxOutputs yapısı oluşturuyoruz
-			newOutputs := TxOutputs{}
-			for _, out := range tx.Outputs {
-				newOutputs.Outputs = append(newOutputs.Outputs, out)
+				// Harcanan çıktıyı geri alma kaydına ekleyip UTXO setinden siliyoruz
+				undo.Spent = append(undo.Spent, SpentOutput{in.ID, in.Out, out})
+				if err := txn.DeleteOutput(in.ID, in.Out); err != nil {
+					return err
+				}
 			}
+		}
 
-			// İşlem ID'sini UTXO önekle birleştirerek anahtar oluşturuyoruz
-			txID := append(utxoPrefix, tx.ID...)
-			// Yeni çıkışları veritabanına kaydediyoruz
-			if err := txn.Set(txID, newOutputs.Serialize()); err != nil {
-				log.Panic(err)
+		// İşlemin her çıktısını kendi indeksi ile kaydediyoruz
+		for outIdx, out := range tx.Outputs {
+			if out.ScriptPubKey.IsUnspendable() {
+				continue // veri çıktıları hiçbir zaman harcanamaz, sette yer tutmaz
+			}
+			if err := txn.PutOutput(tx.ID, outIdx, out); err != nil {
+				return err
 			}
 		}
+	}
 
-		return nil
-	})
-	Handle(err) // Hata durumunu yönetim işlevi ile ele alıyoruz
+	// Geri alma kaydını bloğun hash'i ile saklıyoruz
+	return txn.PutUndo(block.Hash, undo)
 }
 
-// DeleteByPrefix, UTXOSet yapısına ait bir metottur ve belirli bir öneki taşıyan tüm anahtarları veritabanından siler.
-func (u *UTXOSet) DeleteByPrefix(prefix []byte) {
-	// deleteKeys fonksiyonu, belirli anahtarları silmek için kullanılır.
-	deleteKeys := func(keysForDelete [][]byte) error {
-		// Veritabanı işlemleri güncelleme modunda yapılır.
-		if err := u.Blockchain.Database.Update(func(txn *badger.Txn) error {
-			// Her bir anahtarı sil.
-			for _, key := range keysForDelete {
-				if err := txn.Delete(key); err != nil {
-					return err
-				}
-			}
+// hasOutput fonksiyonu, verilen işlemin out numaralı çıktısının UTXO setinde bulunup bulunmadığını kontrol eder.
+func (u UTXOSet) hasOutput(txID []byte, out int) (bool, error) {
+	found := false
+
+	err := u.Blockchain.Store.View(func(txn StoreTxn) error {
+		_, err := txn.Output(txID, out)
+		if errors.Is(err, ErrOutputNotFound) {
 			return nil
-		}); err != nil {
+		} else if err != nil {
 			return err
 		}
+		found = true
 		return nil
+	})
+
+	return found, err
+}
+
+// Revert fonksiyonu, Update fonksiyonunun bir blok için yaptığı değişiklikleri bloğun geri alma
+// kaydını kullanarak geri alır. Bloğun ürettiği çıktılar silinir, harcadığı çıktılar ise harcanmadan
+// önceki yerlerine geri yazılır; UTXO seti blok uygulanmadan önceki haline döner.
+// Bloğun geri alma kaydı yoksa ErrUndoNotFound'u saran bir hata döner.
+func (u *UTXOSet) Revert(block *Block) error {
+	return u.Blockchain.Store.Update(func(txn StoreTxn) error {
+		return revertUTXO(txn, block)
+	})
+}
+
+// revertUTXO fonksiyonu, Revert'in yaptığı değişiklikleri verilen veritabanı işlemi içinde yapar.
+func revertUTXO(txn StoreTxn, block *Block) error {
+	undo, err := txn.Undo(block.Hash)
+	if err != nil {
+		return err
 	}
 
-	// Anahtarların toplanacağı koleksiyon boyutu.
-	collectSize := 100000
-	// Veritabanında okuma işlemi için işlev.
-	u.Blockchain.Database.View(func(txn *badger.Txn) error {
-		// Iterator seçenekleri ayarlanır.
-		opts := badger.DefaultIteratorOptions
-		opts.PrefetchValues = false
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		// Silinecek anahtarlar için boş bir dilim oluşturulur.
-		keysForDelete := make([][]byte, 0, collectSize)
-		keysCollected := 0
-		// Belirtilen önek ile başlayan anahtarları bulur ve işler.
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			// Anahtarı kopyalar ve silinecek anahtarlar listesine ekler.
-			key := it.Item().KeyCopy(nil)
-			keysForDelete = append(keysForDelete, key)
-			keysCollected++
-			// Belirli bir koleksiyon boyutuna ulaşıldığında, bu anahtarları silme işlevini çağırır.
-			if keysCollected == collectSize {
-				if err := deleteKeys(keysForDelete); err != nil {
-					log.Panic(err)
-				}
-				// Yeni bir silinecek anahtarlar dilimi oluşturur.
-				keysForDelete = make([][]byte, 0, collectSize)
-				keysCollected = 0
+	// Bloğun ürettiği çıktılar UTXO setinden kaldırılır (sette olmayan harcanamaz çıktılar için silme etkisizdir)
+	blockTXs := make(map[string]bool)
+	for _, tx := range block.Transactions {
+		blockTXs[hex.EncodeToString(tx.ID)] = true
+		for outIdx := range tx.Outputs {
+			if err := txn.DeleteOutput(tx.ID, outIdx); err != nil {
+				return err
 			}
 		}
-		// Son toplama işlemi için kalan anahtarları silme işlevini çağırır.
-		if keysCollected > 0 {
-			if err := deleteKeys(keysForDelete); err != nil {
-				log.Panic(err)
-			}
+	}
+
+	// Harcanan çıktılar kendi (txid, vout) çiftlerine geri eklenir.
+	// Aynı blokta üretilip harcanan çıktılar blok öncesinde zaten yoktu, onlar atlanır.
+	for _, spent := range undo.Spent {
+		if blockTXs[hex.EncodeToString(spent.TxID)] {
+			continue
 		}
-		return nil
-	})
-}
+		if err := txn.PutOutput(spent.TxID, spent.Out, spent.Output); err != nil {
+			return err
+		}
+	}
 
-func (u UTXOSet) FindUnspentTransactions(pubKeyHash []byte) []TxOutput {
-	var UTXOs []TxOutput
+	return txn.DeleteUndo(block.Hash)
+}
 
-	db := u.Blockchain.Database
+// FindUnspentTransactions fonksiyonu, pubKeyHash'e kilitli harcanmamış çıktıları döndürür.
+func (u UTXOSet) FindUnspentTransactions(pubKeyHash []byte) ([]TxOutput, error) {
+	return u.FindUTXO(pubKeyHash)
+}
 
-	err := db.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
+// MigrateLegacy fonksiyonu, UTXO setinin eski "utxo-<txid>" düzeninde tutulduğu veritabanlarını
+// yeni (txid, vout) düzenine taşır. Eski kayıtlar çıktıların asıl indekslerini içermediğinden
+// dönüştürülmez; silinip UTXO seti ve geri alma kayıtları zincirden yeniden oluşturulur.
+// Taşıma yapıldıysa true döner.
+func (u UTXOSet) MigrateLegacy() (bool, error) {
+	store := u.Blockchain.Store
+	legacy := false
+
+	err := store.View(func(txn StoreTxn) error {
+		var err error
+		legacy, err = txn.HasLegacyOutputs()
+		return err
+	})
+	if err != nil || !legacy {
+		return false, err
+	}
 
-		it := txn.NewIterator(opts)
-		defer it.Close()
+	if err := store.Clear(SectionLegacyUTXO); err != nil {
+		return false, err
+	}
+	if err := store.Clear(SectionUndo); err != nil {
+		return false, err
+	}
+	if err := u.Reindex(); err != nil {
+		return false, err
+	}
+	if err := u.rebuildUndoRecords(); err != nil {
+		return false, err
+	}
 
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			item := it.Item()
-			v, err := item.ValueCopy(nil)
-			Handle(err)
-			outs := DeserializeOutputs(v)
-			for _, out := range outs.Outputs {
-				if out.IsLockedWithKey(pubKeyHash) {
-					UTXOs = append(UTXOs, out)
-				}
-			}
+	return true, nil
+}
 
-		}
-		return nil
+// utxoFormatKey, UTXO kayıtlarının çıktıları hangi biçimde tuttuğunu belirtir. Değeri utxoScriptFormat
+// olmayan veritabanlarındaki kayıtlar betiklerden (TxVersion 2) önce yazılmıştır.
+const utxoFormatKey = "outputformat" // anahtar, store.go içindeki bölüm öneklerinden biriyle başlamamalı
+
+// utxoScriptFormat, çıktıların kilitleme betikleriyle tutulduğu UTXO kayıt biçimidir.
+const utxoScriptFormat = "script"
+
+// MigrateScripts fonksiyonu, çıktıların public key hash'i ile tutulduğu eski UTXO kayıtlarını
+// kilitleme betikli biçime taşır. Kayıtlar biçimlerini belirtmediğinden UTXO seti zincirden yeniden
+// oluşturulur; geri alma kayıtları alan adlarıyla yazıldığı için olduğu gibi okunabilir.
+// Taşıma yapıldıysa true döner.
+func (u UTXOSet) MigrateScripts() (bool, error) {
+	store := u.Blockchain.Store
+	var format []byte
+
+	err := store.View(func(txn StoreTxn) error {
+		var err error
+		format, err = txn.Meta(utxoFormatKey)
+		return err
 	})
-	Handle(err)
+	if err != nil || string(format) == utxoScriptFormat {
+		return false, err
+	}
 
-	return UTXOs
+	if err := u.Reindex(); err != nil {
+		return false, err
+	}
+	err = store.Update(func(txn StoreTxn) error {
+		return txn.SetMeta(utxoFormatKey, []byte(utxoScriptFormat))
+	})
+	return err == nil, err
 }
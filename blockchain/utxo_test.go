@@ -0,0 +1,182 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Bir bloğu geri almak UTXO setini blok uygulanmadan önceki haline birebir döndürür: aynı blokta üretilip
+// harcanan ve veri çıktıları sete geri gelmez, bloğun harcadığı çıktılar kendi (txid, vout) çiftlerine döner.
+// Blok yeniden uygulandığında set tekrar aynı olur.
+func TestUTXOUpdateRevert(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+
+	pay, err := NewTransaction(w, string(to.Address()), 7, 0, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := NullDataScript([]byte("undo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chained := &Transaction{TxVersion, nil, []TxInput{{pay.ID, 0, nil, SequenceFinal}}, []TxOutput{*NewTXOutput(4, string(w.Address())), *NewTXOutput(3, string(to.Address())), {0, data}}, 0}
+	if err := chained.Sign(to.PrivateKey, map[string]Transaction{hex.EncodeToString(pay.ID): *pay}); err != nil {
+		t.Fatal(err)
+	}
+	chained.ID = chained.Hash()
+
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := utxoSnapshot(t, chain)
+	block := addForkBlock(t, chain, &genesis, w, "block", pay, chained) // MineBlock blok içindeki zinciri kabul etmez
+	after := utxoSnapshot(t, chain)
+	if reflect.DeepEqual(before, after) {
+		t.Fatal("mining the block did not change the UTXO set")
+	}
+	for _, key := range []string{fmt.Sprintf("%x:0", pay.ID), fmt.Sprintf("%x:2", chained.ID)} {
+		if _, ok := after[key]; ok {
+			t.Fatalf("output %s is in the UTXO set", key)
+		}
+	}
+
+	if err := u.Revert(block); err != nil {
+		t.Fatal(err)
+	}
+	if got := utxoSnapshot(t, chain); !reflect.DeepEqual(got, before) {
+		t.Fatalf("UTXO set after revert %v, want %v", got, before)
+	}
+	err = chain.Store.View(func(txn StoreTxn) error {
+		_, err := txn.Undo(block.Hash)
+		return err
+	})
+	if !errors.Is(err, ErrUndoNotFound) {
+		t.Fatalf("undo record after revert: %v", err)
+	}
+	if err := u.Revert(block); !errors.Is(err, ErrUndoNotFound) {
+		t.Fatalf("second Revert = %v, want ErrUndoNotFound", err)
+	}
+
+	if err := u.Update(block); err != nil {
+		t.Fatal(err)
+	}
+	if got := utxoSnapshot(t, chain); !reflect.DeepEqual(got, after) {
+		t.Fatalf("UTXO set after update %v, want %v", got, after)
+	}
+
+	// Girdileri harcanmış bir bloğu yeniden uygulamak reddedilir ve seti değiştirmez
+	if err := u.Update(block); !errors.Is(err, ErrMissingInput) {
+		t.Fatalf("second Update = %v, want ErrMissingInput", err)
+	}
+	if got := utxoSnapshot(t, chain); !reflect.DeepEqual(got, after) {
+		t.Fatalf("UTXO set after the rejected update %v, want %v", got, after)
+	}
+}
+
+// Eski "utxo-<txid>" düzenindeki bir veritabanı, yeni düzende tutulan setle aynı (txid, vout) çiftlerine
+// ve aynı geri alma kayıtlarına taşınır. Eski kayıtlar kısmen harcanmış işlemlerin kalan çıktılarını
+// asıl indeksleri olmadan tuttuğundan taşıma kayıtları dönüştürmez, zincirden yeniden oluşturur.
+func TestMigrateLegacyUTXO(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+
+	pay, err := NewTransaction(w, string(to.Address()), 7, 0, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{cb, pay}); err != nil {
+		t.Fatal(err)
+	}
+	back, err := NewTransaction(to, string(w.Address()), 3, 0, 0, u) // pay'in 0. çıktısını harcar, 1. çıktı kalır
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err = CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip, err := chain.MineBlock([]*Transaction{cb, back})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := utxoSnapshot(t, chain)
+	if _, ok := want[fmt.Sprintf("%x:1", pay.ID)]; !ok {
+		t.Fatalf("change output of %x is not in the UTXO set %v", pay.ID, want)
+	}
+	undo := make(map[string]UndoRecord)
+	legacy := make(map[string]TxOutputs)
+	err = chain.Store.View(func(txn StoreTxn) error {
+		for height := 1; height <= tip.Height; height++ {
+			hash, err := txn.BlockHash(height)
+			if err != nil {
+				return err
+			}
+			if undo[string(hash)], err = txn.Undo(hash); err != nil {
+				return err
+			}
+		}
+		return txn.ForEachOutput(func(txID []byte, out int, output TxOutput) error {
+			outs := legacy[string(txID)]
+			outs.Outputs = append(outs.Outputs, output)
+			legacy[string(txID)] = outs
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Veritabanı eski düzene çevrilir: çıktılar işlem başına indekssiz bir listede tutulur, geri alma kaydı yoktur
+	kv := chain.Store.(kvStore).kv.(*memoryBackend)
+	for key := range kv.data {
+		if strings.HasPrefix(key, string(utxoPrefix)) || strings.HasPrefix(key, string(undoPrefix)) {
+			delete(kv.data, key)
+		}
+	}
+	for txID, outs := range legacy {
+		kv.data[string(legacyUTXOPrefix)+txID] = outs.Serialize()
+	}
+
+	if migrated, err := u.MigrateLegacy(); err != nil || !migrated {
+		t.Fatalf("MigrateLegacy = %v, %v", migrated, err)
+	}
+	if got := utxoSnapshot(t, chain); !reflect.DeepEqual(got, want) {
+		t.Fatalf("migrated UTXO set %v, want %v", got, want)
+	}
+	err = chain.Store.View(func(txn StoreTxn) error {
+		if found, err := txn.HasLegacyOutputs(); err != nil || found {
+			return fmt.Errorf("legacy outputs left: %v", err)
+		}
+		for hash, record := range undo {
+			got, err := txn.Undo([]byte(hash))
+			if err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(got, record) {
+				return fmt.Errorf("undo record of %x = %v, want %v", hash, got, record)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migrated, err := u.MigrateLegacy(); err != nil || migrated {
+		t.Fatalf("second MigrateLegacy = %v, %v", migrated, err)
+	}
+}
@@ -0,0 +1,319 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Blok reddedilme sebepleri. AddBlock'un döndürdüğü hatalar bu değerlerden birini sarar,
+// böylece çağıran taraf errors.Is ile sebebi ayırt edebilir.
+var (
+	ErrUnknownGenesis   = errors.New("unknown genesis block")
+	ErrUnknownParent    = errors.New("previous block is not found")
+	ErrBadHeight        = errors.New("block height does not follow its parent")
+	ErrBadVersion       = errors.New("block version is not supported")
+	ErrTimeTooOld       = errors.New("block timestamp is not after the median time of previous blocks")
+	ErrTimeTooNew       = errors.New("block timestamp is too far in the future")
+	ErrBadDifficulty    = errors.New("block bits do not match the required difficulty")
+	ErrBadProofOfWork   = errors.New("proof of work is invalid")
+	ErrBadBlockHash     = errors.New("block hash does not match its contents")
+	ErrBadMerkleRoot    = errors.New("block merkle root does not match its transactions")
+	ErrNoTransactions   = errors.New("block has no transactions")
+	ErrBadCoinbase      = errors.New("block must contain exactly one coinbase transaction")
+	ErrBadTransactionID = errors.New("transaction id does not match its contents")
+	ErrDuplicateTx      = errors.New("transaction appears more than once in block")
+	ErrMissingInput     = errors.New("transaction input references an unknown or spent output")
+	ErrDoubleSpend      = errors.New("output is spent more than once")
+	ErrBadSignature     = errors.New("transaction signature is invalid")
+
+	ErrBadBlockSignature  = errors.New("block signature is invalid")
+	ErrUnauthorizedSigner = errors.New("block signer is not an authority")
+	ErrNotInTurn          = errors.New("block signer is not in turn")
+	ErrBadVote            = errors.New("block vote does not change the authority set")
+
+	ErrBadTxVersion       = errors.New("transaction version is not supported")
+	ErrEmptyTransaction   = errors.New("transaction has no inputs or no outputs")
+	ErrNegativeValue      = errors.New("transaction output value is negative")
+	ErrValueTooLarge      = errors.New("transaction value exceeds the maximum money supply")
+	ErrInsufficientInputs = errors.New("transaction outputs exceed its inputs")
+	ErrBadCoinbaseValue   = errors.New("coinbase pays more than the block reward and fees")
+	ErrTxNotFinal         = errors.New("transaction lock time is not reached")
+	ErrSequenceLocked     = errors.New("transaction input is spent before its relative lock time")
+)
+
+// CheckTransactionSanity fonksiyonu, işlemi önceki işlemlere bakmadan kontrol eder:
+// sürümü bilinmeli, en az bir girdi ve bir çıktı olmalı, çıktı değerleri negatif olmamalı ve toplamları MaxMoney'i aşmamalı.
+// Betiklerden önceki sürümlerdeki işlemler yalnızca eski alanlarla yazılabilen (P2PKH) betikler,
+// sürüm 3'ten önceki işlemler ise kilit taşıyamaz.
+func CheckTransactionSanity(tx *Transaction) error {
+	if tx.Version < 0 || tx.Version > TxVersion {
+		return fmt.Errorf("%w: %d", ErrBadTxVersion, tx.Version)
+	}
+	if tx.Version < scriptTxVersion && !tx.isLegacyEncodable() {
+		return fmt.Errorf("%w: version %d transaction %x carries scripts", ErrBadTxVersion, tx.Version, tx.ID)
+	}
+	if tx.Version < lockTimeTxVersion && tx.hasLocks() {
+		return fmt.Errorf("%w: version %d transaction %x carries lock times", ErrBadTxVersion, tx.Version, tx.ID)
+	}
+
+	if len(tx.Inputs) == 0 || len(tx.Outputs) == 0 {
+		return fmt.Errorf("%w: %x", ErrEmptyTransaction, tx.ID)
+	}
+
+	total := 0
+	for i, out := range tx.Outputs {
+		if out.Value < 0 {
+			return fmt.Errorf("%w: %x:%d", ErrNegativeValue, tx.ID, i)
+		}
+		if out.Value > MaxMoney {
+			return fmt.Errorf("%w: %x:%d", ErrValueTooLarge, tx.ID, i)
+		}
+		// Her iki değer de MaxMoney ile sınırlı olduğundan toplama taşma yapamaz
+		total += out.Value
+		if total > MaxMoney {
+			return fmt.Errorf("%w: %x", ErrValueTooLarge, tx.ID)
+		}
+	}
+
+	return nil
+}
+
+// CheckTransactionInputs fonksiyonu, işlemin harcadığı çıktıların toplamının ürettiği çıktıları
+// karşıladığını kontrol eder ve aradaki farkı döndürür. prevTXs tüm girdilerin işlemlerini içermelidir.
+func CheckTransactionInputs(tx *Transaction, prevTXs map[string]Transaction) (int, error) {
+	if tx.IsCoinbase() {
+		return 0, nil
+	}
+
+	totalIn := 0
+	for _, in := range tx.Inputs {
+		prevTX, ok := prevTXs[hex.EncodeToString(in.ID)]
+		if !ok || in.Out < 0 || in.Out >= len(prevTX.Outputs) {
+			return 0, fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+		}
+
+		value := prevTX.Outputs[in.Out].Value
+		if value < 0 {
+			return 0, fmt.Errorf("%w: %x:%d", ErrNegativeValue, in.ID, in.Out)
+		}
+		if value > MaxMoney {
+			return 0, fmt.Errorf("%w: %x:%d", ErrValueTooLarge, in.ID, in.Out)
+		}
+		totalIn += value
+		if totalIn > MaxMoney {
+			return 0, fmt.Errorf("%w: %x", ErrValueTooLarge, tx.ID)
+		}
+	}
+
+	totalOut := 0
+	for _, out := range tx.Outputs {
+		totalOut += out.Value
+	}
+
+	if totalIn < totalOut {
+		return 0, fmt.Errorf("%w: %x has %d in, %d out", ErrInsufficientInputs, tx.ID, totalIn, totalOut)
+	}
+
+	return totalIn - totalOut, nil
+}
+
+// checkCoinbaseValue fonksiyonu, coinbase işleminin blok ödülü ile toplanan ücretlerin toplamından
+// (reward) fazlasını dağıtmadığını kontrol eder.
+func checkCoinbaseValue(coinbase *Transaction, reward int) error {
+	total := 0
+	for _, out := range coinbase.Outputs {
+		total += out.Value
+	}
+
+	if total > reward {
+		return fmt.Errorf("%w: pays %d, reward is %d", ErrBadCoinbaseValue, total, reward)
+	}
+	return nil
+}
+
+// CheckBlockSanity fonksiyonu, bloğu zincirden ve konsensüsten bağımsız olarak kontrol eder:
+// sürüm, merkle kökü, işlem kimlikleri, coinbase sayısı ve blok içinde çift harcama.
+// Mühür Engine.VerifySeal ile, zaman damgası ise checkBlockContext ile ayrıca doğrulanır.
+func CheckBlockSanity(block *Block) error {
+	if len(block.Transactions) == 0 {
+		return ErrNoTransactions
+	}
+
+	if block.Version < 1 || block.Version > BlockVersion {
+		return fmt.Errorf("%w: %d", ErrBadVersion, block.Version)
+	}
+
+	if root := block.HashTransactions(); root == nil || !bytes.Equal(block.MerkleRoot, root) { //baslık islemlere yalnızca merkle kökü ile baglıdır
+		return fmt.Errorf("%w: %x", ErrBadMerkleRoot, block.Hash)
+	}
+
+	coinbases := 0
+	seenTXs := make(map[string]bool)
+	spent := make(map[string]bool)
+
+	for _, tx := range block.Transactions {
+		if !bytes.Equal(tx.ID, tx.Hash()) {
+			return fmt.Errorf("%w: %x", ErrBadTransactionID, tx.ID)
+		}
+
+		if err := CheckTransactionSanity(tx); err != nil {
+			return err
+		}
+
+		txID := hex.EncodeToString(tx.ID)
+		if seenTXs[txID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateTx, txID)
+		}
+		seenTXs[txID] = true
+
+		if tx.IsCoinbase() {
+			coinbases++
+			continue
+		}
+
+		for _, in := range tx.Inputs {
+			if in.Out < 0 {
+				return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+			}
+			outpoint := fmt.Sprintf("%x:%d", in.ID, in.Out)
+			if spent[outpoint] {
+				return fmt.Errorf("%w: %s", ErrDoubleSpend, outpoint)
+			}
+			spent[outpoint] = true
+		}
+	}
+
+	if coinbases != 1 {
+		return fmt.Errorf("%w: found %d", ErrBadCoinbase, coinbases)
+	}
+
+	return nil
+}
+
+// checkBlockContext fonksiyonu, bloğun ebeveyniyle ve zincirin parametreleriyle uyumunu kontrol eder:
+// ebeveyn mevcut olmalı, yükseklik bir fazlası olmalı, zaman damgası önceki blokların medyanından
+// büyük olmalı ve düğümün saatinden Params.MaxFutureBlockTime saniyeden daha ileride olmamalı,
+// konsensüs alanları (ör. zorluk) zincirin beklediği değerler olmalı.
+func (chain *BlockChain) checkBlockContext(block *Block) error {
+	if len(block.PrevHash) == 0 {
+		return ErrUnknownGenesis
+	}
+
+	parent, err := chain.GetBlock(block.PrevHash)
+	if err != nil {
+		return fmt.Errorf("%w: %x", ErrUnknownParent, block.PrevHash)
+	}
+
+	if block.Height != parent.Height+1 {
+		return fmt.Errorf("%w: got %d, want %d", ErrBadHeight, block.Height, parent.Height+1)
+	}
+
+	medianTime, err := chain.CalcPastMedianTime(&parent)
+	if err != nil {
+		return err
+	}
+	if block.Timestamp <= medianTime {
+		return fmt.Errorf("%w: %d is not after %d", ErrTimeTooOld, block.Timestamp, medianTime)
+	}
+	if limit := time.Now().Unix() + chain.Params.MaxFutureBlockTime; block.Timestamp > limit {
+		return fmt.Errorf("%w: %d is after %d", ErrTimeTooNew, block.Timestamp, limit)
+	}
+
+	return chain.Engine.VerifyHeader(chain, block, &parent)
+}
+
+// checkBlockTransactions fonksiyonu, bloğun işlemlerini mevcut UTXO setine göre kontrol eder.
+// Girdiler harcanmamış bir çıktıya (ya da blokta daha önce üretilmiş bir çıktıya) işaret etmeli
+// ve imzalar doğrulanmalıdır. İşlemlerin kilit zamanlarına ve girdilerin göreli kilitlerine ulaşılmış olmalıdır.
+// Coinbase işlemi bu yükseklikteki ödül ile bloktaki işlem ücretlerinin toplamından fazlasını dağıtmamalıdır.
+// Blok, zincir ucunun hemen üzerine eklenecekmiş gibi değerlendirilir.
+func (chain *BlockChain) checkBlockTransactions(block *Block) error {
+	UTXOSet := UTXOSet{Blockchain: chain}
+	blockTXs := make(map[string]Transaction)
+	var coinbase *Transaction
+	fees := 0
+
+	parent, err := chain.GetBlock(block.PrevHash)
+	if err != nil {
+		return fmt.Errorf("%w: %x", ErrUnknownParent, block.PrevHash)
+	}
+	medianTime, err := chain.CalcPastMedianTime(&parent) //kilitler bloğun zaman damgasına değil önceki blokların medyanına göre açılır
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range block.Transactions {
+		if err := chain.CheckTransactionLocks(tx, block.Height, medianTime, blockTXs); err != nil {
+			return err
+		}
+
+		if tx.IsCoinbase() {
+			coinbase = tx
+			blockTXs[hex.EncodeToString(tx.ID)] = *tx
+			continue
+		}
+
+		prevTXs := make(map[string]Transaction)
+		for _, in := range tx.Inputs {
+			id := hex.EncodeToString(in.ID)
+
+			prevTX, inBlock := blockTXs[id]
+			if !inBlock {
+				unspent, err := UTXOSet.hasOutput(in.ID, in.Out)
+				if err != nil {
+					return err
+				}
+				if !unspent {
+					return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+				}
+
+				prevTX, err = chain.FindTransaction(in.ID)
+				if err != nil {
+					return fmt.Errorf("%w: %x:%d", ErrMissingInput, in.ID, in.Out)
+				}
+			}
+
+			prevTXs[id] = prevTX
+		}
+
+		fee, err := CheckTransactionInputs(tx, prevTXs)
+		if err != nil {
+			return err
+		}
+		fees += fee // her ücret MaxMoney ile sınırlı, toplam taşma yapmaz
+
+		if err := tx.VerifyInputs(prevTXs); err != nil {
+			return err
+		}
+
+		blockTXs[hex.EncodeToString(tx.ID)] = *tx
+	}
+
+	return checkCoinbaseValue(coinbase, chain.Engine.Reward(block.Height)+fees)
+}
+
+// ValidateBlock fonksiyonu, bir bloğu kaydedilmeden önce doğrular.
+// Zincir ucunu uzatan bloklar işlemleriyle birlikte tam olarak doğrulanır; yan daldaki blokların
+// işlemleri ise dal ana zincir olmaya aday olduğunda (reorganize sırasında) doğrulanır.
+func (chain *BlockChain) ValidateBlock(block *Block) error {
+	if err := CheckBlockSanity(block); err != nil {
+		return err
+	}
+
+	if err := chain.Engine.VerifySeal(chain, block); err != nil {
+		return err
+	}
+
+	if err := chain.checkBlockContext(block); err != nil {
+		return err
+	}
+
+	if bytes.Equal(block.PrevHash, chain.LastHash()) {
+		return chain.checkBlockTransactions(block)
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// setTransactions fonksiyonu, bloğun işlemlerini değiştirir ve merkle kökünü yeniden hesaplar.
+func setTransactions(b *Block, txs ...*Transaction) {
+	b.Transactions = txs
+	b.MerkleRoot = b.HashTransactions()
+}
+
+// Geçersiz bloklar, sebebi errors.Is ile ayırt edilebilen bir hatayla reddedilir; zincir ucu değişmez ve
+// blok kaydedilmez. Her durumda blok yeniden mühürlendiğinden yalnızca bozulan kural başarısız olur.
+func TestAddBlockRejections(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spend, err := NewTransaction(w, string(to.Address()), 5, 0, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflict, err := NewTransaction(w, string(to.Address()), 6, 0, 0, u) // aynı genesis çıktısını harcar
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := &Transaction{TxVersion, nil, []TxInput{{bytes.Repeat([]byte{0x42}, 32), 0, nil, SequenceFinal}}, []TxOutput{*NewTXOutput(1, string(to.Address()))}, 0}
+	unknown.ID = unknown.Hash()
+
+	newCoinbase := func(data string) *Transaction {
+		cb, err := CoinbaseTx(string(w.Address()), data, chain.Engine.Reward(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cb
+	}
+	cb := newCoinbase("cb")
+
+	tests := []struct {
+		name   string
+		header func(b *Block) // mühürlenmeden önce
+		sealed func(b *Block) // mühürlendikten sonra
+		want   error
+	}{
+		{"no transactions", func(b *Block) { b.Transactions = nil }, nil, ErrNoTransactions},
+		{"bad version", func(b *Block) { b.Version = BlockVersion + 1 }, nil, ErrBadVersion},
+		{"bad merkle root", func(b *Block) { b.MerkleRoot = make([]byte, 32) }, nil, ErrBadMerkleRoot},
+		{"bad transaction id", func(b *Block) {
+			tx := *cb
+			tx.ID = bytes.Repeat([]byte{0x01}, 32)
+			setTransactions(b, &tx)
+		}, nil, ErrBadTransactionID},
+		{"duplicate transaction", func(b *Block) { setTransactions(b, cb, spend, spend) }, nil, ErrDuplicateTx},
+		{"negative output index", func(b *Block) {
+			tx := *spend
+			tx.Inputs = append([]TxInput(nil), spend.Inputs...)
+			tx.Inputs[0].Out = -1
+			tx.ID = tx.Hash()
+			setTransactions(b, cb, &tx)
+		}, nil, ErrMissingInput},
+		{"double spend in block", func(b *Block) { setTransactions(b, cb, spend, conflict) }, nil, ErrDoubleSpend},
+		{"no coinbase", func(b *Block) { setTransactions(b, spend) }, nil, ErrBadCoinbase},
+		{"two coinbases", func(b *Block) { setTransactions(b, cb, newCoinbase("second")) }, nil, ErrBadCoinbase},
+
+		{"bad block hash", nil, func(b *Block) { b.Hash[0] ^= 0xff }, ErrBadBlockHash},
+		{"target above pow limit", func(b *Block) {
+			b.Bits = BigToCompact(new(big.Int).Lsh(chain.Params.PowLimit(), 1))
+		}, nil, ErrBadProofOfWork},
+
+		{"unknown genesis", func(b *Block) { b.PrevHash, b.Height = []byte{}, 0 }, nil, ErrUnknownGenesis},
+		{"unknown parent", func(b *Block) { b.PrevHash = bytes.Repeat([]byte{0x24}, 32) }, nil, ErrUnknownParent},
+		{"bad height", func(b *Block) { b.Height = 2 }, nil, ErrBadHeight},
+		{"time too old", func(b *Block) { b.Timestamp = genesis.Timestamp }, nil, ErrTimeTooOld},
+		{"time too new", func(b *Block) {
+			b.Timestamp = time.Now().Unix() + chain.Params.MaxFutureBlockTime + 60
+		}, nil, ErrTimeTooNew},
+		{"bad difficulty", func(b *Block) { b.Bits = BigToCompact(new(big.Int).Lsh(big.NewInt(1), 250)) }, nil, ErrBadDifficulty},
+
+		{"unknown input", func(b *Block) { setTransactions(b, cb, unknown) }, nil, ErrMissingInput},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &Block{
+				Version:   BlockVersion,
+				Timestamp: genesis.Timestamp + 1,
+				PrevHash:  genesis.Hash,
+				Height:    1,
+				Bits:      chain.Params.InitialBits(),
+			}
+			setTransactions(b, cb)
+			if test.header != nil {
+				test.header(b)
+			}
+			if err := chain.Engine.Seal(context.Background(), b); err != nil {
+				t.Fatal(err)
+			}
+			if test.sealed != nil {
+				test.sealed(b)
+			}
+
+			if err := chain.AddBlock(b); !errors.Is(err, test.want) {
+				t.Fatalf("AddBlock = %v, want %v", err, test.want)
+			}
+			if !bytes.Equal(chain.LastHash(), genesis.Hash) {
+				t.Fatalf("tip moved to %x", chain.LastHash())
+			}
+			if _, err := chain.GetBlock(b.Hash); !errors.Is(err, ErrBlockNotFound) {
+				t.Fatalf("rejected block was stored: %v", err)
+			}
+		})
+	}
+
+	// Aynı işlemleri taşıyan geçerli bir blok kabul edilir
+	block, err := chain.MineBlock([]*Transaction{cb, spend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(chain.LastHash(), block.Hash) {
+		t.Fatalf("tip %x, want %x", chain.LastHash(), block.Hash)
+	}
+}
+
+// testTransaction fonksiyonu, verilen girdi çıktılarla P2PKH betikli bir işlem oluşturur ve kimliğini hesaplar.
+func testTransaction(version int32, inputs []TxInput, values ...int) *Transaction {
+	script := PayToPubKeyHashScript(bytes.Repeat([]byte{0x77}, 20))
+	tx := &Transaction{Version: version, Inputs: inputs}
+	for _, value := range values {
+		tx.Outputs = append(tx.Outputs, TxOutput{value, script})
+	}
+	tx.ID = tx.Hash()
+	return tx
+}
+
+// Önceki işlemlere bakmadan yapılan kontroller (sürüm, boş işlem, değer sınırları) tipli hatalarla reddeder.
+func TestCheckTransactionSanity(t *testing.T) {
+	input := []TxInput{{bytes.Repeat([]byte{0x11}, 32), 0, PubKeyHashSigScript([]byte{0x01}, []byte{0x02}), SequenceFinal}}
+	withScript := testTransaction(1, input, 1)
+	withScript.Outputs[0].ScriptPubKey = NewScriptBuilder().AddOp(OpTrue).Script()
+	withLock := testTransaction(scriptTxVersion, input, 1)
+	withLock.LockTime = 10
+	withSequence := testTransaction(scriptTxVersion, []TxInput{{input[0].ID, 0, input[0].ScriptSig, 5}}, 1)
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		want error
+	}{
+		{"valid", testTransaction(TxVersion, input, 1, MaxMoney-1), nil},
+		{"unknown version", testTransaction(TxVersion+1, input, 1), ErrBadTxVersion},
+		{"negative version", testTransaction(-1, input, 1), ErrBadTxVersion},
+		{"script before version 2", withScript, ErrBadTxVersion},
+		{"lock time before version 3", withLock, ErrBadTxVersion},
+		{"sequence before version 3", withSequence, ErrBadTxVersion},
+		{"no inputs", testTransaction(TxVersion, nil, 1), ErrEmptyTransaction},
+		{"no outputs", testTransaction(TxVersion, input), ErrEmptyTransaction},
+		{"negative output", testTransaction(TxVersion, input, 1, -1), ErrNegativeValue},
+		{"output above max money", testTransaction(TxVersion, input, MaxMoney+1), ErrValueTooLarge},
+		{"outputs above max money", testTransaction(TxVersion, input, MaxMoney, 1), ErrValueTooLarge},
+	}
+
+	for _, test := range tests {
+		if err := CheckTransactionSanity(test.tx); !errors.Is(err, test.want) {
+			t.Errorf("%s: CheckTransactionSanity = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Girdilerin toplamı çıktıları karşılamalıdır; aradaki fark ücret olarak döner.
+func TestCheckTransactionInputs(t *testing.T) {
+	prev := testTransaction(TxVersion, []TxInput{{bytes.Repeat([]byte{0x11}, 32), 0, nil, SequenceFinal}}, 7, 3)
+	bad := testTransaction(TxVersion, prev.Inputs, -1, MaxMoney+1, MaxMoney)
+	prevTXs := map[string]Transaction{hex.EncodeToString(prev.ID): *prev, hex.EncodeToString(bad.ID): *bad}
+	spend := func(id []byte, outs ...int) []TxInput {
+		var inputs []TxInput
+		for _, out := range outs {
+			inputs = append(inputs, TxInput{id, out, nil, SequenceFinal})
+		}
+		return inputs
+	}
+
+	tests := []struct {
+		name    string
+		tx      *Transaction
+		wantFee int
+		want    error
+	}{
+		{"fee", testTransaction(TxVersion, spend(prev.ID, 0, 1), 6, 2), 2, nil},
+		{"no fee", testTransaction(TxVersion, spend(prev.ID, 0), 7), 0, nil},
+		{"outputs exceed inputs", testTransaction(TxVersion, spend(prev.ID, 1), 4), 0, ErrInsufficientInputs},
+		{"unknown transaction", testTransaction(TxVersion, spend(bytes.Repeat([]byte{0x99}, 32), 0), 1), 0, ErrMissingInput},
+		{"output index out of range", testTransaction(TxVersion, spend(prev.ID, 2), 1), 0, ErrMissingInput},
+		{"negative output index", testTransaction(TxVersion, spend(prev.ID, -1), 1), 0, ErrMissingInput},
+		{"negative input value", testTransaction(TxVersion, spend(bad.ID, 0), 1), 0, ErrNegativeValue},
+		{"input above max money", testTransaction(TxVersion, spend(bad.ID, 1), 1), 0, ErrValueTooLarge},
+		{"inputs above max money", testTransaction(TxVersion, append(spend(bad.ID, 2), spend(prev.ID, 0)...), 1), 0, ErrValueTooLarge},
+	}
+
+	for _, test := range tests {
+		fee, err := CheckTransactionInputs(test.tx, prevTXs)
+		if !errors.Is(err, test.want) || fee != test.wantFee {
+			t.Errorf("%s: CheckTransactionInputs = %d, %v, want %d, %v", test.name, fee, err, test.wantFee, test.want)
+		}
+	}
+
+	cb, err := CoinbaseTx(string(wallet.MakeWallet().Address()), "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee, err := CheckTransactionInputs(cb, nil); err != nil || fee != 0 {
+		t.Errorf("coinbase: CheckTransactionInputs = %d, %v", fee, err)
+	}
+}
+
+// Coinbase, blok ödülü ile bloktaki ücretlerin toplamını dağıtabilir, fazlasını dağıtamaz. Kural hem
+// doğrudan hem de zincire eklenen bloklarda uygulanır.
+func TestCoinbaseValue(t *testing.T) {
+	w, to := wallet.MakeWallet(), wallet.MakeWallet()
+	chain, u := newTestChain(t, w)
+
+	for reward, want := range map[int]error{9: nil, 10: nil, 11: ErrBadCoinbaseValue} {
+		cb, err := CoinbaseTx(string(w.Address()), "", reward)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkCoinbaseValue(cb, 10); !errors.Is(err, want) {
+			t.Errorf("checkCoinbaseValue(%d, 10) = %v, want %v", reward, err, want)
+		}
+	}
+
+	const fee = 2
+	spend, err := NewTransaction(w, string(to.Address()), 5, fee, 0, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	greedy, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(1)+fee+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{greedy, spend}); !errors.Is(err, ErrBadCoinbaseValue) {
+		t.Fatalf("MineBlock = %v, want ErrBadCoinbaseValue", err)
+	}
+
+	cb, err := CoinbaseTx(string(w.Address()), "", chain.Engine.Reward(1)+fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.MineBlock([]*Transaction{cb, spend}); err != nil {
+		t.Fatal(err)
+	}
+}
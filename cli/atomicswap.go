@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/blockchain"
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Atomik takas komutları. Başlatan taraf (initiate) gizli değeri üretir ve kendi zincirinde karşı tarafa bir
+// sözleşme kilitler; karşı taraf (participate) aynı gizli değer hash'iyle diğer zincirde bir sözleşme kilitler.
+// Başlatan taraf karşı tarafın sözleşmesini harcarken (redeem) gizli değeri açıklar; karşı taraf değeri o
+// zincirden çıkarır (extractsecret) ve başlatan tarafın sözleşmesini harcar. Takas tamamlanmazsa taraflar
+// kilit zamanından sonra kendi sözleşmelerini geri alır (refund). Sözleşme işlemi bir dosyada (bkz. writeTxFile),
+// asıl betik ise hex olarak taşınır.
+
+// Kilit zamanı verilmediğinde kullanılan süreler. Başlatan tarafın sözleşmesi karşı tarafınkinden sonra
+// açılmalıdır; aksi halde karşı taraf gizli değeri öğrendikten sonra iki sözleşmeyi de alabilir.
+const (
+	initiateLockDuration    = 48 * time.Hour
+	participateLockDuration = 24 * time.Hour
+)
+
+// initiate fonksiyonu, yeni bir gizli değer üretir ve from cüzdanından to adresine amount tutarında bir
+// atomik takas sözleşmesi kilitler. Gizli değer karşı taraf kendi sözleşmesini kilitleyene kadar saklanmalıdır.
+func (cli *CommandLine) initiate(from, to string, amount, fee int, lockTime uint32, file, nodeID string, mineNow bool) {
+	secret := make([]byte, blockchain.SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		log.Panic(err)
+	}
+	secretHash := sha256.Sum256(secret)
+
+	if lockTime == 0 {
+		lockTime = uint32(time.Now().Add(initiateLockDuration).Unix())
+	}
+
+	fmt.Printf("\u001B[32mSecret           : %x\u001B[0m\n", secret)
+	fmt.Printf("Secret hash      : %x\n", secretHash)
+	cli.lockContract(from, to, amount, fee, secretHash[:], lockTime, file, nodeID, mineNow)
+}
+
+// participate fonksiyonu, başlatan tarafın sözleşmesindeki gizli değer hash'iyle from cüzdanından to adresine
+// amount tutarında bir atomik takas sözleşmesi kilitler.
+func (cli *CommandLine) participate(from, to string, amount, fee int, secretHashHex string, lockTime uint32, file, nodeID string, mineNow bool) {
+	secretHash, err := hex.DecodeString(secretHashHex)
+	if err != nil || len(secretHash) != sha256.Size {
+		log.Panic("\033[31mSecret hash must be 32 bytes of hex\033[0m")
+	}
+
+	if lockTime == 0 {
+		lockTime = uint32(time.Now().Add(participateLockDuration).Unix())
+	}
+
+	cli.lockContract(from, to, amount, fee, secretHash, lockTime, file, nodeID, mineNow)
+}
+
+// lockContract fonksiyonu, sözleşmeyi oluşturur, from cüzdanından sözleşme adresine ödeme yapan işlemi
+// file dosyasına yazar ve gönderir (mineNow ise bu düğümde kazar).
+func (cli *CommandLine) lockContract(from, to string, amount, fee int, secretHash []byte, lockTime uint32, file, nodeID string, mineNow bool) {
+	version, recipientHash, ok := wallet.DecodeAddress(to)
+	if !ok || version != wallet.AddressVersion {
+		log.Panic("\033[31mThe recipient must be a wallet address of this network\033[0m")
+	}
+	w := localWallet(from, nodeID)
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Close()
+
+	contract, err := blockchain.AtomicSwapScript(blockchain.SwapContract{
+		SecretHash:    secretHash,
+		RecipientHash: recipientHash,
+		RefundHash:    wallet.PublicKeyHash(w.PublicKey),
+		LockTime:      lockTime,
+	})
+	handleError(err)
+
+	tx, err := blockchain.NewTransaction(w, blockchain.ScriptAddress(contract), amount, fee, 0, &UTXOSet)
+	handleError(err)
+	writeTxFile(file, tx)
+
+	miner := ""
+	if mineNow {
+		miner = from
+	}
+	submitTx(chain, tx, miner, nodeID)
+
+	fmt.Printf("Contract address : %s\n", blockchain.ScriptAddress(contract))
+	fmt.Printf("Contract         : %x\n", []byte(contract))
+	fmt.Printf("Contract tx      : %x (written to %s)\n", tx.ID, file)
+	printContract(contract)
+	fmt.Println("Success!")
+}
+
+// redeem fonksiyonu, file dosyasındaki işlemin kilitlediği sözleşmeyi gizli değerle harcar. Tokenlar
+// sözleşmenin alıcısına gider; alıcının cüzdanı bu düğümün cüzdan dosyasında olmalıdır.
+func (cli *CommandLine) redeem(contractHex, file, secretHex string, fee int, nodeID string, mineNow bool) {
+	contract, terms := decodeContract(contractHex)
+	secret, err := hex.DecodeString(secretHex)
+	if err != nil {
+		log.Panic("\033[31mSecret is not valid hex\033[0m")
+	}
+	w := contractWallet(terms.RecipientHash, nodeID)
+
+	tx, err := blockchain.NewAtomicSwapRedeem(contract, readTxFile(file), secret, w, fee)
+	handleError(err)
+	cli.spendContract(tx, w, nodeID, mineNow)
+}
+
+// refund fonksiyonu, file dosyasındaki işlemin kilitlediği sözleşmeyi kilit zamanından sonra göndericiye
+// geri öder; göndericinin cüzdanı bu düğümün cüzdan dosyasında olmalıdır.
+func (cli *CommandLine) refund(contractHex, file string, fee int, nodeID string, mineNow bool) {
+	contract, terms := decodeContract(contractHex)
+	w := contractWallet(terms.RefundHash, nodeID)
+
+	tx, err := blockchain.NewAtomicSwapRefund(contract, readTxFile(file), w, fee)
+	handleError(err)
+	cli.spendContract(tx, w, nodeID, mineNow)
+}
+
+// spendContract fonksiyonu, sözleşmeyi harcayan işlemi gönderir (mineNow ise w'nin adresine ödülle kazar).
+func (cli *CommandLine) spendContract(tx *blockchain.Transaction, w *wallet.Wallet, nodeID string, mineNow bool) {
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+
+	miner := ""
+	if mineNow {
+		miner = string(w.Address())
+	}
+	submitTx(chain, tx, miner, nodeID)
+
+	fmt.Printf("Contract spent to %s by transaction %x\n", w.Address(), tx.ID)
+	fmt.Println("Success!")
+}
+
+// extractSecret fonksiyonu, file dosyasındaki işlemin kilitlediği sözleşmeyi harcayan işlemi bu düğümün
+// zincirinde bulur ve açıkladığı gizli değeri yazdırır.
+func (cli *CommandLine) extractSecret(contractHex, file, nodeID string) {
+	contract, terms := decodeContract(contractHex)
+	contractTx := readTxFile(file)
+	out, err := blockchain.ContractOutput(contract, contractTx)
+	handleError(err)
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+
+	spendingTx, err := chain.FindSpendingTransaction(contractTx.ID, out)
+	handleError(err)
+	secret, ok := blockchain.ExtractSecret(&spendingTx, terms.SecretHash)
+	if !ok {
+		log.Panicf("\033[31mTransaction %x spends the contract without revealing the secret (refund)\033[0m", spendingTx.ID)
+	}
+
+	fmt.Printf("\u001B[32mSecret           : %x\u001B[0m\n", secret)
+}
+
+// decodeContract fonksiyonu, hex olarak verilen sözleşmeyi ve koşullarını döndürür.
+func decodeContract(contractHex string) (blockchain.Script, blockchain.SwapContract) {
+	data, err := hex.DecodeString(contractHex)
+	if err != nil {
+		log.Panic("\033[31mContract is not valid hex\033[0m")
+	}
+	contract := blockchain.Script(data)
+	terms, ok := contract.AtomicSwap()
+	if !ok {
+		handleError(fmt.Errorf("%w: %s", blockchain.ErrBadContract, contract))
+	}
+	return contract, terms
+}
+
+// printContract fonksiyonu, sözleşmenin koşullarını yazdırır.
+func printContract(contract blockchain.Script) {
+	terms, _ := contract.AtomicSwap()
+	fmt.Printf("Recipient        : %s\n", wallet.PubKeyHashAddress(terms.RecipientHash))
+	fmt.Printf("Refund to        : %s\n", wallet.PubKeyHashAddress(terms.RefundHash))
+	if terms.LockTime < blockchain.LockTimeThreshold {
+		fmt.Printf("Refundable from  : block %d\n", terms.LockTime)
+	} else {
+		fmt.Printf("Refundable from  : %s\n", time.Unix(int64(terms.LockTime), 0).Format(time.RFC3339))
+	}
+}
+
+// localWallet fonksiyonu, bu düğümün cüzdan dosyasındaki address cüzdanını döndürür.
+func localWallet(address, nodeID string) *wallet.Wallet {
+	wallets, err := wallet.CreateWallets(nodeID)
+	if err != nil {
+		log.Panic(err)
+	}
+	w, ok := wallets.Wallets[address]
+	if !ok {
+		log.Panicf("\033[31mWallet %s is not in this node's wallet file\033[0m", address)
+	}
+	return w
+}
+
+// contractWallet fonksiyonu, bu düğümün cüzdan dosyasında public key hash'i pubKeyHash olan cüzdanı döndürür.
+func contractWallet(pubKeyHash []byte, nodeID string) *wallet.Wallet {
+	wallets, err := wallet.CreateWallets(nodeID)
+	if err != nil {
+		log.Panic(err)
+	}
+	for _, w := range wallets.Wallets {
+		if bytes.Equal(wallet.PublicKeyHash(w.PublicKey), pubKeyHash) {
+			return w
+		}
+	}
+	log.Panicf("\033[31mWallet %s is not in this node's wallet file\033[0m", wallet.PubKeyHashAddress(pubKeyHash))
+	return nil
+}
@@ -1,15 +1,18 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/SadikSunbul/GO-BlockChain-Simulation/blockchain"
 	"github.com/SadikSunbul/GO-BlockChain-Simulation/network"
 	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
 	"log"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 )
 
 // CommandLine struct, komut satırı işlemleri için kullanılan yapıyı temsil eder.
@@ -22,27 +25,94 @@ func (cli *CommandLine) printUsage() {
 	fmt.Printf("\033[35mUsage:\n\033[0m")
 	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "getbalance -address ADDRESS", "Belirtilen adrese ait bakiyeyi görüntüler")
 	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "createblockchain -address ADDRESS", "Yeni bir blok zinciri oluşturur ve belirtilen adrese oluşum ödülünü gönderir")
-	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "printchain", "Blok zincirindeki tüm blokları yazdırır")
-	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "send -from FROM -to TO -amount AMOUNT -mine", "Belirli bir miktarda coin gönder. Ardından -mine bayrağı ayarlanır, bu düğüm üzerinde madencilik yap")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "printchain -from FROM -to TO", "Blok zincirindeki blokları yazdırır. -from/-to verilirse o yükseklik aralığını artan sırayla yazdırır")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "send -from FROM -to TO -amount AMOUNT -fee FEE -mine", "Belirli bir miktarda coin gönder. -fee madenciye bırakılan ücrettir. Ardından -mine bayrağı ayarlanır, bu düğüm üzerinde madencilik yap")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "send ... -locktime LOCKTIME", "İşlem LOCKTIME blok yüksekliğinden (500000000 ve üstü ise unix zamanından) önce kazılamaz")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "getsupply", "Üretilmiş toplam token miktarını, sıradaki blok ödülünü ve arz üst sınırını gösterir")
 	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "createwallet", "Yeni bir cüzdan oluşturur")
-	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "listaddresses", "Cüzdan dosyamızdaki adresleri listeleyin")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "listaddresses -pubkeys", "Cüzdan dosyamızdaki adresleri listeleyin. -pubkeys ile adreslerin public key'leri de yazdırılır")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "createmultisig -required M -keys KEY,KEY,...", "KEY'lerden (hex public key ya da yerel cüzdan adresi) M tanesinin imzasını isteyen çoklu imza adresi oluşturur")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "spendmultisig -redeem SCRIPT -to TO -amount AMOUNT -fee FEE -tx FILE", "Çoklu imza adresinden imzasız bir ödeme işlemi oluşturur ve FILE dosyasına yazar")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "signmultisig -tx FILE -address ADDRESS", "FILE dosyasındaki işleme yerel ADDRESS cüzdanının imzasını ekler")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "sendmultisig -tx FILE -miner ADDRESS", "İmzaları tamamlanan işlemi gönderir. -miner verilirse bu düğümde hemen kazılır ve ödül ADDRESS'e gider")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "initiate -from FROM -to TO -amount AMOUNT -fee FEE -locktime LOCKTIME -tx FILE -mine", "Atomik takası başlatır: gizli değer üretir, TO'ya FROM'un LOCKTIME'dan (varsayılan 48 saat) sonra geri alabileceği bir sözleşme kilitler ve işlemi FILE'a yazar")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "participate -from FROM -to TO -amount AMOUNT -fee FEE -secrethash HASH -locktime LOCKTIME -tx FILE -mine", "Karşı tarafın gizli değer hash'iyle bu zincirde bir sözleşme kilitler (varsayılan kilit 24 saat)")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "redeem -contract CONTRACT -tx FILE -secret SECRET -fee FEE -mine", "FILE'daki sözleşmeyi gizli değerle alıcının yerel cüzdanına harcar")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "refund -contract CONTRACT -tx FILE -fee FEE -mine", "FILE'daki sözleşmeyi kilit zamanından sonra göndericinin yerel cüzdanına geri öder")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "extractsecret -contract CONTRACT -tx FILE", "FILE'daki sözleşmeyi harcayan işlemi zincirde bulur ve açıkladığı gizli değeri yazdırır")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "stamp -file FILE -from FROM -fee FEE -mine", "FILE'ın SHA-256 hash'ini FROM'un ödediği harcanamaz bir veri çıktısıyla zincire yazar")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "verifystamp -file FILE", "FILE'ın hash'ini taşıyan bloğu bulur ve işlemin bloğun merkle köküne bağlandığını gösteren kanıtı yazdırır")
 	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "reindexutxo", "UTXO setini yeniden oluşturur")
-	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "startnode -miner ADDRESS", "NODE_ID ortamında belirtilen kimliğe sahip bir düğüm başlatın. var. -miner madenciliği mümkün kılar")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "reindextx", "İşlem ve harcama indekslerini ana zincirden yeniden oluşturur")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "startnode -miner ADDRESS -workers N", "NODE_ID ortamında belirtilen kimliğe sahip bir düğüm başlatın (verilmezse ağın varsayılan portu). -miner madenciliği mümkün kılar, -workers kazımda kullanılacak goroutine sayısıdır")
+	fmt.Printf(" \033[35m%-40s : %s\n\033[0m", "startnode -voteadd|-voteremove ADDRESS", "PoA: madenci düğümün imzaladığı bloklarda ADDRESS için oy verir")
 
 }
 
+// handleError fonksiyonu, hata varsa komutu sonlandırır. Kullanıcının düzeltebileceği hatalar
+// (zincir yok, yetersiz bakiye ...) yalnızca mesajla, beklenmeyen hatalar panic ile sonlanır.
+// runtime.Goexit ertelenmiş çağrıları (ör. veritabanının kapatılması) çalıştırır.
+func handleError(err error) {
+	if err == nil {
+		return
+	}
+
+	for _, userErr := range []error{
+		blockchain.ErrChainNotFound, blockchain.ErrChainExists, blockchain.ErrConsensusMismatch, blockchain.ErrNetworkMismatch,
+		blockchain.ErrInvalidAddress, blockchain.ErrInvalidAmount, blockchain.ErrInsufficientFunds,
+		blockchain.ErrNonStandardScript, blockchain.ErrBadKeyCount, blockchain.ErrTxNotFinal, blockchain.ErrSequenceLocked,
+		blockchain.ErrBadContract, blockchain.ErrNoContractOutput, blockchain.ErrSecretMismatch, blockchain.ErrTxNotFound,
+		blockchain.ErrDataTooLarge,
+	} {
+		if errors.Is(err, userErr) {
+			fmt.Printf("\033[31m%v\033[0m\n", err)
+			runtime.Goexit()
+		}
+	}
+
+	log.Panic(err)
+}
+
+// loadParams fonksiyonu, NETWORK ortam değişkeninde adı verilen hazır ağ profilini (main, test, regtest)
+// ya da CHAIN_PARAMS ile verilen JSON dosyasındaki parametreleri döndürür. İkisi de yoksa ana ağ kullanılır.
+func loadParams() (blockchain.Params, error) {
+	networkName := os.Getenv("NETWORK")
+	paramsFile := os.Getenv("CHAIN_PARAMS")
+
+	if paramsFile == "" {
+		return blockchain.ParamsByName(networkName)
+	}
+	if networkName != "" {
+		return blockchain.Params{}, fmt.Errorf("NETWORK and CHAIN_PARAMS are both set; select the base network with \"Base\" in %s", paramsFile)
+	}
+	return blockchain.LoadParams(paramsFile)
+}
+
 // reindexUTXO fonksiyonu, UTXO setini yeniden oluşturur.
 func (cli *CommandLine) reindexUTXO(nodeID string) {
-	chain := blockchain.ContinueBlockChain(nodeID) // blockchain adında bir Blockchain nesnesi
-	defer chain.Database.Close()                   // blok zincirini kapat
-	UTXOSet := blockchain.UTXOSet{chain}           // UTXO setini oluştur
-	UTXOSet.Reindex()                              // UTXO setini yeniden oluştur
-
-	count := UTXOSet.CountTransactions()                            // UTXO setindeki işlemleri sayar
+	chain, err := blockchain.ContinueBlockChain(nodeID) // blockchain adında bir Blockchain nesnesi
+	handleError(err)
+	defer chain.Close()                              // blok zincirini kapat
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain} // UTXO setini oluştur
+	handleError(UTXOSet.Reindex())                   // UTXO setini yeniden oluştur
+
+	count, err := UTXOSet.CountTransactions() // UTXO setindeki işlemleri sayar
+	handleError(err)
 	fmt.Printf("Tamamlamak! UTXO kümesinde %d işlem var.\n", count) // UTXO setindeki işlemlerin sayısını ekrana yazdırır
 }
 
-func (cli *CommandLine) StartNode(nodeID, minerAddress string) {
+// reindexTransactions fonksiyonu, işlem ve harcama indekslerini yeniden oluşturur.
+func (cli *CommandLine) reindexTransactions(nodeID string) {
+	chain, err := blockchain.ContinueBlockChain(nodeID) // blockchain adında bir Blockchain nesnesi
+	handleError(err)
+	defer chain.Close() // blok zincirini kapat
+
+	count, err := chain.ReindexTransactions() // işlem indeksini yeniden oluştur
+	handleError(err)
+	fmt.Printf("Tamamlamak! İşlem indeksinde %d işlem var.\n", count) // indekslenen işlemlerin sayısını ekrana yazdırır
+}
+
+func (cli *CommandLine) StartNode(nodeID, minerAddress string, proposals []blockchain.Proposal) {
 	fmt.Printf("Başlangıç Düğümü\n %s\n", nodeID)
 
 	if len(minerAddress) > 0 {
@@ -52,7 +122,7 @@ func (cli *CommandLine) StartNode(nodeID, minerAddress string) {
 			log.Panic("Yanlış madenci adresi!")
 		}
 	}
-	network.StartServer(nodeID, minerAddress)
+	network.StartServer(nodeID, minerAddress, proposals)
 }
 
 // validateArgs fonksiyonu, komut satırı argümanlarını doğrular.
@@ -66,25 +136,33 @@ func (cli *CommandLine) validateArgs() {
 	}
 }
 
-// printChain fonksiyonu, blok zincirindeki tüm blokları yazdırır
-func (cli *CommandLine) printChain(nodeID string) {
-	chain := blockchain.ContinueBlockChain(nodeID) // blockchain adında bir Blockchain nesnesi
-	defer chain.Database.Close()                   // blok zincirini kapat
-	iter := chain.Iterator()                       // blok zinciri iteratorunu oluştur
+// printChain fonksiyonu, blok zincirindeki tüm blokları zincir ucundan geriye doğru yazdırır.
+// from ya da to verilmişse (negatif değilse) yalnızca o yükseklik aralığı artan sırayla yazdırılır.
+func (cli *CommandLine) printChain(nodeID string, from, to int) {
+	chain, err := blockchain.ContinueBlockChain(nodeID) // blockchain adında bir Blockchain nesnesi
+	handleError(err)
+	defer chain.Close() // blok zincirini kapat
 	fmt.Println()
 
+	if from >= 0 || to >= 0 {
+		iter := chain.ForwardIterator(from) // yükseklik indeksi üzerinden ileri yönlü iterator
+		for {
+			block, err := iter.Next()
+			handleError(err)
+			if block == nil || (to >= 0 && block.Height > to) {
+				break
+			}
+			printBlock(chain, block)
+		}
+		return
+	}
+
+	iter := chain.Iterator() // blok zinciri iteratorunu oluştur
+
 	for { // blok zinciri sonuna kadar döngü
-		block := iter.Next() // Sıradaki bloğu al
-		fmt.Println("\033[97m╔══════════════════════════════════════════ BLOCK ═════════════════════════════════════════╗")
-		fmt.Printf("║ \033[32m%-10s : %x\033[0m\n", "Hash", block.Hash)
-		fmt.Printf("║ \033[32m%-10s : %x\033[0m\n", "Prev. hash", block.PrevHash)
-		pow := blockchain.NewProof(block)
-		fmt.Printf("║ \033[32m%-10s : %v\033[0m\n", "PoW", strconv.FormatBool(pow.Validate()))
-		// Blok zincirinden o bloğun proof of work değerini yazdır
-		for _, tx := range block.Transactions {
-			fmt.Println("║", tx)
-		}
-		fmt.Println("\u001B[97m╚═════════════════════════════════════════════════════════════════════════════════════════╝")
+		block, err := iter.Next() // Sıradaki bloğu al
+		handleError(err)
+		printBlock(chain, block)
 
 		if len(block.PrevHash) == 0 {
 			break
@@ -93,16 +171,35 @@ func (cli *CommandLine) printChain(nodeID string) {
 	}
 }
 
+// printBlock fonksiyonu, tek bir bloğu ve işlemlerini yazdırır
+func printBlock(chain *blockchain.BlockChain, block *blockchain.Block) {
+	fmt.Println("\033[97m╔══════════════════════════════════════════ BLOCK ═════════════════════════════════════════╗")
+	fmt.Printf("║ \033[32m%-10s : %x\033[0m\n", "Hash", block.Hash)
+	fmt.Printf("║ \033[32m%-10s : %x\033[0m\n", "Prev. hash", block.PrevHash)
+	fmt.Printf("║ \033[32m%-10s : %d\033[0m\n", "Height", block.Height)
+	fmt.Printf("║ \033[32m%-10s : %d\033[0m\n", "Version", block.Version)
+	fmt.Printf("║ \033[32m%-10s : %x\033[0m\n", "Merkle", block.MerkleRoot)
+	fmt.Printf("║ \033[32m%-10s : %08x\033[0m\n", "Bits", block.Bits)
+	// Bloğun mührünü zincirin konsensüs kuralına göre doğrula ve sonucu yazdır
+	fmt.Printf("║ \033[32m%-10s : %v\033[0m\n", "Seal", strconv.FormatBool(chain.Engine.VerifySeal(chain, block) == nil))
+	for _, tx := range block.Transactions {
+		fmt.Println("║", tx)
+	}
+	fmt.Println("\u001B[97m╚═════════════════════════════════════════════════════════════════════════════════════════╝")
+}
+
 // createBlockChain fonksiyonu, belirtilen adresin blok zincirini oluşturur
 func (cli *CommandLine) createBlockChain(address, nodeID string) { // blockchain oluşturur
 	if !wallet.ValidateAddress(address) { // adresin dogrulugunu kontrol eder
 		log.Panic("\033[31mAddress is not Valid\033[0m")
 	}
-	chain := blockchain.InitBlockChain(address, nodeID) // adresin blok zincirini oluşturur
-	defer chain.Database.Close()                        // blok zincirini kapat
+	chain, err := blockchain.InitBlockChain(address, nodeID) // adresin blok zincirini oluşturur
+	handleError(err)
+	defer chain.Close() // blok zincirini kapat
+	fmt.Println("Genesis created")
 
-	UTXOSet := blockchain.UTXOSet{chain} // adresin UTXO setini oluşturur
-	UTXOSet.Reindex()                    // adresin UTXO setini yeniden oluşturur
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain} // adresin UTXO setini oluşturur
+	handleError(UTXOSet.Reindex())                   // adresin UTXO setini yeniden oluşturur
 
 	fmt.Println("\u001B[32mFinished!\u001B[0m") // sonlandırılır
 }
@@ -112,14 +209,16 @@ func (cli *CommandLine) getBalance(address, nodeID string) {
 	if !wallet.ValidateAddress(address) { // adresin dogrulugunu kontrol eder
 		log.Panic("\033[31mAddress is not Valid\033[0m")
 	}
-	chain := blockchain.ContinueBlockChain(nodeID) // adresin blok zincirini okur
-	UTXOSet := blockchain.UTXOSet{chain}           // adresin UTXO setini oluşturur
-	defer chain.Database.Close()                   // blok zincirini kapat
+	chain, err := blockchain.ContinueBlockChain(nodeID) // adresin blok zincirini okur
+	handleError(err)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain} // adresin UTXO setini oluşturur
+	defer chain.Close()                              // blok zincirini kapat
 
 	balance := 0
-	pubKeyHash := wallet.Base58Decode([]byte(address))   // adresin base58 kodunu okur
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]       // adresin ilk 4 karakterini kaldırır
-	UTXOs := UTXOSet.FindUnspentTransactions(pubKeyHash) // adresin bakiyesini bulur
+	script, err := blockchain.AddressScript(address) // adrese ödeme yapan kilitleme betiği (P2PKH ya da P2SH)
+	handleError(err)
+	UTXOs, err := UTXOSet.FindScriptUTXO(script) // adresin bakiyesini bulur
+	handleError(err)
 
 	for _, out := range UTXOs { // bakiye döngüsü
 		balance += out.Value // bakiyeyi arttırır
@@ -128,17 +227,48 @@ func (cli *CommandLine) getBalance(address, nodeID string) {
 	fmt.Printf("\033[32mBalance of %s: %d\u001B[0m\n", address, balance) // bakiye yazdırılır
 }
 
+// getSupply fonksiyonu, zincirde üretilmiş token miktarını ve ödül planını yazdırır.
+func (cli *CommandLine) getSupply(nodeID string) {
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	params := blockchain.ActiveParams
+
+	height, err := chain.GetBestHeight()
+	handleError(err)
+	issued, err := UTXOSet.TotalValue() // UTXO setindeki toplam değer
+	handleError(err)
+	fmt.Printf("Height:        %d\n", height)
+	fmt.Printf("Issued:        %d\n", issued)
+	fmt.Printf("Scheduled:     %d\n", params.IssuedSupply(height))   // ödül planına göre en fazla üretilebilecek miktar
+	fmt.Printf("Next subsidy:  %d\n", chain.Engine.Reward(height+1)) // sıradaki bloğun ödülü
+	if params.HalvingInterval > 0 {
+		fmt.Printf("Next halving:  %d\n", (height/params.HalvingInterval+1)*params.HalvingInterval)
+	}
+	if max := params.MaxSupply(); max >= 0 {
+		fmt.Printf("Max supply:    %d\n", max)
+	} else {
+		fmt.Println("Max supply:    sınırsız")
+	}
+}
+
 // send fonksiyonu, belirtilen miktarı belirtilen adresten diğer bir adrese gönderir.
-func (cli *CommandLine) send(from, to string, amount int, nodeID string, mineNow bool) {
+// lockTime sıfır değilse işlem o blok yüksekliğine ya da zamana ulaşılana kadar kazılamaz ve gönderilmez.
+func (cli *CommandLine) send(from, to string, amount, fee int, lockTime uint32, nodeID string, mineNow bool) {
 	if !wallet.ValidateAddress(to) {
 		log.Panic("Address is not Valid")
 	}
 	if !wallet.ValidateAddress(from) {
 		log.Panic("Address is not Valid")
 	}
-	chain := blockchain.ContinueBlockChain(nodeID)
-	UTXOSet := blockchain.UTXOSet{chain}
-	defer chain.Database.Close()
+	if wallet.IsScriptAddress(from) {
+		log.Panic("\033[31mMultisig addresses are spent with spendmultisig\033[0m")
+	}
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Close()
 
 	wallets, err := wallet.CreateWallets(nodeID)
 	if err != nil {
@@ -146,12 +276,20 @@ func (cli *CommandLine) send(from, to string, amount int, nodeID string, mineNow
 	}
 	wallet := wallets.GetWallet(from)
 
-	tx := blockchain.NewTransaction(&wallet, to, amount, &UTXOSet)
+	tx, err := blockchain.NewTransaction(&wallet, to, amount, fee, lockTime, &UTXOSet)
+	handleError(err)
+	handleError(chain.VerifyTransaction(tx)) // kilit zamanına ulaşılmamış işlem madencilerin havuzuna da alınmaz
 	if mineNow {
-		cbTx := blockchain.CoinbaseTx(from, "")
+		if signer, ok := chain.Engine.(blockchain.Signer); ok { // PoA zincirinde blok gonderen cüzdanla imzalanır
+			signer.Authorize(&wallet)
+		}
+		height, err := chain.GetBestHeight()
+		handleError(err)
+		cbTx, err := blockchain.CoinbaseTx(from, "", chain.Engine.Reward(height+1)+fee) // ücret de madenciye (gonderene) doner
+		handleError(err)
 		txs := []*blockchain.Transaction{cbTx, tx}
-		block := chain.MineBlock(txs)
-		UTXOSet.Update(block)
+		_, err = chain.MineBlock(txs)
+		handleError(err)
 	} else {
 		network.SendTx(network.KnownNodes[0], tx)
 		fmt.Println("send tx")
@@ -160,11 +298,16 @@ func (cli *CommandLine) send(from, to string, amount int, nodeID string, mineNow
 	fmt.Println("Success!")
 }
 
-// listAddresses fonksiyonu, cüzdan adreslerini listeler.
-func (cli *CommandLine) listAddresses(nodeID string) {
+// listAddresses fonksiyonu, cüzdan adreslerini listeler. pubKeys true ise çoklu imza adresi
+// oluşturmak için paylaşılacak public key'ler de yazdırılır.
+func (cli *CommandLine) listAddresses(nodeID string, pubKeys bool) {
 	wallets, _ := wallet.CreateWallets(nodeID) // cüzdan dosyasını okur
 	addresses := wallets.GetAllAddress()       // cüzdan adreslerini alır
 	for _, address := range addresses {
+		if pubKeys {
+			fmt.Printf("\033[36m	%s\u001B[0m %x\n", address, wallets.Wallets[address].PublicKey)
+			continue
+		}
 		fmt.Printf("\033[36m	%s\u001B[0m\n", address)
 	}
 }
@@ -180,6 +323,13 @@ func (cli *CommandLine) createWallet(nodeID string) {
 func (cli *CommandLine) Run() { // komut satırı işlemleri
 	cli.validateArgs() // komut satırı argümanlarını dogrular
 
+	params, err := loadParams() // ağ profili (NETWORK) ya da özel parametre dosyası (CHAIN_PARAMS)
+	if err != nil {
+		log.Panic(err)
+	}
+	blockchain.SetActiveParams(params)
+	network.KnownNodes = append([]string(nil), params.Seeds...)
+
 	nodeID := os.Getenv("NODE_ID") // Set-Item -Path Env:NODE_ID -Value "3000" | set NODE_ID=3000
 	/*
 			Set-Item -Path Env:NODE_ID -Value "3000"
@@ -192,17 +342,31 @@ func (cli *CommandLine) Run() { // komut satırı işlemleri
 
 	*/
 	if nodeID == "" {
-		fmt.Printf("NODE_ID env ayarlanmadı!")
-		runtime.Goexit()
+		nodeID = strconv.Itoa(params.DefaultPort) // verilmezse ağın varsayılan portu kullanılır
 	}
 
 	getBalanceCmd := flag.NewFlagSet("getbalance", flag.ExitOnError)             // getbalance komutunu tanımla
 	createBlockchainCmd := flag.NewFlagSet("createblockchain", flag.ExitOnError) // createblockchain komutunu tanımla
 	sendCmd := flag.NewFlagSet("send", flag.ExitOnError)                         // send komutunu tanımla
 	printChainCmd := flag.NewFlagSet("printchain", flag.ExitOnError)             // printchain komutunu tanımla
+	printChainFrom := printChainCmd.Int("from", -1, "Yazdırılacak ilk blok yüksekliği")
+	printChainTo := printChainCmd.Int("to", -1, "Yazdırılacak son blok yüksekliği")
 	createWalletCmd := flag.NewFlagSet("createwallet", flag.ExitOnError)
+	getSupplyCmd := flag.NewFlagSet("getsupply", flag.ExitOnError)
 	listAddressesCmd := flag.NewFlagSet("listaddresses", flag.ExitOnError)
+	createMultiSigCmd := flag.NewFlagSet("createmultisig", flag.ExitOnError)
+	spendMultiSigCmd := flag.NewFlagSet("spendmultisig", flag.ExitOnError)
+	signMultiSigCmd := flag.NewFlagSet("signmultisig", flag.ExitOnError)
+	sendMultiSigCmd := flag.NewFlagSet("sendmultisig", flag.ExitOnError)
+	initiateCmd := flag.NewFlagSet("initiate", flag.ExitOnError)
+	participateCmd := flag.NewFlagSet("participate", flag.ExitOnError)
+	redeemCmd := flag.NewFlagSet("redeem", flag.ExitOnError)
+	refundCmd := flag.NewFlagSet("refund", flag.ExitOnError)
+	extractSecretCmd := flag.NewFlagSet("extractsecret", flag.ExitOnError)
+	stampCmd := flag.NewFlagSet("stamp", flag.ExitOnError)
+	verifyStampCmd := flag.NewFlagSet("verifystamp", flag.ExitOnError)
 	reindexUTXOCmd := flag.NewFlagSet("reindexutxo", flag.ExitOnError)
+	reindexTxCmd := flag.NewFlagSet("reindextx", flag.ExitOnError)
 	startNodeCmd := flag.NewFlagSet("startnode", flag.ExitOnError)
 
 	getBalanceAddress := getBalanceCmd.String("address", "", "\033[36mBakiye almanın adresi\033[0m")
@@ -210,8 +374,53 @@ func (cli *CommandLine) Run() { // komut satırı işlemleri
 	sendFrom := sendCmd.String("from", "", "\033[36mKaynak cüzdan adresi\033[0m")
 	sendTo := sendCmd.String("to", "", "\033[36mHedef cüzdan adresi\033[0m")
 	sendAmount := sendCmd.Int("amount", 0, "\033[36mGönderilecek tutar\033[0m")
+	sendFee := sendCmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
 	sendMine := sendCmd.Bool("mine", false, "Aynı düğümde hemen madencilik yapın")
+	sendLockTime := sendCmd.Uint64("locktime", 0, "\033[36mİşlemin kazılabileceği en erken blok yüksekliği ya da unix zamanı\033[0m")
+	listAddressesPubKeys := listAddressesCmd.Bool("pubkeys", false, "Adreslerin public key'lerini de yazdırın")
+	createMultiSigRequired := createMultiSigCmd.Int("required", 0, "Harcamak için gereken imza sayısı (M)")
+	createMultiSigKeys := createMultiSigCmd.String("keys", "", "Virgülle ayrılmış hex public key'ler ya da yerel cüzdan adresleri (N)")
+	spendMultiSigRedeem := spendMultiSigCmd.String("redeem", "", "createmultisig'in yazdırdığı asıl betik (redeem script, hex)")
+	spendMultiSigTo := spendMultiSigCmd.String("to", "", "\033[36mHedef adres\033[0m")
+	spendMultiSigAmount := spendMultiSigCmd.Int("amount", 0, "\033[36mGönderilecek tutar\033[0m")
+	spendMultiSigFee := spendMultiSigCmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
+	spendMultiSigTx := spendMultiSigCmd.String("tx", "", "İmzasız işlemin yazılacağı dosya")
+	signMultiSigTx := signMultiSigCmd.String("tx", "", "İmzalanacak işlemin dosyası")
+	signMultiSigAddress := signMultiSigCmd.String("address", "", "İmzalayan yerel cüzdanın adresi")
+	sendMultiSigTx := sendMultiSigCmd.String("tx", "", "Gönderilecek işlemin dosyası")
+	sendMultiSigMiner := sendMultiSigCmd.String("miner", "", "İşlemi bu düğümde hemen kazın ve ödülü ADDRESS adresine gönderin")
+	swapCmds := []*flag.FlagSet{initiateCmd, participateCmd}
+	swapFrom, swapTo, swapAmount, swapFee, swapLockTime, swapTx, swapMine := make([]*string, 2), make([]*string, 2), make([]*int, 2), make([]*int, 2), make([]*uint64, 2), make([]*string, 2), make([]*bool, 2)
+	for i, cmd := range swapCmds { // initiate ve participate aynı bayrakları kullanır
+		swapFrom[i] = cmd.String("from", "", "\033[36mSözleşmeyi kilitleyen (ve geri alabilecek) yerel cüzdan\033[0m")
+		swapTo[i] = cmd.String("to", "", "\033[36mGizli değerle harcayabilecek alıcının adresi\033[0m")
+		swapAmount[i] = cmd.Int("amount", 0, "\033[36mKilitlenecek tutar\033[0m")
+		swapFee[i] = cmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
+		swapLockTime[i] = cmd.Uint64("locktime", 0, "\033[36mGeri almanın mümkün olduğu blok yüksekliği ya da unix zamanı\033[0m")
+		swapTx[i] = cmd.String("tx", "", "Sözleşme işleminin yazılacağı dosya")
+		swapMine[i] = cmd.Bool("mine", false, "Aynı düğümde hemen madencilik yapın")
+	}
+	participateSecretHash := participateCmd.String("secrethash", "", "Başlatan tarafın yazdırdığı gizli değer hash'i (hex)")
+	redeemContract := redeemCmd.String("contract", "", "Sözleşmenin asıl betiği (hex)")
+	redeemTx := redeemCmd.String("tx", "", "Sözleşme işleminin dosyası")
+	redeemSecret := redeemCmd.String("secret", "", "Gizli değer (hex)")
+	redeemFee := redeemCmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
+	redeemMine := redeemCmd.Bool("mine", false, "Aynı düğümde hemen madencilik yapın")
+	refundContract := refundCmd.String("contract", "", "Sözleşmenin asıl betiği (hex)")
+	refundTx := refundCmd.String("tx", "", "Sözleşme işleminin dosyası")
+	refundFee := refundCmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
+	refundMine := refundCmd.Bool("mine", false, "Aynı düğümde hemen madencilik yapın")
+	extractSecretContract := extractSecretCmd.String("contract", "", "Sözleşmenin asıl betiği (hex)")
+	extractSecretTx := extractSecretCmd.String("tx", "", "Sözleşme işleminin dosyası")
+	stampFile := stampCmd.String("file", "", "Hash'i zincire yazılacak dosya")
+	stampFrom := stampCmd.String("from", "", "\033[36mİşlem ücretini ödeyen yerel cüzdan\033[0m")
+	stampFee := stampCmd.Int("fee", 0, "\033[36mMadenciye bırakılan işlem ücreti\033[0m")
+	stampMine := stampCmd.Bool("mine", false, "Aynı düğümde hemen madencilik yapın")
+	verifyStampFile := verifyStampCmd.String("file", "", "Damgası doğrulanacak dosya")
 	startNodeMiner := startNodeCmd.String("miner", "", "Madencilik modunu etkinleştirin ve ödülü ADDRESS adresine gönderin")
+	startNodeWorkers := startNodeCmd.Int("workers", 0, "Madencilikte kullanılacak goroutine sayısı (varsayılan: işlemci sayısı)")
+	startNodeVoteAdd := startNodeCmd.String("voteadd", "", "PoA: ADDRESS adresinin yetkili listesine eklenmesi için oy verin")
+	startNodeVoteRemove := startNodeCmd.String("voteremove", "", "PoA: ADDRESS adresinin yetkili listesinden çıkarılması için oy verin")
 
 	// send komutundaki tutarı tanımla
 
@@ -241,6 +450,66 @@ func (cli *CommandLine) Run() { // komut satırı işlemleri
 		if err != nil {
 			log.Panic(err)
 		}
+	case "createmultisig":
+		err := createMultiSigCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "spendmultisig":
+		err := spendMultiSigCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "signmultisig":
+		err := signMultiSigCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "sendmultisig":
+		err := sendMultiSigCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "initiate":
+		err := initiateCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "participate":
+		err := participateCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "redeem":
+		err := redeemCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "refund":
+		err := refundCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "extractsecret":
+		err := extractSecretCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "stamp":
+		err := stampCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "verifystamp":
+		err := verifyStampCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "getsupply":
+		err := getSupplyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "send":
 		err := sendCmd.Parse(os.Args[2:]) // send komutunu çalıştır
 		if err != nil {
@@ -251,6 +520,11 @@ func (cli *CommandLine) Run() { // komut satırı işlemleri
 		if err != nil {
 			log.Panic(err)
 		}
+	case "reindextx":
+		err := reindexTxCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "startnode":
 		err := startNodeCmd.Parse(os.Args[2:])
 		if err != nil {
@@ -278,34 +552,121 @@ func (cli *CommandLine) Run() { // komut satırı işlemleri
 	}
 
 	if printChainCmd.Parsed() {
-		cli.printChain(nodeID)
+		cli.printChain(nodeID, *printChainFrom, *printChainTo)
 	}
 
 	if createWalletCmd.Parsed() {
 		cli.createWallet(nodeID)
 	}
+	if getSupplyCmd.Parsed() {
+		cli.getSupply(nodeID)
+	}
 	if listAddressesCmd.Parsed() {
-		cli.listAddresses(nodeID)
+		cli.listAddresses(nodeID, *listAddressesPubKeys)
+	}
+	if createMultiSigCmd.Parsed() {
+		if *createMultiSigRequired <= 0 || *createMultiSigKeys == "" {
+			createMultiSigCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.createMultiSig(*createMultiSigRequired, strings.Split(*createMultiSigKeys, ","), nodeID)
+	}
+	if spendMultiSigCmd.Parsed() {
+		if *spendMultiSigRedeem == "" || *spendMultiSigTo == "" || *spendMultiSigAmount <= 0 || *spendMultiSigFee < 0 || *spendMultiSigTx == "" {
+			spendMultiSigCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.spendMultiSig(*spendMultiSigRedeem, *spendMultiSigTo, *spendMultiSigAmount, *spendMultiSigFee, *spendMultiSigTx, nodeID)
+	}
+	if signMultiSigCmd.Parsed() {
+		if *signMultiSigTx == "" || *signMultiSigAddress == "" {
+			signMultiSigCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.signMultiSig(*signMultiSigTx, *signMultiSigAddress, nodeID)
+	}
+	if sendMultiSigCmd.Parsed() {
+		if *sendMultiSigTx == "" {
+			sendMultiSigCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.sendMultiSig(*sendMultiSigTx, *sendMultiSigMiner, nodeID)
+	}
+	for i, cmd := range swapCmds {
+		if !cmd.Parsed() {
+			continue
+		}
+		if *swapFrom[i] == "" || *swapTo[i] == "" || *swapAmount[i] <= 0 || *swapFee[i] < 0 || *swapLockTime[i] > math.MaxUint32 || *swapTx[i] == "" ||
+			(cmd == participateCmd && *participateSecretHash == "") {
+			cmd.Usage()
+			runtime.Goexit()
+		}
+		if cmd == initiateCmd {
+			cli.initiate(*swapFrom[i], *swapTo[i], *swapAmount[i], *swapFee[i], uint32(*swapLockTime[i]), *swapTx[i], nodeID, *swapMine[i])
+		} else {
+			cli.participate(*swapFrom[i], *swapTo[i], *swapAmount[i], *swapFee[i], *participateSecretHash, uint32(*swapLockTime[i]), *swapTx[i], nodeID, *swapMine[i])
+		}
+	}
+	if redeemCmd.Parsed() {
+		if *redeemContract == "" || *redeemTx == "" || *redeemSecret == "" || *redeemFee < 0 {
+			redeemCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.redeem(*redeemContract, *redeemTx, *redeemSecret, *redeemFee, nodeID, *redeemMine)
+	}
+	if refundCmd.Parsed() {
+		if *refundContract == "" || *refundTx == "" || *refundFee < 0 {
+			refundCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.refund(*refundContract, *refundTx, *refundFee, nodeID, *refundMine)
+	}
+	if extractSecretCmd.Parsed() {
+		if *extractSecretContract == "" || *extractSecretTx == "" {
+			extractSecretCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.extractSecret(*extractSecretContract, *extractSecretTx, nodeID)
+	}
+	if stampCmd.Parsed() {
+		if *stampFile == "" || *stampFrom == "" || *stampFee < 0 {
+			stampCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.stamp(*stampFile, *stampFrom, *stampFee, nodeID, *stampMine)
+	}
+	if verifyStampCmd.Parsed() {
+		if *verifyStampFile == "" {
+			verifyStampCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.verifyStamp(*verifyStampFile, nodeID)
 	}
 	if reindexUTXOCmd.Parsed() {
 		cli.reindexUTXO(nodeID)
 	}
+	if reindexTxCmd.Parsed() {
+		cli.reindexTransactions(nodeID)
+	}
 
 	if sendCmd.Parsed() {
-		if *sendFrom == "" || *sendTo == "" || *sendAmount <= 0 {
+		if *sendFrom == "" || *sendTo == "" || *sendAmount <= 0 || *sendFee < 0 || *sendLockTime > math.MaxUint32 {
 			sendCmd.Usage()
 			runtime.Goexit()
 		}
 
-		cli.send(*sendFrom, *sendTo, *sendAmount, nodeID, *sendMine)
+		cli.send(*sendFrom, *sendTo, *sendAmount, *sendFee, uint32(*sendLockTime), nodeID, *sendMine)
 	}
 
 	if startNodeCmd.Parsed() {
-		nodeID := os.Getenv("NODE_ID")
-		if nodeID == "" {
-			startNodeCmd.Usage()
-			runtime.Goexit()
+		var proposals []blockchain.Proposal
+		if *startNodeVoteAdd != "" {
+			proposals = append(proposals, blockchain.Proposal{Address: *startNodeVoteAdd, Add: true})
+		}
+		if *startNodeVoteRemove != "" {
+			proposals = append(proposals, blockchain.Proposal{Address: *startNodeVoteRemove, Add: false})
 		}
-		cli.StartNode(nodeID, *startNodeMiner)
+		network.MinerWorkers = *startNodeWorkers
+		cli.StartNode(nodeID, *startNodeMiner, proposals)
 	}
 }
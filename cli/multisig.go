@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/blockchain"
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/network"
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
+)
+
+// Çoklu imza komutları kısmen imzalanmış işlemi bir dosyada (işlemin kanonik kodlamasının hex hali) taşır;
+// dosya imzalayacak cüzdanların düğümleri arasında elden ele geçirilir.
+
+// createMultiSig fonksiyonu, keys anahtarlarından required tanesinin imzasını isteyen çoklu imza adresini
+// ve harcarken gereken asıl betiği (redeem script) yazdırır. Anahtarlar hex public key ya da bu düğümün
+// cüzdan dosyasındaki bir adres olabilir.
+func (cli *CommandLine) createMultiSig(required int, keys []string, nodeID string) {
+	wallets, _ := wallet.CreateWallets(nodeID) // cüzdan dosyasını okur
+
+	var pubKeys [][]byte
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if w, ok := wallets.Wallets[key]; ok { // yerel cüzdan adresi
+			pubKeys = append(pubKeys, w.PublicKey)
+			continue
+		}
+		pubKey, err := hex.DecodeString(key)
+		if err != nil || len(pubKey) == 0 {
+			log.Panicf("\033[31m%q is neither a public key nor a local wallet address\033[0m", key)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	redeemScript, err := blockchain.MultiSigScript(required, pubKeys)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("\u001B[32mMultisig address : %s\u001B[0m\n", blockchain.ScriptAddress(redeemScript))
+	fmt.Printf("Redeem script    : %x\n", []byte(redeemScript))
+	fmt.Printf("Script           : %s\n", redeemScript)
+}
+
+// spendMultiSig fonksiyonu, çoklu imza adresinden to adresine amount gönderen imzasız işlemi file dosyasına yazar.
+func (cli *CommandLine) spendMultiSig(redeemHex, to string, amount, fee int, file, nodeID string) {
+	redeemScript, err := hex.DecodeString(redeemHex)
+	if err != nil {
+		log.Panic("\033[31mRedeem script is not valid hex\033[0m")
+	}
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Close()
+
+	tx, err := blockchain.NewMultiSigTransaction(redeemScript, to, amount, fee, &UTXOSet)
+	handleError(err)
+	writeTxFile(file, tx)
+
+	fmt.Printf("Unsigned transaction %x written to %s\n", tx.ID, file)
+	printSignatureProgress(tx)
+}
+
+// signMultiSig fonksiyonu, file dosyasındaki işlemi bu düğümün address cüzdanıyla imzalar ve dosyayı günceller.
+func (cli *CommandLine) signMultiSig(file, address, nodeID string) {
+	wallets, err := wallet.CreateWallets(nodeID)
+	if err != nil {
+		log.Panic(err)
+	}
+	w, ok := wallets.Wallets[address]
+	if !ok {
+		log.Panicf("\033[31mWallet %s is not in this node's wallet file\033[0m", address)
+	}
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+
+	tx := readTxFile(file)
+	handleError(chain.SignTransaction(tx, w.PrivateKey))
+	tx.ID = tx.Hash() //ID imzalar dahil edilerek hesaplanır
+	writeTxFile(file, tx)
+
+	fmt.Printf("Signed with %s, transaction is now %x\n", address, tx.ID)
+	printSignatureProgress(tx)
+}
+
+// sendMultiSig fonksiyonu, file dosyasındaki işlemin imzaları tamamsa işlemi merkez düğüme gönderir.
+// miner verilmişse işlem aynı düğümde hemen kazılır ve blok ödülü miner adresine gider.
+func (cli *CommandLine) sendMultiSig(file, miner, nodeID string) {
+	if miner != "" && !wallet.ValidateAddress(miner) {
+		log.Panic("Address is not Valid")
+	}
+	tx := readTxFile(file)
+	if !printSignatureProgress(tx) {
+		fmt.Println("\033[31mNot enough signatures, sign the transaction with signmultisig first\033[0m")
+		runtime.Goexit()
+	}
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+
+	submitTx(chain, tx, miner, nodeID)
+	fmt.Println("Success!")
+}
+
+// submitTx fonksiyonu, işlemi doğruladıktan sonra merkez düğüme gönderir. miner verilmişse işlem
+// gönderilmek yerine bu düğümde hemen kazılır; blok ödülü ve işlemin ücreti miner adresine gider.
+func submitTx(chain *blockchain.BlockChain, tx *blockchain.Transaction, miner, nodeID string) {
+	handleError(chain.VerifyTransaction(tx)) // imzalar, girdiler ve kilitler göndermeden önce doğrulanır
+
+	if miner == "" {
+		network.SendTx(network.KnownNodes[0], tx)
+		fmt.Println("send tx")
+		return
+	}
+
+	if signer, ok := chain.Engine.(blockchain.Signer); ok { // PoA zincirinde blok madencinin cüzdanıyla imzalanır
+		wallets, err := wallet.CreateWallets(nodeID)
+		if err != nil {
+			log.Panic(err)
+		}
+		w, ok := wallets.Wallets[miner]
+		if !ok {
+			log.Panicf("\033[31mWallet %s is not in this node's wallet file\033[0m", miner)
+		}
+		signer.Authorize(w)
+	}
+	height, err := chain.GetBestHeight()
+	handleError(err)
+	fee, err := chain.TransactionFee(tx)
+	handleError(err)
+	cbTx, err := blockchain.CoinbaseTx(miner, "", chain.Engine.Reward(height+1)+fee) // ücret de madenciye gider
+	handleError(err)
+	_, err = chain.MineBlock([]*blockchain.Transaction{cbTx, tx})
+	handleError(err)
+}
+
+// printSignatureProgress fonksiyonu, çoklu imzalı girdilerin imza durumunu yazdırır ve hepsinin
+// gereken imza sayısına ulaşıp ulaşmadığını döndürür.
+func printSignatureProgress(tx *blockchain.Transaction) bool {
+	complete := true
+	for i := range tx.Inputs {
+		signed, required, ok := tx.MultiSigProgress(i)
+		if !ok {
+			continue
+		}
+		fmt.Printf("Input %d: %d of %d signatures\n", i, signed, required)
+		if signed < required {
+			complete = false
+		}
+	}
+	return complete
+}
+
+// writeTxFile fonksiyonu, işlemi hex olarak dosyaya yazar.
+func writeTxFile(file string, tx *blockchain.Transaction) {
+	if err := os.WriteFile(file, []byte(hex.EncodeToString(tx.Serialize())+"\n"), 0644); err != nil {
+		log.Panic(err)
+	}
+}
+
+// readTxFile fonksiyonu, writeTxFile ile yazılmış işlemi dosyadan okur.
+func readTxFile(file string) *blockchain.Transaction {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		log.Panic(err)
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		log.Panicf("\033[31m%s does not contain a hex encoded transaction\033[0m", file)
+	}
+	tx, err := blockchain.DeserializeTransaction(data)
+	handleError(err)
+	return &tx
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/blockchain"
+)
+
+// Zaman damgası komutları bir dosyanın SHA-256 hash'ini zincire yazar ve dosyanın hangi blokta
+// damgalandığını merkle kanıtıyla gösterir. Dosyanın kendisi zincire yazılmaz.
+
+// stamp fonksiyonu, file dosyasının hash'ini from cüzdanının ödediği bir veri çıktısıyla zincire yazar.
+func (cli *CommandLine) stamp(file, from string, fee int, nodeID string, mineNow bool) {
+	hash := fileHash(file)
+	w := localWallet(from, nodeID)
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Close()
+
+	tx, err := blockchain.NewDataTransaction(w, hash, fee, &UTXOSet)
+	handleError(err)
+
+	miner := ""
+	if mineNow {
+		miner = from
+	}
+	submitTx(chain, tx, miner, nodeID)
+
+	fmt.Printf("File hash        : %x\n", hash)
+	fmt.Printf("Stamp tx         : %x\n", tx.ID)
+	fmt.Println("Success!")
+}
+
+// verifyStamp fonksiyonu, file dosyasının hash'ini taşıyan işlemi ve bloğu bulur, işlemin bloğun merkle
+// köküne bağlandığını gösteren kanıtı yazdırır ve kanıtı doğrular.
+func (cli *CommandLine) verifyStamp(file, nodeID string) {
+	hash := fileHash(file)
+
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	handleError(err)
+	defer chain.Close()
+
+	block, tx, err := chain.FindDataTransaction(hash)
+	handleError(err)
+	proof, err := block.TransactionProof(tx.ID)
+	handleError(err)
+	height, err := chain.GetBestHeight()
+	handleError(err)
+
+	fmt.Printf("File hash        : %x\n", hash)
+	fmt.Printf("Stamp tx         : %x\n", tx.ID)
+	fmt.Printf("Block            : %x\n", block.Hash)
+	fmt.Printf("Height           : %d (%d confirmations)\n", block.Height, height-block.Height+1)
+	fmt.Printf("Block time       : %s\n", time.Unix(block.Timestamp, 0).Format(time.RFC3339))
+	fmt.Printf("Merkle root      : %x\n", block.MerkleRoot)
+	fmt.Printf("Leaf index       : %d of %d transactions\n", proof.Index, len(block.Transactions))
+	index := proof.Index
+	for i, sibling := range proof.Hashes {
+		side := "right"
+		if index%2 == 1 {
+			side = "left"
+		}
+		fmt.Printf("Proof %-2d (%-5s) : %x\n", i, side, sibling)
+		index /= 2
+	}
+
+	if !blockchain.VerifyMerkleProof(block.MerkleRoot, tx.Serialize(), proof) {
+		log.Panic("\033[31mMerkle proof does not match the block's merkle root\033[0m")
+	}
+	fmt.Println("\u001B[32mProof is valid, the file existed at this block\u001B[0m")
+}
+
+// fileHash fonksiyonu, file dosyasının SHA-256 hash'ini döndürür.
+func fileHash(file string) []byte {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		log.Panic(err)
+	}
+	return hasher.Sum(nil)
+}
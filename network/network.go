@@ -2,9 +2,12 @@ package network
 
 import (
 	"bytes"
+	"context"
 	"crypto/elliptic"
+	"encoding/binary"
 	"encoding/gob"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,9 +15,11 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"sync"
 	"syscall"
 
 	"github.com/SadikSunbul/GO-BlockChain-Simulation/blockchain"
+	"github.com/SadikSunbul/GO-BlockChain-Simulation/wallet"
 	"github.com/vrecan/death/v3"
 )
 
@@ -24,16 +29,35 @@ func init() {
 
 const (
 	protocol      = "tcp"
-	version       = 1
+	version       = 2
 	commandLength = 12
+
+	// magicLength, her mesajın başındaki ağ kimliğinin (Params.Magic) uzunluğudur.
+	// Başka bir ağın kimliğini taşıyan mesajlar işlenmeden reddedilir.
+	magicLength = 4
+
+	// maxInvBlocks, tek bir getblocks cevabında gönderilecek en fazla blok hash'i sayısıdır.
+	// Daha uzun zincirler sayfa sayfa istenir.
+	maxInvBlocks = 500
 )
 
 var (
 	nodeAddress     string
 	mineAddress     string
-	KnownNodes      = []string{"localhost:3000"}
+	KnownNodes      = append([]string(nil), blockchain.DefaultParams.Seeds...) // ağ seçildiğinde Params.Seeds ile değiştirilir
 	blocksInTransit = [][]byte{}
+	syncFrom        = -1       // son getblocks isteğinin başlangıç yüksekliği, senkronizasyon yoksa -1
+	syncHasMore     = false    // son alınan blok envanteri dolu bir sayfa ise true
+	syncLock        sync.Mutex // blocksInTransit, syncFrom ve syncHasMore'a aynı anda gelen bağlantılardan erişimi sıralar
 	memoryPool      = make(map[string]blockchain.Transaction)
+	poolLock        sync.Mutex // memoryPool'a aynı anda gelen bağlantılardan erişimi sıralar
+
+	// MinerWorkers, iş kanıtı madenciliğinde nonce arayan goroutine sayısıdır. 0 ise işlemci sayısı kullanılır.
+	MinerWorkers int
+
+	miningLock   sync.Mutex
+	mining       bool               // MineTx şu anda çalışıyorsa true
+	cancelMining context.CancelFunc // kazılan bloğu iptal eder, kazım yoksa nil
 )
 
 type Addr struct {
@@ -46,7 +70,8 @@ type Block struct {
 }
 
 type GetBlocks struct {
-	AddrFrom string
+	AddrFrom   string
+	FromHeight int // istenen ilk blok yüksekliği
 }
 
 type GetData struct {
@@ -100,7 +125,7 @@ func ExtractCmd(request []byte) []byte {
 
 func RequestBlocks() {
 	for _, node := range KnownNodes {
-		SendGetBlocks(node)
+		SendGetBlocks(node, 0)
 	}
 }
 
@@ -141,7 +166,9 @@ func SendData(addr string, data []byte) {
 
 	defer conn.Close()
 
-	_, err = io.Copy(conn, bytes.NewReader(data))
+	var magic [magicLength]byte
+	binary.BigEndian.PutUint32(magic[:], blockchain.ActiveParams.Magic) // mesaj ağın kimliği ile başlar
+	_, err = io.Copy(conn, io.MultiReader(bytes.NewReader(magic[:]), bytes.NewReader(data)))
 	if err != nil {
 		log.Panic(err)
 	}
@@ -155,8 +182,14 @@ func SendInv(address, kind string, items [][]byte) {
 	SendData(address, request)
 }
 
-func SendGetBlocks(address string) {
-	payload := GobEncode(GetBlocks{nodeAddress})
+// SendGetBlocks fonksiyonu, karşı düğümden from yüksekliğinden başlayan en fazla maxInvBlocks
+// blok hash'i ister.
+func SendGetBlocks(address string, from int) {
+	syncLock.Lock()
+	syncFrom = from
+	syncLock.Unlock()
+
+	payload := GobEncode(GetBlocks{nodeAddress, from})
 	request := append(CmdToBytes("getblocks"), payload...)
 
 	SendData(address, request)
@@ -178,7 +211,11 @@ func SendTx(addr string, tnx *blockchain.Transaction) {
 }
 
 func SendVersion(addr string, chain *blockchain.BlockChain) {
-	bestHeight := chain.GetBestHeight()
+	bestHeight, err := chain.GetBestHeight()
+	if err != nil {
+		fmt.Printf("Zincir yüksekliği okunamadı: %s\n", err)
+		return
+	}
 	payload := GobEncode(Version{version, bestHeight, nodeAddress})
 
 	request := append(CmdToBytes("version"), payload...)
@@ -218,22 +255,93 @@ func HandleBlock(request []byte, chain *blockchain.BlockChain) {
 	fmt.Println("Handle Block hatası sonrasıs")
 
 	blockData := payload.Block
-	block := blockchain.Deserialize(blockData)
+	block, err := blockchain.Deserialize(blockData)
+	if err != nil {
+		fmt.Printf("Rejected block: %s\n", err)
+		return
+	}
 
 	fmt.Println("Recevied a new block!")
-	chain.AddBlock(block)
+	if err := chain.AddBlock(block); err != nil {
+		fmt.Printf("Rejected block %x: %s\n", block.Hash, err)
+
+		if errors.Is(err, blockchain.ErrUnknownParent) {
+			// Ebeveyni bilinmeyen blok: önce zincir ucumuzdan, o da yetmezse (dal ayrımı)
+			// genesisten itibaren bloklar istenir
+			syncLock.Lock()
+			blocksInTransit = [][]byte{}
+			from := syncFrom
+			if from == 0 {
+				syncFrom = -1
+			}
+			syncLock.Unlock()
+
+			switch {
+			case from < 0:
+				bestHeight, err := chain.GetBestHeight()
+				if err != nil {
+					fmt.Printf("Zincir yüksekliği okunamadı: %s\n", err)
+					return
+				}
+				SendGetBlocks(payload.AddrFrom, bestHeight+1)
+			case from > 0:
+				SendGetBlocks(payload.AddrFrom, 0)
+			}
+			return
+		}
+	} else {
+		fmt.Printf("Added block %x\n", block.Hash)
 
-	fmt.Printf("Added block %x\n", block.Hash)
+		if bytes.Equal(chain.LastHash(), block.Hash) {
+			// Yeni zincir ucu: bloktaki işlemler havuzdan çıkarılır ve kazılan blok yeni uç üzerinde yeniden başlatılır
+			poolLock.Lock()
+			for _, tx := range block.Transactions {
+				delete(memoryPool, hex.EncodeToString(tx.ID))
+			}
+			poolLock.Unlock()
 
-	if len(blocksInTransit) > 0 {
-		blockHash := blocksInTransit[0]
-		SendGetData(payload.AddrFrom, "block", blockHash)
+			abortMining()
+		}
+	}
 
-		blocksInTransit = blocksInTransit[1:]
+	if blockHash := nextBlockInTransit(); blockHash != nil {
+		SendGetData(payload.AddrFrom, "block", blockHash)
 	} else {
-		UTXOSet := blockchain.UTXOSet{chain}
-		UTXOSet.Reindex()
+		continueSync(payload.AddrFrom, chain)
+	}
+}
+
+// nextBlockInTransit fonksiyonu, istenecek sıradaki blok hash'ini blocksInTransit kuyruğundan çıkarır.
+// Kuyruk boşsa nil döner.
+func nextBlockInTransit() []byte {
+	syncLock.Lock()
+	defer syncLock.Unlock()
+
+	if len(blocksInTransit) == 0 {
+		return nil
+	}
+	blockHash := blocksInTransit[0]
+	blocksInTransit = blocksInTransit[1:]
+	return blockHash
+}
+
+// continueSync fonksiyonu, istenen bloklar bittiğinde son envanter dolu bir sayfa ise bir sonraki
+// sayfayı ister, değilse senkronizasyonu bitirir.
+func continueSync(address string, chain *blockchain.BlockChain) {
+	syncLock.Lock()
+	more := syncFrom >= 0 && syncHasMore
+	syncLock.Unlock()
+
+	if more {
+		if bestHeight, err := chain.GetBestHeight(); err == nil {
+			SendGetBlocks(address, bestHeight+1)
+			return
+		}
 	}
+
+	syncLock.Lock()
+	syncFrom = -1
+	syncLock.Unlock()
 }
 
 func HandleInv(request []byte, chain *blockchain.BlockChain) {
@@ -250,24 +358,36 @@ func HandleInv(request []byte, chain *blockchain.BlockChain) {
 	fmt.Printf("Recevied inventory with %d %s\n", len(payload.Items), payload.Type)
 
 	if payload.Type == "block" {
-		blocksInTransit = payload.Items
+		// Zaten sahip olunan bloklar tekrar istenmez
+		missing := [][]byte{}
+		for _, b := range payload.Items {
+			if _, err := chain.GetBlock(b); err != nil {
+				missing = append(missing, b)
+			}
+		}
 
-		blockHash := payload.Items[0]
-		SendGetData(payload.AddrFrom, "block", blockHash)
+		syncLock.Lock()
+		syncHasMore = len(payload.Items) >= maxInvBlocks
+		blocksInTransit = missing
+		syncLock.Unlock()
 
-		newInTransit := [][]byte{}
-		for _, b := range blocksInTransit {
-			if bytes.Compare(b, blockHash) != 0 {
-				newInTransit = append(newInTransit, b)
-			}
+		blockHash := nextBlockInTransit()
+		if blockHash == nil {
+			continueSync(payload.AddrFrom, chain)
+			return
 		}
-		blocksInTransit = newInTransit
+
+		SendGetData(payload.AddrFrom, "block", blockHash)
 	}
 
-	if payload.Type == "tx" {
+	if payload.Type == "tx" && len(payload.Items) > 0 {
 		txID := payload.Items[0]
 
-		if memoryPool[hex.EncodeToString(txID)].ID == nil {
+		poolLock.Lock()
+		known := memoryPool[hex.EncodeToString(txID)].ID != nil
+		poolLock.Unlock()
+
+		if !known {
 			SendGetData(payload.AddrFrom, "tx", txID)
 		}
 	}
@@ -284,7 +404,11 @@ func HandleGetBlocks(request []byte, chain *blockchain.BlockChain) {
 		log.Panic(err)
 	}
 
-	blocks := chain.GetBlockHashes()
+	blocks, err := chain.GetBlockHashes(payload.FromHeight, payload.FromHeight+maxInvBlocks-1)
+	if err != nil {
+		fmt.Printf("Blok hashleri okunamadı: %s\n", err)
+		return
+	}
 	SendInv(payload.AddrFrom, "block", blocks)
 }
 
@@ -310,7 +434,9 @@ func HandleGetData(request []byte, chain *blockchain.BlockChain) {
 
 	if payload.Type == "tx" {
 		txID := hex.EncodeToString(payload.ID)
+		poolLock.Lock()
 		tx := memoryPool[txID]
+		poolLock.Unlock()
 
 		SendTx(payload.AddrFrom, &tx)
 	}
@@ -328,10 +454,21 @@ func HandleTx(request []byte, chain *blockchain.BlockChain) {
 	}
 
 	txData := payload.Transaction
-	tx := blockchain.DeserializeTransaction(txData)
+	tx, err := blockchain.DeserializeTransaction(txData)
+	if err != nil {
+		fmt.Printf("Rejected tx: %s\n", err)
+		return
+	}
+	if err := chain.VerifyTransaction(&tx); err != nil { // geçersiz ya da kilit zamanına ulaşılmamış işlemler havuza alınmaz
+		fmt.Printf("Rejected tx: %s\n", err)
+		return
+	}
+	poolLock.Lock()
 	memoryPool[hex.EncodeToString(tx.ID)] = tx
+	poolSize := len(memoryPool)
+	poolLock.Unlock()
 
-	fmt.Printf("%s, %d", nodeAddress, len(memoryPool))
+	fmt.Printf("%s, %d", nodeAddress, poolSize)
 
 	if nodeAddress == KnownNodes[0] {
 		for _, node := range KnownNodes {
@@ -340,50 +477,125 @@ func HandleTx(request []byte, chain *blockchain.BlockChain) {
 			}
 		}
 	} else {
-		if len(memoryPool) >= 2 && len(mineAddress) > 0 {
+		if poolSize >= 2 && len(mineAddress) > 0 {
 			MineTx(chain)
 		}
 	}
 }
 
+// MineTx fonksiyonu, havuzdaki geçerli işlemleri yeni bir blokta kazar ve bloğu diğer düğümlere duyurur.
+// Kazım sırasında HandleBlock yeni bir zincir ucu bağlarsa kazım iptal edilir ve havuzda kalan
+// işlemlerle yeni uç üzerinde yeniden başlar. Havuz boşalana kadar blok kazmaya devam eder.
+// Aynı anda yalnızca bir MineTx çalışır; kazım sürerken gelen işlemler bir sonraki bloğa kalır.
 func MineTx(chain *blockchain.BlockChain) {
-	var txs []*blockchain.Transaction
+	miningLock.Lock()
+	if mining {
+		miningLock.Unlock()
+		return
+	}
+	mining = true
+	miningLock.Unlock()
+
+	defer func() {
+		miningLock.Lock()
+		mining = false
+		miningLock.Unlock()
+	}()
+
+	for {
+		if signer, ok := chain.Engine.(blockchain.Signer); ok && !signer.InTurn(chain) {
+			fmt.Println("Blok imzalama sırası bu düğümde değil")
+			return
+		}
 
-	for id := range memoryPool {
-		fmt.Printf("tx: %s\n", memoryPool[id].ID)
-		tx := memoryPool[id]
-		if chain.VerifyTransaction(&tx) {
-			txs = append(txs, &tx)
+		var pool []blockchain.Transaction
+
+		poolLock.Lock()
+		for id := range memoryPool {
+			pool = append(pool, memoryPool[id])
+		}
+		poolLock.Unlock()
+
+		txs, fees, invalid := chain.SelectTransactions(pool) // ücret oranı yüksek işlemler önce seçilir
+		if len(invalid) > 0 {
+			// Harcanmış çıktıya bağlı ya da geçersiz işlemler havuzdan atılır, aksi halde her kazım aynı hatayla biter
+			poolLock.Lock()
+			for _, tx := range invalid {
+				fmt.Printf("Dropped tx %x\n", tx.ID)
+				delete(memoryPool, hex.EncodeToString(tx.ID))
+			}
+			poolLock.Unlock()
+		}
+		if len(txs) == 0 {
+			fmt.Println("All Transactions are invalid")
+			return
+		}
+		for _, tx := range txs {
+			fmt.Printf("tx: %x\n", tx.ID)
 		}
-	}
 
-	if len(txs) == 0 {
-		fmt.Println("All Transactions are invalid")
-		return
-	}
+		bestHeight, err := chain.GetBestHeight()
+		if err != nil {
+			fmt.Printf("Blok kazılamadı: %s\n", err)
+			return
+		}
+		cbTx, err := blockchain.CoinbaseTx(mineAddress, "", chain.Engine.Reward(bestHeight+1)+fees)
+		if err != nil {
+			fmt.Printf("Blok kazılamadı: %s\n", err)
+			return
+		}
+		txs = append(txs, cbTx)
 
-	cbTx := blockchain.CoinbaseTx(mineAddress, "")
-	txs = append(txs, cbTx)
+		ctx, cancel := context.WithCancel(context.Background())
+		miningLock.Lock()
+		cancelMining = cancel
+		miningLock.Unlock()
 
-	newBlock := chain.MineBlock(txs)
-	UTXOSet := blockchain.UTXOSet{chain}
-	UTXOSet.Reindex()
+		newBlock, err := chain.MineBlockContext(ctx, txs)
 
-	fmt.Println("New Block mined")
+		miningLock.Lock()
+		cancelMining = nil
+		miningLock.Unlock()
+		cancel()
 
-	for _, tx := range txs {
-		txID := hex.EncodeToString(tx.ID)
-		delete(memoryPool, txID)
-	}
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("Yeni zincir ucu geldi, kazım yeniden başlatılıyor")
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Blok kazılamadı: %s\n", err)
+			return
+		}
 
-	for _, node := range KnownNodes {
-		if node != nodeAddress {
-			SendInv(node, "block", [][]byte{newBlock.Hash})
+		fmt.Println("New Block mined")
+
+		poolLock.Lock()
+		for _, tx := range txs {
+			txID := hex.EncodeToString(tx.ID)
+			delete(memoryPool, txID)
+		}
+		remaining := len(memoryPool)
+		poolLock.Unlock()
+
+		for _, node := range KnownNodes {
+			if node != nodeAddress {
+				SendInv(node, "block", [][]byte{newBlock.Hash})
+			}
+		}
+
+		if remaining == 0 {
+			return
 		}
 	}
+}
 
-	if len(memoryPool) > 0 {
-		MineTx(chain)
+// abortMining fonksiyonu, devam eden bir kazım varsa iptal eder.
+func abortMining() {
+	miningLock.Lock()
+	defer miningLock.Unlock()
+
+	if cancelMining != nil {
+		cancelMining()
 	}
 }
 
@@ -398,11 +610,15 @@ func HandleVersion(request []byte, chain *blockchain.BlockChain) {
 		log.Panic(err)
 	}
 
-	bestHeight := chain.GetBestHeight()
+	bestHeight, err := chain.GetBestHeight()
+	if err != nil {
+		fmt.Printf("Zincir yüksekliği okunamadı: %s\n", err)
+		return
+	}
 	otherHeight := payload.BestHeight
 
 	if bestHeight < otherHeight {
-		SendGetBlocks(payload.AddrFrom)
+		SendGetBlocks(payload.AddrFrom, bestHeight+1)
 	} else if bestHeight > otherHeight {
 		SendVersion(payload.AddrFrom, chain)
 	}
@@ -419,6 +635,15 @@ func HandleConnection(conn net.Conn, chain *blockchain.BlockChain) {
 	if err != nil {
 		log.Panic(err)
 	}
+	if len(req) < magicLength+commandLength {
+		fmt.Printf("Eksik mesaj reddedildi: %s\n", conn.RemoteAddr())
+		return
+	}
+	if magic := binary.BigEndian.Uint32(req[:magicLength]); magic != blockchain.ActiveParams.Magic {
+		fmt.Printf("Başka bir ağın mesajı reddedildi: %s (magic %08x)\n", conn.RemoteAddr(), magic)
+		return
+	}
+	req = req[magicLength:]
 	command := BytesToCmd(req[:commandLength])
 	fmt.Printf("Received %s command\n", command)
 
@@ -443,7 +668,7 @@ func HandleConnection(conn net.Conn, chain *blockchain.BlockChain) {
 
 }
 
-func StartServer(nodeID, minerAddress string) {
+func StartServer(nodeID, minerAddress string, proposals []blockchain.Proposal) {
 	nodeAddress = fmt.Sprintf("localhost:%s", nodeID)
 	mineAddress = minerAddress
 	ln, err := net.Listen(protocol, nodeAddress)
@@ -452,10 +677,41 @@ func StartServer(nodeID, minerAddress string) {
 	}
 	defer ln.Close()
 
-	chain := blockchain.ContinueBlockChain(nodeID)
-	defer chain.Database.Close()
+	chain, err := blockchain.ContinueBlockChain(nodeID)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer chain.Close()
 	go CloseDB(chain)
 
+	if pow, ok := chain.Engine.(*blockchain.ProofOfWorkEngine); ok {
+		if MinerWorkers > 0 {
+			pow.Workers = MinerWorkers
+		}
+		pow.OnHashRate = func(hashesPerSecond float64) {
+			fmt.Printf("Hash rate: %.0f H/s\n", hashesPerSecond)
+		}
+	}
+
+	if signer, ok := chain.Engine.(blockchain.Signer); ok && len(mineAddress) > 0 {
+		// PoA zincirinde bloklar madenci adresinin cüzdanıyla imzalanır
+		wallets, err := wallet.CreateWallets(nodeID)
+		if err != nil {
+			log.Panic(err)
+		}
+		if _, ok := wallets.Wallets[mineAddress]; !ok {
+			log.Panicf("%s adresinin cüzdanı bu düğümde bulunamadı", mineAddress)
+		}
+		w := wallets.GetWallet(mineAddress)
+		signer.Authorize(&w)
+
+		for _, p := range proposals {
+			if err := signer.Propose(p); err != nil {
+				log.Panic(err)
+			}
+		}
+	}
+
 	if nodeAddress != KnownNodes[0] {
 		SendVersion(KnownNodes[0], chain)
 	}
@@ -497,6 +753,6 @@ func CloseDB(chain *blockchain.BlockChain) {
 	d.WaitForDeathWithFunc(func() {
 		defer os.Exit(1)
 		defer runtime.Goexit()
-		chain.Database.Close()
+		chain.Close()
 	})
 }
@@ -9,30 +9,36 @@ import (
 	"log"
 	"math/big"
 
+	"github.com/mr-tron/base58"
 	"golang.org/x/crypto/ripemd160"
 )
 
-const (
-	checksumLength = 4
-	version        = byte(0x00) // 0 ın 16 lık gosterımıdır
-)
+const checksumLength = 4
+
+// AddressVersion, adreslerin ilk baytı olan sürüm kodudur; her ağ kendi değerini kullanır
+// (bkz. blockchain.SetActiveParams). Başka bir sürüm baytı taşıyan adresler geçersiz sayılır.
+var AddressVersion = byte(0x00) // 0 ın 16 lık gosterımıdır
+
+// ScriptAddressVersion, bir betiğin hash'ine ödeme yapan (P2SH) adreslerin sürüm baytıdır.
+// AddressVersion'dan farklı olmalıdır; adresin türü bu bayttan anlaşılır.
+var ScriptAddressVersion = byte(0x05)
 
 type Wallet struct {
 	PrivateKey ecdsa.PrivateKey //eliptik eğrisi ile private key
 	PublicKey  []byte
 }
 
-// Serialize, cüzdanı serileştirmek için özel bir yöntem
+// Serialize, cüzdanı serileştirmek için özel bir yöntem.
+// D, X ve Y değerleri 32 bayta tamamlanarak yazılır; DeserializeWallet sabit konumlardan okur.
 func (w *Wallet) Serialize() []byte {
-	var content bytes.Buffer
+	content := make([]byte, 96, 96+len(w.PublicKey))
 
 	// Private key'in D, X ve Y değerlerini kaydet
-	content.Write(w.PrivateKey.D.Bytes())
-	content.Write(w.PrivateKey.PublicKey.X.Bytes())
-	content.Write(w.PrivateKey.PublicKey.Y.Bytes())
-	content.Write(w.PublicKey)
+	w.PrivateKey.D.FillBytes(content[:32])
+	w.PrivateKey.PublicKey.X.FillBytes(content[32:64])
+	w.PrivateKey.PublicKey.Y.FillBytes(content[64:96])
 
-	return content.Bytes()
+	return append(content, w.PublicKey...)
 }
 
 // DeserializeWallet, serileştirilmiş veriyi Wallet yapısına dönüştürür
@@ -59,7 +65,9 @@ func NewKeyPair() (ecdsa.PrivateKey, []byte) {
 	if err != nil {
 		log.Panic(err)
 	}
-	pubKey := append(private.PublicKey.X.Bytes(), private.PublicKey.Y.Bytes()...) //public key olusturulur
+	pubKey := make([]byte, 64) //public key sabit uzunlukta (X||Y, 32+32 bayt) olusturulur
+	private.PublicKey.X.FillBytes(pubKey[:32])
+	private.PublicKey.Y.FillBytes(pubKey[32:])
 	return *private, pubKey
 }
 
@@ -91,13 +99,27 @@ func Checksum(payload []byte) []byte {
 
 // Address fonksiyonu, bir adres olusturur
 func (w Wallet) Address() []byte {
-	pubHash := PublicKeyHash(w.PublicKey)                // public key hash kodu olusturulur
-	versionedHash := append([]byte{version}, pubHash...) // version ve public key hash kodu birleştirilir
-	checksum := Checksum(versionedHash)                  // checksum kodu olusturulur
-	fullHash := append(versionedHash, checksum...)       // versionedHash ve checksum kodu birleştirilir
-	address := Base58Encode(fullHash)                    // adres olusturulur
+	pubHash := PublicKeyHash(w.PublicKey) // public key hash kodu olusturulur
+	return PubKeyHashAddress(pubHash)
+}
+
+// PubKeyHashAddress fonksiyonu, public key hash'i pubKeyHash olan cüzdanın adresini olusturur.
+func PubKeyHashAddress(pubKeyHash []byte) []byte {
+	return encodeAddress(AddressVersion, pubKeyHash)
+}
+
+// ScriptAddress fonksiyonu, hash'i scriptHash olan betiğe ödeme yapan (P2SH) adresi olusturur.
+// scriptHash, betiğin PublicKeyHash ile alınan hash'idir.
+func ScriptAddress(scriptHash []byte) []byte {
+	return encodeAddress(ScriptAddressVersion, scriptHash)
+}
 
-	return address
+// encodeAddress fonksiyonu, sürüm baytı, hash ve checksum'ı birleştirip Base58 ile kodlar.
+func encodeAddress(version byte, hash []byte) []byte {
+	versionedHash := append([]byte{version}, hash...) // version ve hash kodu birleştirilir
+	checksum := Checksum(versionedHash)               // checksum kodu olusturulur
+	fullHash := append(versionedHash, checksum...)    // versionedHash ve checksum kodu birleştirilir
+	return Base58Encode(fullHash)                     // adres olusturulur
 }
 
 /*
@@ -116,13 +138,36 @@ func (w Wallet) Address() []byte {
 
 */
 
-// ValidateAddress fonksiyonu, bir adresin gecerli olup olmadıgını kontrol eder
+// ValidateAddress fonksiyonu, bir adresin gecerli olup olmadıgını ve etkin aga (AddressVersion ya da
+// ScriptAddressVersion) aıt oldugunu kontrol eder
 func ValidateAddress(address string) bool {
-	pubKeyHash := Base58Decode([]byte(address))                        // adresi byte dizisine dönüştürülür
+	_, _, ok := DecodeAddress(address)
+	return ok
+}
+
+// IsScriptAddress fonksiyonu, adresin gecerli bir betik (P2SH) adresi olup olmadıgını kontrol eder
+func IsScriptAddress(address string) bool {
+	version, _, ok := DecodeAddress(address)
+	return ok && version == ScriptAddressVersion
+}
+
+// DecodeAddress fonksiyonu, adresin sürüm baytını ve hash'ini (public key ya da betik hash'i) döndürür.
+// Adres gecersızse ya da etkin aga aıt degılse ok false olur.
+func DecodeAddress(address string) (version byte, hash []byte, ok bool) {
+	pubKeyHash, err := base58.Decode(address) // adresi byte dizisine dönüştürülür
+	if err != nil || len(pubKeyHash) <= 1+checksumLength {
+		return 0, nil, false // base58 olmayan ya da surum ve checksum'dan kısa adresler gecersızdır
+	}
 	actualChecksum := pubKeyHash[len(pubKeyHash)-checksumLength:]      // checksum kodu alınır pubKeyHash[5:] 5. indeks den sona kadar oalnı alır
-	version := pubKeyHash[0]                                           // version kodu alınır
+	version = pubKeyHash[0]                                            // version kodu alınır
 	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-checksumLength]        // version ve checksum kodu silinir
 	targetChecksum := Checksum(append([]byte{version}, pubKeyHash...)) // checksum kodu olusturulur
 
-	return bytes.Compare(actualChecksum, targetChecksum) == 0 // checksum kodu karsılastırılır
+	if version != AddressVersion && version != ScriptAddressVersion {
+		return 0, nil, false // baska bir aga aıt adresler gecersızdır
+	}
+	if bytes.Compare(actualChecksum, targetChecksum) != 0 { // checksum kodu karsılastırılır
+		return 0, nil, false
+	}
+	return version, pubKeyHash, true
 }
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 )
 
-const walletFile = "./tmp/wallets_%s.data"
+const walletFile = "wallets_%s.data"
+
+// DataDir, cüzdan dosyalarının tutulduğu dizindir; her ağ kendi dizinini kullanır (bkz. blockchain.SetActiveParams).
+var DataDir = "./tmp"
 
 type Wallets struct {
 	Wallets map[string]*Wallet
@@ -53,7 +57,7 @@ func (ws *Wallets) GetAllAddress() []string {
 
 // LoadFile fonksiyonu, cüzdanları dosyadan yükler
 func (ws *Wallets) LoadFile(nodeId string) error {
-	walletFile := fmt.Sprintf(walletFile, nodeId)
+	walletFile := filepath.Join(DataDir, fmt.Sprintf(walletFile, nodeId))
 	if _, err := os.Stat(walletFile); os.IsNotExist(err) {
 		return err
 	}
@@ -83,7 +87,7 @@ func (ws *Wallets) LoadFile(nodeId string) error {
 // SaveFile fonksiyonu, cüzdanları dosyaya kaydeder
 func (ws *Wallets) SaveFile(nodeId string) {
 	var content bytes.Buffer
-	walletFile := fmt.Sprintf(walletFile, nodeId)
+	walletFile := filepath.Join(DataDir, fmt.Sprintf(walletFile, nodeId))
 
 	var walletsData = make(map[string][]byte)
 	for addr, wallet := range ws.Wallets {
@@ -96,6 +100,11 @@ func (ws *Wallets) SaveFile(nodeId string) {
 		log.Panic(err)
 	}
 
+	err = os.MkdirAll(DataDir, 0755) // ağın dizini henüz yoksa oluşturulur
+	if err != nil {
+		log.Panic(err)
+	}
+
 	err = os.WriteFile(walletFile, content.Bytes(), 0644)
 	if err != nil {
 		log.Panic(err)